@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -15,8 +16,9 @@ import (
  * Steps to do here:
  * 1. Create an instance of the app that contains the http.Handler item that is needed by
  *    the Http.ListenAndServe method.
- * 2. Log the launch notification using log.Println
- * 3. Execute http.ListenAndServe to launch the server.
+ * 2. Start the background workers of the app, like the outbox relay.
+ * 3. Log the launch notification using log.Println
+ * 4. Execute http.ListenAndServe to launch the server.
  *
  * Leave the main package simple. The objectives of main are to create the app Handler and
  * launch the server. Leave the endpoint routing inside threedee.go.
@@ -29,6 +31,8 @@ func main() {
 	}
 
 	app := threedee.NewThreedee()
+	go app.Relay.Run(context.Background())
+
 	log.Println("Threedee service is ready to listen at port 3000")
 	err := http.ListenAndServe(":3000", app.Router)
 	if err != nil {
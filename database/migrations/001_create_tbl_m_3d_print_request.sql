@@ -0,0 +1,30 @@
+CREATE TABLE tbl_m_3d_print_request (
+   id bigserial primary key not null,
+   item_name varchar(100) not null,
+   est_weight float8 not null,
+   est_filament_length float8 not null,
+   est_duration int not null,
+   file_url text not null,
+   requestor varchar(100) not null,
+   status varchar(20) not null default 'received',
+   created_on timestamptz not null default now(),
+   created_by varchar(100) not null default 'system',
+   modified_on timestamptz null,
+   modified_by varchar(100) null,
+   is_active bool not null default true
+);
+
+CREATE OR REPLACE FUNCTION before_update_3dpr() RETURNS trigger AS $before_update_3dpr$
+    BEGIN
+        -- Add modify fields
+        NEW.modified_on := now();
+
+	IF NEW.modified_by = NULL THEN
+            NEW.modified_by := 'system';
+	END IF;
+        RETURN NEW;
+    END;
+$before_update_3dpr$ LANGUAGE plpgsql;
+
+CREATE TRIGGER before_update_3dpr BEFORE UPDATE ON tbl_m_3d_print_request
+    FOR EACH ROW EXECUTE PROCEDURE before_update_3dpr();
@@ -0,0 +1,4 @@
+-- file_key is the storage key of an uploaded print file, file_hash the SHA-256 of its content
+ALTER TABLE tbl_m_3d_print_request
+   ADD COLUMN file_key varchar(80) not null default '',
+   ADD COLUMN file_hash varchar(64) not null default '';
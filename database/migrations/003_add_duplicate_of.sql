@@ -0,0 +1,6 @@
+-- duplicate_of points to the earlier active request with the same file, 0 when unique
+ALTER TABLE tbl_m_3d_print_request
+   ADD COLUMN duplicate_of bigint not null default 0;
+
+CREATE INDEX idx_3dpr_file_hash_requestor ON tbl_m_3d_print_request (file_hash, requestor)
+   WHERE is_active = true;
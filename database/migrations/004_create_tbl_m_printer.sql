@@ -0,0 +1,11 @@
+-- build_* columns are the build volume in mm
+CREATE TABLE tbl_m_printer (
+   id bigserial primary key not null,
+   name varchar(100) not null,
+   build_width float8 not null,
+   build_depth float8 not null,
+   build_height float8 not null,
+   created_on timestamptz not null default now(),
+   created_by varchar(100) not null default 'system',
+   is_active bool not null default true
+);
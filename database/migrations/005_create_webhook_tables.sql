@@ -0,0 +1,24 @@
+CREATE TABLE tbl_m_webhook_subscription (
+   id bigserial primary key not null,
+   url text not null,
+   secret varchar(200) not null,
+   events text[] not null,
+   created_on timestamptz not null default now(),
+   created_by varchar(100) not null default 'system',
+   is_active bool not null default true
+);
+
+-- one row per delivery attempt
+CREATE TABLE tbl_t_webhook_delivery (
+   id bigserial primary key not null,
+   subscription_id bigint not null references tbl_m_webhook_subscription(id),
+   event varchar(50) not null,
+   payload text not null,
+   attempt int not null,
+   status_code int not null default 0,
+   error text not null default '',
+   success bool not null,
+   created_on timestamptz not null default now()
+);
+
+CREATE INDEX idx_webhook_delivery_subscription ON tbl_t_webhook_delivery (subscription_id, id);
@@ -0,0 +1,14 @@
+-- events are written in the same transaction as the print request change and published
+-- by the outbox relay, delivered_on stays null until every sink accepted the event
+CREATE TABLE tbl_t_outbox (
+   id bigserial primary key not null,
+   event varchar(50) not null,
+   aggregate_id bigint not null,
+   payload text not null,
+   attempts int not null default 0,
+   last_error text not null default '',
+   created_on timestamptz not null default now(),
+   delivered_on timestamptz null
+);
+
+CREATE INDEX idx_outbox_pending ON tbl_t_outbox (id) WHERE delivered_on IS NULL;
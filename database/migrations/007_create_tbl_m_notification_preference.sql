@@ -0,0 +1,7 @@
+CREATE TABLE tbl_m_notification_preference (
+   requestor varchar(100) primary key not null,
+   email varchar(254) not null default '',
+   opt_out bool not null default false,
+   created_on timestamptz not null default now(),
+   modified_on timestamptz not null default now()
+);
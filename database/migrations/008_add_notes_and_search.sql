@@ -0,0 +1,13 @@
+-- notes is free text from the requestor, e.g. color or infill wishes
+ALTER TABLE tbl_m_3d_print_request
+   ADD COLUMN notes text not null default '';
+
+-- the 'simple' configuration does not stem, item names and requestors are not English prose
+ALTER TABLE tbl_m_3d_print_request
+   ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+      setweight(to_tsvector('simple', item_name), 'A') ||
+      setweight(to_tsvector('simple', requestor), 'B') ||
+      setweight(to_tsvector('simple', notes), 'C')
+   ) STORED;
+
+CREATE INDEX idx_3dpr_search ON tbl_m_3d_print_request USING GIN (search_vector);
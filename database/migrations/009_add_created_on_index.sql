@@ -0,0 +1,2 @@
+-- usage reports read the active requests of a date range
+CREATE INDEX idx_3dpr_created_on ON tbl_m_3d_print_request (created_on) WHERE is_active = true;
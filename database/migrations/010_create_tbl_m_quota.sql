@@ -0,0 +1,11 @@
+-- quotas of single requestors, the others get the QUOTA_* defaults
+CREATE TABLE tbl_m_quota (
+   requestor varchar(100) primary key not null,
+   max_active int not null default 0,
+   max_grams_per_month float8 not null default 0,
+   max_hours_per_week float8 not null default 0,
+   created_on timestamptz not null default now(),
+   modified_on timestamptz not null default now()
+);
+
+CREATE INDEX idx_3dpr_requestor ON tbl_m_3d_print_request (requestor, created_on) WHERE is_active = true;
@@ -0,0 +1,18 @@
+-- new requests wait for a review, existing ones keep their status
+ALTER TABLE tbl_m_3d_print_request
+   ADD COLUMN reviewer varchar(100) not null default '',
+   ALTER COLUMN status SET DEFAULT 'pending_review';
+
+-- the review thread of a request, the decisions of the reviewer and the resubmissions of
+-- the requestor with their comments
+CREATE TABLE tbl_t_review (
+   id bigserial primary key not null,
+   print_request_id bigint not null references tbl_m_3d_print_request (id),
+   author varchar(100) not null,
+   action varchar(20) not null,
+   comment text not null default '',
+   created_on timestamptz not null default now()
+);
+
+CREATE INDEX idx_review_print_request ON tbl_t_review (print_request_id, id);
+CREATE INDEX idx_3dpr_pending_review ON tbl_m_3d_print_request (reviewer) WHERE status = 'pending_review' AND is_active = true;
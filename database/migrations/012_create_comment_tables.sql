@@ -0,0 +1,21 @@
+-- comments on print requests and the images attached to them, the files themselves are in
+-- the file storage
+CREATE TABLE tbl_t_comment (
+   id bigserial primary key not null,
+   print_request_id bigint not null references tbl_m_3d_print_request (id),
+   author varchar(100) not null,
+   body text not null default '',
+   created_on timestamptz not null default now()
+);
+
+CREATE TABLE tbl_t_comment_attachment (
+   id bigserial primary key not null,
+   comment_id bigint not null references tbl_t_comment (id),
+   file_key varchar(100) not null,
+   filename varchar(255) not null,
+   content_type varchar(100) not null,
+   size bigint not null
+);
+
+CREATE INDEX idx_comment_print_request ON tbl_t_comment (print_request_id, id);
+CREATE INDEX idx_comment_attachment_comment ON tbl_t_comment_attachment (comment_id, id);
@@ -0,0 +1,12 @@
+-- print settings, existing requests get the defaults of entity.NewPrintRequest
+ALTER TABLE tbl_m_3d_print_request
+   ADD COLUMN material varchar(20) not null default 'pla',
+   ADD COLUMN color varchar(30) not null default '',
+   ADD COLUMN layer_height float8 not null default 0.2,
+   ADD COLUMN infill int not null default 20,
+   ADD COLUMN supports bool not null default false,
+   ADD COLUMN nozzle_size float8 not null default 0.4,
+   ADD COLUMN quantity int not null default 1,
+   ADD COLUMN est_cost float8 not null default 0;
+
+CREATE INDEX idx_3dpr_material ON tbl_m_3d_print_request (material) WHERE is_active = true;
@@ -0,0 +1,18 @@
+-- projects group the print requests of the parts of one thing, e.g. a drone frame. Requests
+-- that are not part of a project have project_id 0, like duplicate_of.
+CREATE TABLE tbl_m_project (
+   id bigserial primary key not null,
+   name varchar(100) not null,
+   owner varchar(100) not null,
+   description text not null default '',
+   created_on timestamptz not null default now(),
+   created_by varchar(100) not null default 'system',
+   modified_on timestamptz null,
+   modified_by varchar(100) null,
+   is_active bool not null default true
+);
+
+ALTER TABLE tbl_m_3d_print_request
+   ADD COLUMN project_id bigint not null default 0;
+
+CREATE INDEX idx_3dpr_project ON tbl_m_3d_print_request (project_id) WHERE is_active = true;
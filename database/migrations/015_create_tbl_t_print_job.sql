@@ -0,0 +1,18 @@
+-- print jobs are the attempts at printing a request, with what they really took. The printer
+-- is not a foreign key, the registry can be empty.
+CREATE TABLE tbl_t_print_job (
+   id bigserial primary key not null,
+   print_request_id bigint not null references tbl_m_3d_print_request (id),
+   printer_id bigint not null,
+   started_on timestamptz not null,
+   ended_on timestamptz null,
+   duration bigint not null default 0,
+   filament_used float8 not null default 0,
+   outcome varchar(20) not null default 'printing',
+   failure_reason text not null default '',
+   created_on timestamptz not null default now(),
+   modified_on timestamptz null
+);
+
+CREATE INDEX idx_print_job_print_request ON tbl_t_print_job (print_request_id, id);
+CREATE INDEX idx_print_job_ended_on ON tbl_t_print_job (ended_on) WHERE outcome = 'succeeded';
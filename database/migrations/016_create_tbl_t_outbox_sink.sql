@@ -0,0 +1,11 @@
+-- every sink of the outbox relay keeps its own delivery state of an event, so a failing sink
+-- only retries itself. tbl_t_outbox.delivered_on is set once all sinks delivered the event.
+CREATE TABLE tbl_t_outbox_sink (
+   outbox_id bigint not null references tbl_t_outbox (id) on delete cascade,
+   sink varchar(50) not null,
+   attempts int not null default 0,
+   last_error text not null default '',
+   next_attempt_on timestamptz null,
+   delivered_on timestamptz null,
+   primary key (outbox_id, sink)
+);
@@ -0,0 +1,88 @@
+-- SQLite version of the Postgres migrations 001 to 007
+CREATE TABLE tbl_m_3d_print_request (
+   id integer primary key autoincrement not null,
+   item_name varchar(100) not null,
+   est_weight real not null,
+   est_filament_length real not null,
+   est_duration int not null,
+   file_url text not null,
+   file_key varchar(80) not null default '',
+   file_hash varchar(64) not null default '',
+   duplicate_of integer not null default 0,
+   requestor varchar(100) not null,
+   status varchar(20) not null default 'received',
+   created_on datetime not null default current_timestamp,
+   created_by varchar(100) not null default 'system',
+   modified_on datetime null,
+   modified_by varchar(100) null,
+   is_active bool not null default true
+);
+
+CREATE TRIGGER before_update_3dpr AFTER UPDATE ON tbl_m_3d_print_request
+   FOR EACH ROW WHEN NEW.modified_on IS OLD.modified_on
+   BEGIN
+      UPDATE tbl_m_3d_print_request SET
+         modified_on = current_timestamp,
+         modified_by = coalesce(NEW.modified_by, 'system')
+      WHERE id = NEW.id;
+   END;
+
+CREATE INDEX idx_3dpr_file_hash_requestor ON tbl_m_3d_print_request (file_hash, requestor)
+   WHERE is_active = true;
+
+CREATE TABLE tbl_m_printer (
+   id integer primary key autoincrement not null,
+   name varchar(100) not null,
+   build_width real not null,
+   build_depth real not null,
+   build_height real not null,
+   created_on datetime not null default current_timestamp,
+   created_by varchar(100) not null default 'system',
+   is_active bool not null default true
+);
+
+-- events is a JSON array of event names
+CREATE TABLE tbl_m_webhook_subscription (
+   id integer primary key autoincrement not null,
+   url text not null,
+   secret varchar(200) not null,
+   events text not null,
+   created_on datetime not null default current_timestamp,
+   created_by varchar(100) not null default 'system',
+   is_active bool not null default true
+);
+
+CREATE TABLE tbl_t_webhook_delivery (
+   id integer primary key autoincrement not null,
+   subscription_id integer not null references tbl_m_webhook_subscription(id),
+   event varchar(50) not null,
+   payload text not null,
+   attempt int not null,
+   status_code int not null default 0,
+   error text not null default '',
+   success bool not null,
+   created_on datetime not null default current_timestamp
+);
+
+CREATE INDEX idx_webhook_delivery_subscription ON tbl_t_webhook_delivery (subscription_id, id);
+
+CREATE TABLE tbl_t_outbox (
+   id integer primary key autoincrement not null,
+   event varchar(50) not null,
+   aggregate_id integer not null,
+   payload text not null,
+   attempts int not null default 0,
+   last_error text not null default '',
+   created_on datetime not null default current_timestamp,
+   delivered_on datetime null
+);
+
+CREATE INDEX idx_outbox_pending ON tbl_t_outbox (id) WHERE delivered_on IS NULL;
+
+CREATE TABLE tbl_m_notification_preference (
+   requestor varchar(100) primary key not null,
+   email varchar(254) not null default '',
+   opt_out bool not null default false,
+   created_on datetime not null default current_timestamp,
+   modified_on datetime not null default current_timestamp
+);
@@ -0,0 +1,22 @@
+-- SQLite version of the Postgres migration 008, an FTS5 index kept in sync by triggers
+ALTER TABLE tbl_m_3d_print_request ADD COLUMN notes text not null default '';
+
+CREATE VIRTUAL TABLE tbl_m_3d_print_request_fts USING fts5(
+   item_name, requestor, notes,
+   content = 'tbl_m_3d_print_request', content_rowid = 'id',
+   tokenize = 'unicode61'
+);
+
+CREATE TRIGGER after_insert_3dpr_fts AFTER INSERT ON tbl_m_3d_print_request BEGIN
+   INSERT INTO tbl_m_3d_print_request_fts (rowid, item_name, requestor, notes)
+      VALUES (NEW.id, NEW.item_name, NEW.requestor, NEW.notes);
+END;
+
+CREATE TRIGGER after_update_3dpr_fts AFTER UPDATE OF item_name, requestor, notes ON tbl_m_3d_print_request BEGIN
+   INSERT INTO tbl_m_3d_print_request_fts (tbl_m_3d_print_request_fts, rowid, item_name, requestor, notes)
+      VALUES ('delete', OLD.id, OLD.item_name, OLD.requestor, OLD.notes);
+   INSERT INTO tbl_m_3d_print_request_fts (rowid, item_name, requestor, notes)
+      VALUES (NEW.id, NEW.item_name, NEW.requestor, NEW.notes);
+END;
+
+INSERT INTO tbl_m_3d_print_request_fts (tbl_m_3d_print_request_fts) VALUES ('rebuild');
@@ -0,0 +1,14 @@
+-- SQLite version of the Postgres migration 011. The default status can not be changed,
+-- Insert sets it instead.
+ALTER TABLE tbl_m_3d_print_request ADD COLUMN reviewer varchar(100) not null default '';
+
+CREATE TABLE tbl_t_review (
+   id integer primary key autoincrement not null,
+   print_request_id integer not null references tbl_m_3d_print_request (id),
+   author varchar(100) not null,
+   action varchar(20) not null,
+   comment text not null default '',
+   created_on datetime not null default current_timestamp
+);
+
+CREATE INDEX idx_review_print_request ON tbl_t_review (print_request_id, id);
@@ -0,0 +1,20 @@
+-- SQLite version of the Postgres migration 012
+CREATE TABLE tbl_t_comment (
+   id integer primary key autoincrement not null,
+   print_request_id integer not null references tbl_m_3d_print_request (id),
+   author varchar(100) not null,
+   body text not null default '',
+   created_on datetime not null default current_timestamp
+);
+
+CREATE TABLE tbl_t_comment_attachment (
+   id integer primary key autoincrement not null,
+   comment_id integer not null references tbl_t_comment (id),
+   file_key varchar(100) not null,
+   filename varchar(255) not null,
+   content_type varchar(100) not null,
+   size integer not null
+);
+
+CREATE INDEX idx_comment_print_request ON tbl_t_comment (print_request_id, id);
+CREATE INDEX idx_comment_attachment_comment ON tbl_t_comment_attachment (comment_id, id);
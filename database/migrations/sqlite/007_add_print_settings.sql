@@ -0,0 +1,11 @@
+-- SQLite version of the Postgres migration 013
+ALTER TABLE tbl_m_3d_print_request ADD COLUMN material varchar(20) not null default 'pla';
+ALTER TABLE tbl_m_3d_print_request ADD COLUMN color varchar(30) not null default '';
+ALTER TABLE tbl_m_3d_print_request ADD COLUMN layer_height float8 not null default 0.2;
+ALTER TABLE tbl_m_3d_print_request ADD COLUMN infill int not null default 20;
+ALTER TABLE tbl_m_3d_print_request ADD COLUMN supports bool not null default false;
+ALTER TABLE tbl_m_3d_print_request ADD COLUMN nozzle_size float8 not null default 0.4;
+ALTER TABLE tbl_m_3d_print_request ADD COLUMN quantity int not null default 1;
+ALTER TABLE tbl_m_3d_print_request ADD COLUMN est_cost float8 not null default 0;
+
+CREATE INDEX idx_3dpr_material ON tbl_m_3d_print_request (material);
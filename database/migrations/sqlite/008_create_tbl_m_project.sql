@@ -0,0 +1,16 @@
+-- SQLite version of the Postgres migration 014
+CREATE TABLE tbl_m_project (
+   id integer primary key autoincrement not null,
+   name varchar(100) not null,
+   owner varchar(100) not null,
+   description text not null default '',
+   created_on datetime not null default current_timestamp,
+   created_by varchar(100) not null default 'system',
+   modified_on datetime null,
+   modified_by varchar(100) null,
+   is_active bool not null default true
+);
+
+ALTER TABLE tbl_m_3d_print_request ADD COLUMN project_id integer not null default 0;
+
+CREATE INDEX idx_3dpr_project ON tbl_m_3d_print_request (project_id);
@@ -0,0 +1,17 @@
+-- SQLite version of the Postgres migration 015
+CREATE TABLE tbl_t_print_job (
+   id integer primary key autoincrement not null,
+   print_request_id integer not null references tbl_m_3d_print_request (id),
+   printer_id integer not null,
+   started_on datetime not null,
+   ended_on datetime null,
+   duration integer not null default 0,
+   filament_used real not null default 0,
+   outcome varchar(20) not null default 'printing',
+   failure_reason text not null default '',
+   created_on datetime not null default current_timestamp,
+   modified_on datetime null
+);
+
+CREATE INDEX idx_print_job_print_request ON tbl_t_print_job (print_request_id, id);
+CREATE INDEX idx_print_job_ended_on ON tbl_t_print_job (ended_on);
@@ -0,0 +1,10 @@
+-- SQLite version of the Postgres migration 016
+CREATE TABLE tbl_t_outbox_sink (
+   outbox_id integer not null references tbl_t_outbox (id) on delete cascade,
+   sink varchar(50) not null,
+   attempts int not null default 0,
+   last_error text not null default '',
+   next_attempt_on datetime null,
+   delivered_on datetime null,
+   primary key (outbox_id, sink)
+);
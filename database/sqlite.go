@@ -0,0 +1,84 @@
+package database
+
+import (
+	"database/sql"
+	"embed"
+	"path"
+	"sort"
+
+	_ "modernc.org/sqlite" // pure Go, no cgo needed
+)
+
+//go:embed migrations/sqlite/*.sql
+var sqliteMigrations embed.FS
+
+// NewSqlite opens the SQLite database at path and brings its schema up to date. Unlike
+// Postgres the connection is opened once and shared, SQLite allows a single writer anyway.
+func NewSqlite(dbPath string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec("PRAGMA foreign_keys = ON; PRAGMA busy_timeout = 5000;")
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	err = migrateSqlite(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// migrateSqlite runs the files in migrations/sqlite that were not run yet, in name order
+func migrateSqlite(db *sql.DB) error {
+	_, err := db.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (version varchar(200) primary key not null)")
+	if err != nil {
+		return err
+	}
+
+	files, err := sqliteMigrations.ReadDir("migrations/sqlite")
+	if err != nil {
+		return err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name() < files[j].Name() })
+
+	for _, file := range files {
+		var applied int
+		err := db.QueryRow("SELECT count(*) FROM schema_migrations WHERE version = $1", file.Name()).Scan(&applied)
+		if err != nil {
+			return err
+		}
+		if applied > 0 {
+			continue
+		}
+
+		script, err := sqliteMigrations.ReadFile(path.Join("migrations/sqlite", file.Name()))
+		if err != nil {
+			return err
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(string(script))
+		if err == nil {
+			_, err = tx.Exec("INSERT INTO schema_migrations (version) VALUES ($1)", file.Name())
+		}
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		err = tx.Commit()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package email
+
+import (
+	"bytes"
+	"errors"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SmtpSender sends plain text emails through an SMTP relay. Without a username it sends
+// unauthenticated, which is what most relays inside the lab network expect.
+type SmtpSender struct {
+	Addr     string // host:port
+	From     string
+	Username string
+	Password string
+}
+
+func NewSmtpSender(addr string, from string, username string, password string) *SmtpSender {
+	return &SmtpSender{addr, from, username, password}
+}
+
+func (s *SmtpSender) Send(to string, subject string, body string) error {
+	if strings.ContainsAny(to, "\r\n") || strings.ContainsAny(subject, "\r\n") {
+		return errors.New("email headers must not contain line breaks")
+	}
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		host, _, err := net.SplitHostPort(s.Addr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+
+	return smtp.SendMail(s.Addr, auth, s.From, []string{to}, s.message(to, subject, body, time.Now()))
+}
+
+func (s *SmtpSender) message(to string, subject string, body string, now time.Time) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("From: " + s.From + "\r\n")
+	buf.WriteString("To: " + to + "\r\n")
+	buf.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	buf.WriteString("Date: " + now.Format(time.RFC1123Z) + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	return buf.Bytes()
+}
@@ -0,0 +1,142 @@
+package email_test
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"threedee/email"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeSmtpServer accepts one mail per connection and records the envelope and data
+type fakeSmtpServer struct {
+	listener net.Listener
+	auth     chan string
+	rcpt     chan string
+	data     chan string
+}
+
+func newFakeSmtpServer() (*fakeSmtpServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &fakeSmtpServer{listener, make(chan string, 10), make(chan string, 10), make(chan string, 10)}
+	go s.serve()
+	return s, nil
+}
+
+func (s *fakeSmtpServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSmtpServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 fake ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO":
+			reply("250-fake")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			s.auth <- line
+			reply("235 authenticated")
+		case "MAIL":
+			reply("250 ok")
+		case "RCPT":
+			s.rcpt <- line
+			reply("250 ok")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.data <- data.String()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+type SmtpSenderTestSuite struct {
+	suite.Suite
+	server *fakeSmtpServer
+}
+
+func (suite *SmtpSenderTestSuite) SetupTest() {
+	server, err := newFakeSmtpServer()
+	suite.Require().Nil(err)
+	suite.server = server
+}
+
+func (suite *SmtpSenderTestSuite) TearDownTest() {
+	suite.server.listener.Close()
+}
+
+func (suite *SmtpSenderTestSuite) TestSend() {
+	sender := email.NewSmtpSender(suite.server.listener.Addr().String(), "lab@example.com", "", "")
+
+	err := sender.Send("andi@example.com", "Your print is ready", "Hi andi,\n\ncome pick it up.\n")
+
+	suite.Nil(err)
+	suite.Equal("RCPT TO:<andi@example.com>", <-suite.server.rcpt)
+	data := <-suite.server.data
+	suite.Contains(data, "From: lab@example.com\r\n")
+	suite.Contains(data, "To: andi@example.com\r\n")
+	suite.Contains(data, "Subject: Your print is ready\r\n")
+	suite.Contains(data, "\r\n\r\nHi andi,\r\n\r\ncome pick it up.\r\n")
+	suite.Len(suite.server.auth, 0)
+}
+
+func (suite *SmtpSenderTestSuite) TestSendWithAuth() {
+	// net/smtp only sends PLAIN credentials without TLS to localhost
+	addr := strings.Replace(suite.server.listener.Addr().String(), "127.0.0.1", "localhost", 1)
+	sender := email.NewSmtpSender(addr, "lab@example.com", "lab", "s3cret")
+
+	err := sender.Send("andi@example.com", "Your print is ready", "done")
+
+	suite.Nil(err)
+	suite.Equal("AUTH PLAIN AGxhYgBzM2NyZXQ=", <-suite.server.auth)
+}
+
+func (suite *SmtpSenderTestSuite) TestSendHeaderInjection() {
+	sender := email.NewSmtpSender(suite.server.listener.Addr().String(), "lab@example.com", "", "")
+
+	err := sender.Send("andi@example.com\r\nBcc: everyone@example.com", "Your print is ready", "done")
+
+	suite.NotNil(err)
+	suite.Len(suite.server.rcpt, 0)
+}
+
+func TestSmtpSenderTestSuite(t *testing.T) {
+	suite.Run(t, new(SmtpSenderTestSuite))
+}
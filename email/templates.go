@@ -0,0 +1,77 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"path"
+	"strings"
+	"text/template"
+	"threedee/entity"
+	"time"
+)
+
+/*
+ * Templates renders the emails sent to requestors.
+ *
+ * There is one template per status in templates/, named after the status. A template starts
+ * with a "Subject:" line, followed by an empty line and the plain text body. It is executed
+ * with the entity.StatusChangedEvent of the change, so {{.ItemName}}, {{.Requestor}} and
+ * {{.PreviousStatus}} are available.
+ */
+
+//go:embed templates/*.tmpl
+var templateFiles embed.FS
+
+var funcs = template.FuncMap{
+	// duration formats seconds, e.g. 9000 as 2h30m0s
+	"duration": func(seconds int) string {
+		return (time.Duration(seconds) * time.Second).String()
+	},
+}
+
+type Templates struct {
+	templates map[string]*template.Template
+}
+
+func NewTemplates() (*Templates, error) {
+	files, err := templateFiles.ReadDir("templates")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Templates{templates: make(map[string]*template.Template)}
+	for _, file := range files {
+		tmpl, err := template.New(file.Name()).Funcs(funcs).ParseFS(templateFiles, path.Join("templates", file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		result.templates[strings.TrimSuffix(file.Name(), ".tmpl")] = tmpl
+	}
+	return result, nil
+}
+
+// Has reports whether requestors are emailed about a request reaching the status
+func (t *Templates) Has(status string) bool {
+	_, ok := t.templates[status]
+	return ok
+}
+
+func (t *Templates) Render(event *entity.StatusChangedEvent) (subject string, body string, err error) {
+	tmpl, ok := t.templates[event.Status]
+	if !ok {
+		return "", "", errors.New("no email template for status " + event.Status)
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, event)
+	if err != nil {
+		return "", "", err
+	}
+
+	parts := strings.SplitN(buf.String(), "\n\n", 2)
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "Subject: ") {
+		return "", "", errors.New("email template for " + event.Status + " must start with a Subject line")
+	}
+	return strings.TrimPrefix(parts[0], "Subject: "), parts[1], nil
+}
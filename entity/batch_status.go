@@ -0,0 +1,17 @@
+package entity
+
+// BatchStatusChange moves several print requests to the same status. When Atomic is
+// true, either all of them change or none does.
+type BatchStatusChange struct {
+	Ids    []int  `json:"ids"`
+	Status string `json:"status"`
+	Atomic *bool  `json:"atomic"` // true when not set
+}
+
+// BatchStatusResult tells what happened to one request of a BatchStatusChange
+type BatchStatusResult struct {
+	Id             int    `json:"id"`
+	PreviousStatus string `json:"previous_status,omitempty"`
+	Changed        bool   `json:"changed"`
+	Error          string `json:"error,omitempty"`
+}
@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+// Comment is a message on a print request, e.g. about color, infill or orientation
+type Comment struct {
+	Id             int           `json:"id"`
+	PrintRequestId int           `json:"print_request_id"`
+	Author         string        `json:"author"`
+	Body           string        `json:"body"`
+	Attachments    []*Attachment `json:"attachments"`
+	CreatedOn      time.Time     `json:"created_on"`
+}
+
+func NewComment() *Comment {
+	return &Comment{Attachments: make([]*Attachment, 0)}
+}
+
+// Attachment is an image of a comment, kept in the file storage under Key. Url is a signed
+// download URL, it is not stored.
+type Attachment struct {
+	Key         string `json:"key"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Url         string `json:"url"`
+}
+
+func NewAttachment() *Attachment {
+	return &Attachment{}
+}
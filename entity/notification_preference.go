@@ -0,0 +1,12 @@
+package entity
+
+// NotificationPreference decides where, and whether, a requestor is emailed about status changes
+type NotificationPreference struct {
+	Requestor string `json:"requestor"`
+	Email     string `json:"email"`
+	OptOut    bool   `json:"opt_out"`
+}
+
+func NewNotificationPreference() *NotificationPreference {
+	return &NotificationPreference{}
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxEvent is a print request lifecycle event waiting to be published. Payload holds
+// the JSON of the event data, e.g. the print request or a StatusChangedEvent.
+type OutboxEvent struct {
+	Id          int             `json:"id"`
+	Event       string          `json:"event"`
+	AggregateId int             `json:"aggregate_id"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"`
+	LastError   string          `json:"last_error"`
+	CreatedOn   time.Time       `json:"created_on"`
+}
+
+func NewOutboxEvent() *OutboxEvent {
+	return &OutboxEvent{}
+}
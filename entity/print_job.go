@@ -0,0 +1,70 @@
+package entity
+
+import "time"
+
+// Outcomes of a print job, a job is printing until it ends with one of the others
+const (
+	JobPrinting  = "printing"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// JobStatuses are the statuses of the print requests jobs can be recorded for. A failed
+// request can be printed again once it is approved, but its failed jobs are still recorded.
+var JobStatuses = []string{StatusApproved, StatusFinished, StatusFailed}
+
+// PrintJob is one attempt at printing a print request on a printer. Duration is in second,
+// from StartedOn to EndedOn, and FilamentUsed in gram like the estimates. EndedOn is nil
+// while printing.
+type PrintJob struct {
+	Id             int        `json:"id"`
+	PrintRequestId int        `json:"print_request_id"`
+	PrinterId      int        `json:"printer_id"`
+	StartedOn      time.Time  `json:"started_on"`
+	EndedOn        *time.Time `json:"ended_on"`
+	Duration       int        `json:"duration"`
+	FilamentUsed   float32    `json:"filament_used"`
+	Outcome        string     `json:"outcome"`
+	FailureReason  string     `json:"failure_reason"`
+	CreatedOn      time.Time  `json:"created_on"`
+}
+
+func NewPrintJob() *PrintJob {
+	return &PrintJob{Outcome: JobPrinting}
+}
+
+// EstimateQuery selects the succeeded jobs that ended in [From, To)
+type EstimateQuery struct {
+	From time.Time
+	To   time.Time
+}
+
+// EstimateRow compares the estimates of a print request with the sums of its succeeded
+// jobs. The errors are relative, (actual - estimated) / estimated, and 0 without an
+// estimate.
+type EstimateRow struct {
+	PrintRequestId    int     `json:"print_request_id"`
+	ItemName          string  `json:"item_name"`
+	Jobs              int     `json:"jobs"`
+	EstimatedDuration int     `json:"estimated_duration"`
+	ActualDuration    int     `json:"actual_duration"`
+	DurationError     float64 `json:"duration_error"`
+	EstimatedWeight   float32 `json:"estimated_weight"`
+	ActualWeight      float32 `json:"actual_weight"`
+	WeightError       float64 `json:"weight_error"`
+}
+
+// EstimateAccuracy sums the rows of an estimate report. The bias is the mean error, positive
+// when prints take more than estimated, and the mean absolute error how far off the
+// estimates are. Only rows with an estimate are counted in them.
+type EstimateAccuracy struct {
+	Requests                  int            `json:"requests"`
+	RequestsWithDuration      int            `json:"requests_with_duration"`
+	DurationBias              float64        `json:"duration_bias"`
+	DurationMeanAbsoluteError float64        `json:"duration_mean_absolute_error"`
+	RequestsWithWeight        int            `json:"requests_with_weight"`
+	WeightBias                float64        `json:"weight_bias"`
+	WeightMeanAbsoluteError   float64        `json:"weight_mean_absolute_error"`
+	Rows                      []*EstimateRow `json:"rows"`
+}
@@ -1,5 +1,94 @@
 package entity
 
+import "strings"
+
+// Print request statuses, a new request starts as StatusPendingReview. Requests created
+// before the review step started as StatusReceived.
+const (
+	StatusPendingReview    = "pending_review"
+	StatusChangesRequested = "changes_requested"
+	StatusReceived         = "received"
+	StatusProcessed        = "processed"
+	StatusApproved         = "approved"
+	StatusRejected         = "rejected"
+	StatusFinished         = "finished"
+	StatusFailed           = "failed"
+)
+
+var Statuses = []string{StatusPendingReview, StatusChangesRequested, StatusReceived, StatusProcessed, StatusApproved, StatusRejected, StatusFinished, StatusFailed}
+
+// TerminalStatuses are the statuses of requests that are done with, every other status is
+// active. A failed request can still be approved again, but until then it is not printed.
+var TerminalStatuses = []string{StatusRejected, StatusFinished, StatusFailed}
+
+// IsTerminalStatus tells whether status is one of TerminalStatuses
+func IsTerminalStatus(status string) bool {
+	for _, s := range TerminalStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// InReviewStatuses are the statuses of requests in review, which only change by a ReviewEntry
+var InReviewStatuses = []string{StatusPendingReview, StatusChangesRequested}
+
+// IsInReview tells whether status is one of InReviewStatuses
+func IsInReview(status string) bool {
+	for _, s := range InReviewStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusTransitions are the statuses a batch change can move a request to from each status.
+// A failed print can be approved again, rejected and finished requests are done. Requests
+// in review only move by a ReviewEntry, so InReviewStatuses are left out.
+var StatusTransitions = map[string][]string{
+	StatusReceived:  {StatusProcessed, StatusApproved, StatusRejected},
+	StatusProcessed: {StatusApproved, StatusRejected},
+	StatusApproved:  {StatusFinished, StatusFailed},
+	StatusFailed:    {StatusApproved, StatusRejected},
+}
+
+// CanChangeStatus tells whether a request can move from one status to another, keeping
+// the same status is always allowed
+func CanChangeStatus(from string, to string) bool {
+	if from == to {
+		return true
+	}
+	for _, s := range StatusTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Materials a request can be printed in
+const (
+	MaterialPla   = "pla"
+	MaterialPetg  = "petg"
+	MaterialAbs   = "abs"
+	MaterialAsa   = "asa"
+	MaterialTpu   = "tpu"
+	MaterialNylon = "nylon"
+)
+
+var Materials = []string{MaterialPla, MaterialPetg, MaterialAbs, MaterialAsa, MaterialTpu, MaterialNylon}
+
+// Print settings of a new request that does not set them
+const (
+	DefaultMaterial    = MaterialPla
+	DefaultLayerHeight = 0.2 // mm
+	DefaultInfill      = 20  // percent
+	DefaultNozzleSize  = 0.4 // mm
+)
+
+// PrintRequest is one part to print. The estimates are for all Quantity copies together.
 type PrintRequest struct {
 	Id                      int     `json:"id"`
 	ItemName                string  `json:"item_name"`
@@ -7,10 +96,69 @@ type PrintRequest struct {
 	EstimatedFilamentLength float32 `json:"estimated_filament_length"`
 	EstimatedDuration       int     `json:"estimated_duration"`
 	FileUrl                 string  `json:"file_url"`
+	FileKey                 string  `json:"file_key"`
+	FileHash                string  `json:"file_hash"`
+	DuplicateOf             int     `json:"duplicate_of"`
 	Requestor               string  `json:"requestor"`
+	Notes                   string  `json:"notes"`
+	Material                string  `json:"material"`
+	Color                   string  `json:"color"`
+	LayerHeight             float32 `json:"layer_height"` // mm
+	Infill                  int     `json:"infill"`       // percent
+	Supports                bool    `json:"supports"`
+	NozzleSize              float32 `json:"nozzle_size"` // mm
+	Quantity                int     `json:"quantity"`
+	EstimatedCost           float32 `json:"estimated_cost"` // of the material, see estimator.Cost
+	ProjectId               int     `json:"project_id"`     // 0 when the request is not part of a Project
+	Reviewer                string  `json:"reviewer"`       // assigned on creation, empty when anyone may review
 	Status                  string  `json:"status"`
 }
 
+// NewPrintRequest returns a request with the default print settings
 func NewPrintRequest() *PrintRequest {
-	return &PrintRequest{}
+	return &PrintRequest{
+		Material:    DefaultMaterial,
+		LayerHeight: DefaultLayerHeight,
+		Infill:      DefaultInfill,
+		NozzleSize:  DefaultNozzleSize,
+		Quantity:    1,
+	}
+}
+
+// PrintRequestFilter narrows the lists of active print requests, empty fields match all.
+// Color matches case-insensitively and Supports is only compared when set.
+type PrintRequestFilter struct {
+	Requestor string
+	Status    string
+	Material  string
+	Color     string
+	Supports  *bool
+	ProjectId int
+}
+
+// Matches tells whether model is selected by the filter, a nil filter matches all
+func (f *PrintRequestFilter) Matches(model *PrintRequest) bool {
+	if f == nil {
+		return true
+	}
+	return (f.Requestor == "" || model.Requestor == f.Requestor) &&
+		(f.Status == "" || model.Status == f.Status) &&
+		(f.Material == "" || model.Material == f.Material) &&
+		(f.Color == "" || strings.EqualFold(model.Color, f.Color)) &&
+		(f.Supports == nil || model.Supports == *f.Supports) &&
+		(f.ProjectId == 0 || model.ProjectId == f.ProjectId)
+}
+
+// SearchResult is a print request found by a full-text search. The highlights are the
+// fields HTML escaped, with the matched words wrapped in <mark></mark>.
+type SearchResult struct {
+	*PrintRequest
+	Rank       float32   `json:"rank"`
+	Highlights Highlight `json:"highlights"`
+}
+
+type Highlight struct {
+	ItemName  string `json:"item_name"`
+	Requestor string `json:"requestor"`
+	Notes     string `json:"notes"`
 }
@@ -0,0 +1,14 @@
+package entity
+
+// Printer is a machine in the lab. Build volume is in mm.
+type Printer struct {
+	Id          int     `json:"id"`
+	Name        string  `json:"name"`
+	BuildWidth  float64 `json:"build_width"`
+	BuildDepth  float64 `json:"build_depth"`
+	BuildHeight float64 `json:"build_height"`
+}
+
+func NewPrinter() *Printer {
+	return &Printer{}
+}
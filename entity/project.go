@@ -0,0 +1,77 @@
+package entity
+
+import "time"
+
+// Project statuses, derived from the statuses of the parts by ProjectStatus
+const (
+	ProjectEmpty      = "empty"
+	ProjectInReview   = "in_review"
+	ProjectInProgress = "in_progress"
+	ProjectFailed     = "failed"
+	ProjectFinished   = "finished"
+	ProjectRejected   = "rejected"
+)
+
+// Project groups the print requests of the parts of one thing, e.g. a drone frame. The
+// totals sum the estimates of the active parts that are not rejected, Parts counts all
+// active parts by status.
+type Project struct {
+	Id                      int            `json:"id"`
+	Name                    string         `json:"name"`
+	Owner                   string         `json:"owner"`
+	Description             string         `json:"description"`
+	Status                  string         `json:"status"`
+	Parts                   map[string]int `json:"parts"`
+	EstimatedWeight         float32        `json:"estimated_weight"`
+	EstimatedFilamentLength float32        `json:"estimated_filament_length"`
+	EstimatedDuration       int            `json:"estimated_duration"`
+	EstimatedCost           float32        `json:"estimated_cost"`
+	CreatedOn               time.Time      `json:"created_on"`
+}
+
+func NewProject() *Project {
+	return &Project{Status: ProjectEmpty, Parts: make(map[string]int)}
+}
+
+// AddParts counts parts of the project in status and adds their estimates to the totals
+// unless they are rejected
+func (p *Project) AddParts(status string, count int, weight float32, filamentLength float32, duration int, cost float32) {
+	p.Parts[status] += count
+	if status != StatusRejected {
+		p.EstimatedWeight += weight
+		p.EstimatedFilamentLength += filamentLength
+		p.EstimatedDuration += duration
+		p.EstimatedCost += cost
+	}
+	p.Status = ProjectStatus(p.Parts)
+}
+
+// ProjectStatus derives the status of a project from the number of its parts by status.
+// A project is rejected when all its parts are, failed when one of them failed and in review
+// while one is not approved yet. It is finished when all parts that are not rejected are
+// finished, and in progress otherwise.
+func ProjectStatus(parts map[string]int) string {
+	total, rejected, finished := 0, 0, 0
+	for status, count := range parts {
+		total += count
+		switch status {
+		case StatusRejected:
+			rejected += count
+		case StatusFinished:
+			finished += count
+		}
+	}
+	switch {
+	case total == 0:
+		return ProjectEmpty
+	case rejected == total:
+		return ProjectRejected
+	case parts[StatusFailed] > 0:
+		return ProjectFailed
+	case parts[StatusPendingReview] > 0 || parts[StatusChangesRequested] > 0 || parts[StatusReceived] > 0 || parts[StatusProcessed] > 0:
+		return ProjectInReview
+	case finished+rejected == total:
+		return ProjectFinished
+	}
+	return ProjectInProgress
+}
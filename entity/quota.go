@@ -0,0 +1,21 @@
+package entity
+
+// Quota limits what one requestor can have in the queue, a zero limit is unlimited
+type Quota struct {
+	Requestor        string  `json:"requestor"`
+	MaxActive        int     `json:"max_active"`
+	MaxGramsPerMonth float64 `json:"max_grams_per_month"`
+	MaxHoursPerWeek  float64 `json:"max_hours_per_week"`
+}
+
+func NewQuota() *Quota {
+	return &Quota{}
+}
+
+// QuotaUsage is what a requestor has used of their Quota. Rejected and deleted requests
+// do not count.
+type QuotaUsage struct {
+	Active         int     `json:"active"`           // not in one of TerminalStatuses
+	GramsThisMonth float64 `json:"grams_this_month"` // requested since the first of the month, UTC
+	HoursThisWeek  float64 `json:"hours_this_week"`  // requested since Monday, UTC
+}
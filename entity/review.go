@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// Review actions, the first three are taken by the reviewer of a request in
+// StatusPendingReview and ReviewResubmit by its requestor after ReviewRequestChanges
+const (
+	ReviewApprove        = "approve"
+	ReviewReject         = "reject"
+	ReviewRequestChanges = "request_changes"
+	ReviewResubmit       = "resubmit"
+)
+
+// ReviewStatuses are the statuses the review actions move a request to
+var ReviewStatuses = map[string]string{
+	ReviewApprove:        StatusApproved,
+	ReviewReject:         StatusRejected,
+	ReviewRequestChanges: StatusChangesRequested,
+	ReviewResubmit:       StatusPendingReview,
+}
+
+// ReviewEntry is one message of the review thread of a print request
+type ReviewEntry struct {
+	Id             int       `json:"id"`
+	PrintRequestId int       `json:"print_request_id"`
+	Author         string    `json:"author"`
+	Action         string    `json:"action"`
+	Comment        string    `json:"comment"`
+	CreatedOn      time.Time `json:"created_on"`
+}
+
+func NewReviewEntry() *ReviewEntry {
+	return &ReviewEntry{}
+}
@@ -0,0 +1,14 @@
+package entity
+
+type StoredFile struct {
+	Key         string `json:"key"`
+	Filename    string `json:"filename"`
+	Hash        string `json:"hash"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+	Existed     bool   `json:"-"` // the same content was stored before Save, see Files.Discard
+}
+
+func NewStoredFile() *StoredFile {
+	return &StoredFile{}
+}
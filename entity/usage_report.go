@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+// Intervals of a usage report, a period starts on the first day, weeks on Monday
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// UsageQuery selects the print requests created in [From, To) and how they are grouped
+type UsageQuery struct {
+	From        time.Time
+	To          time.Time
+	Interval    string
+	ByRequestor bool
+	ByStatus    bool
+}
+
+// UsageRow sums the estimates of the requests of one period. Requestor and Status are
+// only set when the report is grouped by them.
+type UsageRow struct {
+	Period         string  `json:"period"`
+	Requestor      string  `json:"requestor,omitempty"`
+	Status         string  `json:"status,omitempty"`
+	Requests       int     `json:"requests"`
+	Weight         float64 `json:"weight"`
+	FilamentLength float64 `json:"filament_length"`
+	Duration       int     `json:"duration"`
+}
@@ -0,0 +1,54 @@
+package entity
+
+import "time"
+
+// Print request lifecycle events that webhooks can subscribe to
+const (
+	EventCreated       = "created"
+	EventUpdated       = "updated"
+	EventStatusChanged = "status_changed"
+	EventDeleted       = "deleted"
+)
+
+var Events = []string{EventCreated, EventUpdated, EventStatusChanged, EventDeleted}
+
+// StatusChangedEvent is the data of EventStatusChanged
+type StatusChangedEvent struct {
+	*PrintRequest
+	PreviousStatus string `json:"previous_status"`
+}
+
+// DeletedEvent is the data of EventDeleted
+type DeletedEvent struct {
+	Id        int    `json:"id"`
+	Requestor string `json:"requestor"`
+	Status    string `json:"status"`
+}
+
+type WebhookSubscription struct {
+	Id     int      `json:"id"`
+	Url    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"`
+	Events []string `json:"events"`
+}
+
+func NewWebhookSubscription() *WebhookSubscription {
+	return &WebhookSubscription{}
+}
+
+// WebhookDelivery is one delivery attempt, kept as a log
+type WebhookDelivery struct {
+	Id             int       `json:"id"`
+	SubscriptionId int       `json:"subscription_id"`
+	Event          string    `json:"event"`
+	Payload        string    `json:"payload"`
+	Attempt        int       `json:"attempt"`
+	StatusCode     int       `json:"status_code"`
+	Error          string    `json:"error"`
+	Success        bool      `json:"success"`
+	CreatedOn      time.Time `json:"created_on"`
+}
+
+func NewWebhookDelivery() *WebhookDelivery {
+	return &WebhookDelivery{}
+}
@@ -5,8 +5,11 @@ go 1.16
 require (
 	github.com/julienschmidt/httprouter v1.3.0
 	github.com/lib/pq v1.10.3
+	github.com/nats-io/nats-server/v2 v2.6.6
+	github.com/nats-io/nats.go v1.14.0
 	github.com/rs/cors v1.8.0
 	github.com/sirupsen/logrus v1.8.1
 	github.com/stretchr/testify v1.4.0
 	github.com/subosito/gotenv v1.2.0
+	modernc.org/sqlite v1.14.8
 )
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+	"threedee/entity"
+	print_request "threedee/interfaces/print-request"
+	print_request_comment "threedee/interfaces/print-request-comment"
+	"threedee/storage"
+	"threedee/utility/normalizer"
+	"threedee/utility/response"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type CommentHandler struct {
+	Repo     print_request_comment.CommentRepositoryInterface
+	Requests print_request.PrintRequestRepositoryInterface
+	Norm     *normalizer.CommentNormalizer
+	Files    *storage.Files // optional, comments can not have attachments when nil
+}
+
+func NewCommentHandler(repo print_request_comment.CommentRepositoryInterface, requests print_request.PrintRequestRepositoryInterface, norm *normalizer.CommentNormalizer, files *storage.Files) *CommentHandler {
+	return &CommentHandler{repo, requests, norm, files}
+}
+
+// handle GET /print-requests/:id/comments
+func (h *CommentHandler) Index(w http.ResponseWriter, r *http.Request, p httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	id, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("id is not a number"))
+	}
+
+	request, err := h.Requests.GetById(id)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+	if request == nil || request.Id == 0 {
+		return http.StatusNotFound, response.WriteNotFoundError(w, errors.New("record not found"))
+	}
+
+	data, err := h.Repo.GetByPrintRequest(id)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+	for _, comment := range data {
+		h.sign(comment)
+	}
+
+	return http.StatusOK, response.WriteSuccess(w, data, "success")
+}
+
+// handle POST /print-requests/:id/comments
+//
+// Accepts a JSON body or a multipart/form-data body with images in "attachments". The images
+// are saved to the file storage before the comment, and every attachment in the answer has
+// a signed download URL.
+func (h *CommentHandler) Create(w http.ResponseWriter, r *http.Request, p httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	id, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("id is not a number"))
+	}
+
+	model, uploads, err := h.Norm.ReadAndNormalize(w, r)
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, err)
+	}
+	if len(uploads) > 0 && h.Files == nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("attachments are not accepted without file storage"))
+	}
+
+	// no files are stored for requests that are gone
+	request, err := h.Requests.GetById(id)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+	if request == nil || request.Id == 0 {
+		return http.StatusNotFound, response.WriteNotFoundError(w, errors.New("record not found"))
+	}
+
+	for _, upload := range uploads {
+		file, err := h.Files.Save(upload.Filename, bytes.NewReader(upload.Data))
+		if err == storage.ErrFileTooLarge {
+			return http.StatusRequestEntityTooLarge, response.WriteRequestEntityTooLargeError(w, err)
+		}
+		if err != nil {
+			return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+		}
+		model.Attachments = append(model.Attachments, &entity.Attachment{
+			Key:         file.Key,
+			Filename:    file.Filename,
+			ContentType: file.ContentType,
+			Size:        file.Size,
+		})
+	}
+
+	model.PrintRequestId = id
+	model.Id, err = h.Repo.Insert(model)
+	if err != nil {
+		return writeRepositoryError(w, err)
+	}
+	model.CreatedOn = time.Now().UTC()
+	h.sign(model)
+
+	return http.StatusOK, response.WriteSuccess(w, model, "success")
+}
+
+// sign sets the download URLs of the attachments of comment
+func (h *CommentHandler) sign(comment *entity.Comment) {
+	if h.Files == nil {
+		return
+	}
+	now := time.Now()
+	for _, attachment := range comment.Attachments {
+		attachment.Url = h.Files.SignedUrl(attachment.Key, now)
+	}
+}
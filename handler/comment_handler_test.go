@@ -0,0 +1,177 @@
+package handler_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"threedee/entity"
+	"threedee/handler"
+	"threedee/storage"
+	"threedee/testdata/mock"
+	"threedee/utility/normalizer"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	testifymock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// a 1x1 PNG
+var pngImage = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00\x1f\x15\xc4\x89\x00\x00\x00\rIDATx\x9cc\xf8\x0f\x00\x00\x01\x01\x00\x05\x18\xd8N\x00\x00\x00\x00IEND\xaeB`\x82")
+
+type CommentHandlerTestSuite struct {
+	suite.Suite
+	mockCommentRepo *mock.MockCommentRepository
+	mockPanelRepo   *mock.MockPrintRequestRepository
+	handlerInstance handler.CommentHandler
+}
+
+func (suite *CommentHandlerTestSuite) SetupTest() {
+	local, _ := storage.NewLocalStorage(suite.T().TempDir())
+	suite.mockCommentRepo = &mock.MockCommentRepository{}
+	suite.mockPanelRepo = &mock.MockPrintRequestRepository{}
+	suite.handlerInstance = handler.CommentHandler{
+		Repo:     suite.mockCommentRepo,
+		Requests: suite.mockPanelRepo,
+		Norm:     normalizer.NewCommentNormalizer(),
+		Files:    storage.NewFiles(local, 1<<20, "secret", time.Minute),
+	}
+}
+
+// multipartComment returns a multipart body with the comment fields and one attachment
+func multipartComment(author string, body string, filename string, data []byte) (*bytes.Buffer, string) {
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	form.WriteField("author", author)
+	form.WriteField("body", body)
+	part, _ := form.CreateFormFile("attachments", filename)
+	part.Write(data)
+	form.Close()
+	return &buf, form.FormDataContentType()
+}
+
+//===============================================INDEX========================================================
+
+func (suite *CommentHandlerTestSuite) TestIndex() {
+	comments := []*entity.Comment{
+		{Id: 1, PrintRequestId: 1, Author: "andi", Body: "Red please", Attachments: []*entity.Attachment{{Key: strings.Repeat("a", 64) + ".png", Filename: "front.png"}}},
+	}
+	suite.mockPanelRepo.On("GetById", 1).Return(&entity.PrintRequest{Id: 1}, nil).Once()
+	suite.mockPanelRepo.On("GetById", 2).Return(&entity.PrintRequest{}, nil).Once()
+	suite.mockCommentRepo.On("GetByPrintRequest", 1).Return(comments, nil).Once()
+
+	req, _ := http.NewRequest("GET", "/print-requests/1/comments", nil)
+	responseRecorder := httptest.NewRecorder()
+	code, err := suite.handlerInstance.Index(responseRecorder, req, []httprouter.Param{{Key: "id", Value: "1"}})
+
+	suite.Equal(http.StatusOK, code)
+	suite.Nil(err)
+	suite.Contains(comments[0].Attachments[0].Url, "/files/"+comments[0].Attachments[0].Key+"?expires=")
+
+	req, _ = http.NewRequest("GET", "/print-requests/2/comments", nil)
+	code, err = suite.handlerInstance.Index(httptest.NewRecorder(), req, []httprouter.Param{{Key: "id", Value: "2"}})
+
+	suite.Equal(http.StatusNotFound, code)
+	suite.NotNil(err)
+}
+
+//===============================================CREATE========================================================
+
+func (suite *CommentHandlerTestSuite) TestCreate() {
+	var testCase = []struct {
+		testcase    string
+		id          string
+		body        func() (*bytes.Buffer, string)
+		isError     bool
+		code        int
+		attachments int
+	}{
+		{
+			testcase: "json",
+			id:       "1",
+			body: func() (*bytes.Buffer, string) {
+				return bytes.NewBufferString(`{"author":"andi","body":"Red please"}`), "application/json"
+			},
+			code: http.StatusOK,
+		},
+		{
+			testcase: "image attachment",
+			id:       "1",
+			body: func() (*bytes.Buffer, string) {
+				return multipartComment("andi", "", "front.png", pngImage)
+			},
+			code:        http.StatusOK,
+			attachments: 1,
+		},
+		{
+			testcase: "not an image",
+			id:       "1",
+			body: func() (*bytes.Buffer, string) {
+				return multipartComment("andi", "See file", "front.png", []byte("G1 X1 E1"))
+			},
+			isError: true,
+			code:    http.StatusBadRequest,
+		},
+		{
+			testcase: "no author",
+			id:       "1",
+			body: func() (*bytes.Buffer, string) {
+				return bytes.NewBufferString(`{"body":"Red please"}`), "application/json"
+			},
+			isError: true,
+			code:    http.StatusBadRequest,
+		},
+		{
+			testcase: "empty",
+			id:       "1",
+			body: func() (*bytes.Buffer, string) {
+				return bytes.NewBufferString(`{"author":"andi"}`), "application/json"
+			},
+			isError: true,
+			code:    http.StatusBadRequest,
+		},
+		{
+			testcase: "request not found",
+			id:       "2",
+			body: func() (*bytes.Buffer, string) {
+				return bytes.NewBufferString(`{"author":"andi","body":"Red please"}`), "application/json"
+			},
+			isError: true,
+			code:    http.StatusNotFound,
+		},
+	}
+	for _, tc := range testCase {
+		suite.SetupTest()
+		suite.mockPanelRepo.On("GetById", 1).Return(&entity.PrintRequest{Id: 1}, nil)
+		suite.mockPanelRepo.On("GetById", 2).Return(&entity.PrintRequest{}, nil)
+		suite.mockCommentRepo.On("Insert", testifymock.MatchedBy(func(model *entity.Comment) bool {
+			return model.PrintRequestId == 1 && len(model.Attachments) == tc.attachments
+		})).Return(7, nil)
+
+		body, contentType := tc.body()
+		req, _ := http.NewRequest("POST", "/print-requests/"+tc.id+"/comments", body)
+		req.Header.Set("Content-Type", contentType)
+		responseRecorder := httptest.NewRecorder()
+		code, err := suite.handlerInstance.Create(responseRecorder, req, []httprouter.Param{{Key: "id", Value: tc.id}})
+
+		suite.Equal(tc.code, code, tc.testcase)
+		if tc.isError {
+			suite.NotNil(err, tc.testcase)
+			suite.mockCommentRepo.AssertNotCalled(suite.T(), "Insert", testifymock.Anything)
+		} else {
+			suite.Nil(err, tc.testcase)
+			suite.Contains(responseRecorder.Body.String(), `"id":7`, tc.testcase)
+		}
+		if tc.attachments > 0 {
+			suite.Contains(responseRecorder.Body.String(), `"content_type":"image/png"`, tc.testcase)
+			suite.Contains(responseRecorder.Body.String(), `"url":"/files/`, tc.testcase)
+		}
+	}
+}
+
+func TestCommentHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(CommentHandlerTestSuite))
+}
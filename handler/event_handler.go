@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"threedee/sse"
+	"threedee/utility/response"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// heartbeatInterval keeps proxies from closing an idle stream
+const heartbeatInterval = 15 * time.Second
+
+type EventHandler struct {
+	Broker *sse.Broker
+}
+
+func NewEventHandler(broker *sse.Broker) *EventHandler {
+	return &EventHandler{broker}
+}
+
+// handle GET /print-requests/events?requestor=&status=
+//
+// Streams print request events as Server-Sent Events. A reconnecting client sends the id
+// of the last event it saw in the Last-Event-ID header and gets the events it missed.
+func (h *EventHandler) Stream(w http.ResponseWriter, r *http.Request, _ httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, errors.New("streaming is not supported"))
+	}
+
+	lastEventId := 0
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("Last-Event-ID is not a number"))
+		}
+		lastEventId = id
+	}
+
+	query := r.URL.Query()
+	filter := sse.Filter{Requestor: query.Get("requestor"), Status: query.Get("status")}
+	client, backlog := h.Broker.Subscribe(filter, lastEventId)
+	defer h.Broker.Unsubscribe(client)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	for _, e := range backlog {
+		writeEvent(w, e)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return http.StatusOK, nil
+		case e, open := <-client.Events:
+			if !open {
+				return http.StatusOK, errors.New("client could not keep up with the event stream")
+			}
+			writeEvent(w, e)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, e sse.Event) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Id, e.Type, e.Data)
+}
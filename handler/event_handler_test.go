@@ -0,0 +1,109 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"threedee/handler"
+	"threedee/sse"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/suite"
+)
+
+type EventHandlerTestSuite struct {
+	suite.Suite
+	broker          *sse.Broker
+	handlerInstance handler.EventHandler
+}
+
+func (suite *EventHandlerTestSuite) SetupTest() {
+	suite.broker = sse.NewBroker(10)
+	suite.handlerInstance = handler.EventHandler{Broker: suite.broker}
+}
+
+//===============================================STREAM========================================================
+
+func (suite *EventHandlerTestSuite) TestStream() {
+	suite.broker.Publish(sse.Event{Id: 1, Type: "created", Requestor: "andi", Data: json.RawMessage(`{"id":7}`)})
+	suite.broker.Publish(sse.Event{Id: 2, Type: "created", Requestor: "budi", Data: json.RawMessage(`{"id":8}`)})
+	suite.broker.Publish(sse.Event{Id: 3, Type: "deleted", Requestor: "andi", Data: json.RawMessage(`{"id":7}`)})
+
+	var testCase = []struct {
+		testcase    string
+		query       string
+		lastEventId string
+		isError     bool
+		code        int
+		body        string
+	}{
+		{
+			testcase:    "resume filtered",
+			query:       "?requestor=andi",
+			lastEventId: "1",
+			isError:     false,
+			code:        http.StatusOK,
+			body:        "id: 3\nevent: deleted\ndata: {\"id\":7}\n\n",
+		},
+		{
+			testcase:    "resume",
+			lastEventId: "1",
+			isError:     false,
+			code:        http.StatusOK,
+			body:        "id: 2\nevent: created\ndata: {\"id\":8}\n\nid: 3\nevent: deleted\ndata: {\"id\":7}\n\n",
+		},
+		{
+			testcase:    "invalid Last-Event-ID",
+			lastEventId: "abc",
+			isError:     true,
+			code:        http.StatusBadRequest,
+		},
+	}
+	for _, tc := range testCase {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		req, _ := http.NewRequestWithContext(ctx, "GET", "/print-requests/events"+tc.query, nil)
+		req.Header.Set("Last-Event-ID", tc.lastEventId)
+		responseRecorder := httptest.NewRecorder()
+
+		code, err := suite.handlerInstance.Stream(responseRecorder, req, httprouter.Params{})
+		cancel()
+
+		suite.Equal(tc.code, code, tc.testcase)
+		if tc.isError {
+			suite.NotNil(err, tc.testcase)
+		} else {
+			suite.Nil(err, tc.testcase)
+			suite.Equal("text/event-stream", responseRecorder.Header().Get("Content-Type"), tc.testcase)
+			suite.Equal(tc.body, responseRecorder.Body.String(), tc.testcase)
+		}
+	}
+}
+
+func (suite *EventHandlerTestSuite) TestStreamLive() {
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, "GET", "/print-requests/events?status=finished", nil)
+	responseRecorder := httptest.NewRecorder()
+
+	done := make(chan int)
+	go func() {
+		code, _ := suite.handlerInstance.Stream(responseRecorder, req, httprouter.Params{})
+		done <- code
+	}()
+
+	// wait for the handler to subscribe before publishing
+	time.Sleep(20 * time.Millisecond)
+	suite.broker.Publish(sse.Event{Id: 1, Type: "status_changed", Status: "processed", Data: json.RawMessage(`{}`)})
+	suite.broker.Publish(sse.Event{Id: 2, Type: "status_changed", Status: "finished", Data: json.RawMessage(`{}`)})
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	suite.Equal(http.StatusOK, <-done)
+	suite.Equal("id: 2\nevent: status_changed\ndata: {}\n\n", responseRecorder.Body.String())
+}
+
+func TestEventHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(EventHandlerTestSuite))
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	file_storage "threedee/interfaces/file-storage"
+	"threedee/storage"
+	"threedee/utility/response"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type FileHandler struct {
+	Files *storage.Files
+}
+
+func NewFileHandler(files *storage.Files) *FileHandler {
+	return &FileHandler{files}
+}
+
+// handle GET /files/:key?expires=&signature=
+func (h *FileHandler) Download(w http.ResponseWriter, r *http.Request, p httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	if h.Files == nil {
+		return http.StatusNotFound, response.WriteNotFoundError(w, errors.New("file storage is not configured"))
+	}
+
+	key := p.ByName("key")
+	query := r.URL.Query()
+	err := h.Files.Verify(key, query.Get("expires"), query.Get("signature"), time.Now())
+	if err != nil {
+		return http.StatusForbidden, response.WriteForbiddenError(w, err)
+	}
+
+	file, err := h.Files.Open(key)
+	if err == file_storage.ErrFileNotFound {
+		return http.StatusNotFound, response.WriteNotFoundError(w, err)
+	}
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+	defer file.Close()
+
+	contentType := mime.TypeByExtension(filepath.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+key+"\"")
+	w.WriteHeader(http.StatusOK)
+
+	// headers are already sent, so a failed copy can only be logged
+	_, err = io.Copy(w, file)
+	return http.StatusOK, err
+}
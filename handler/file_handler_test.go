@@ -0,0 +1,84 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"threedee/handler"
+	"threedee/storage"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/suite"
+)
+
+type FileHandlerTestSuite struct {
+	suite.Suite
+	files           *storage.Files
+	handlerInstance handler.FileHandler
+}
+
+func (suite *FileHandlerTestSuite) SetupTest() {
+	local, _ := storage.NewLocalStorage(suite.T().TempDir())
+	suite.files = storage.NewFiles(local, 1<<20, "secret", time.Minute)
+	suite.handlerInstance = handler.FileHandler{Files: suite.files}
+}
+
+//===============================================DOWNLOAD========================================================
+
+func (suite *FileHandlerTestSuite) TestDownload() {
+	file, _ := suite.files.Save("cup.gcode", strings.NewReader("G1 X1 E1"))
+	signed, _ := url.Parse(suite.files.SignedUrl(file.Key, time.Now()))
+	missingKey := strings.Repeat("0", 64) + ".gcode"
+	missing, _ := url.Parse(suite.files.SignedUrl(missingKey, time.Now()))
+
+	var testCase = []struct {
+		testcase string
+		key      string
+		query    string
+		isError  bool
+		code     int
+	}{
+		{
+			testcase: "success",
+			key:      file.Key,
+			query:    signed.RawQuery,
+			isError:  false,
+			code:     http.StatusOK,
+		},
+		{
+			testcase: "unsigned",
+			key:      file.Key,
+			query:    "",
+			isError:  true,
+			code:     http.StatusForbidden,
+		},
+		{
+			testcase: "missing file",
+			key:      missingKey,
+			query:    missing.RawQuery,
+			isError:  true,
+			code:     http.StatusNotFound,
+		},
+	}
+	for _, tc := range testCase {
+		req, _ := http.NewRequest("GET", "/files/"+tc.key+"?"+tc.query, nil)
+		responseRecorder := httptest.NewRecorder()
+
+		code, err := suite.handlerInstance.Download(responseRecorder, req, []httprouter.Param{{Key: "key", Value: tc.key}})
+
+		suite.Equal(tc.code, code, tc.testcase)
+		if tc.isError {
+			suite.NotNil(err, tc.testcase)
+		} else {
+			suite.Nil(err, tc.testcase)
+			suite.Equal("G1 X1 E1", responseRecorder.Body.String())
+		}
+	}
+}
+
+func TestFileHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(FileHandlerTestSuite))
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+	notification_preference "threedee/interfaces/notification-preference"
+	"threedee/utility/normalizer"
+	"threedee/utility/response"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type NotificationPreferenceHandler struct {
+	Repo notification_preference.NotificationPreferenceRepositoryInterface
+	Norm *normalizer.NotificationPreferenceNormalizer
+}
+
+func NewNotificationPreferenceHandler(repo notification_preference.NotificationPreferenceRepositoryInterface, norm *normalizer.NotificationPreferenceNormalizer) *NotificationPreferenceHandler {
+	return &NotificationPreferenceHandler{repo, norm}
+}
+
+// handle GET /notification-preferences/:requestor
+func (h *NotificationPreferenceHandler) Show(w http.ResponseWriter, r *http.Request, p httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	data, err := h.Repo.GetByRequestor(p.ByName("requestor"))
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+
+	// requestors without a stored preference get the defaults
+	data.Requestor = p.ByName("requestor")
+	return http.StatusOK, response.WriteSuccess(w, data, "success")
+}
+
+// handle PUT /notification-preferences/:requestor
+func (h *NotificationPreferenceHandler) Update(w http.ResponseWriter, r *http.Request, p httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	model, err := h.Norm.ReadAndNormalize(w, r)
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, err)
+	}
+
+	model.Requestor = p.ByName("requestor")
+	err = h.Repo.Upsert(model)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+
+	return http.StatusOK, response.WriteSuccess(w, model, "success")
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"threedee/entity"
+	print_job "threedee/interfaces/print-job"
+	print_request "threedee/interfaces/print-request"
+	"threedee/interfaces/printer"
+	"threedee/utility/normalizer"
+	"threedee/utility/response"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type PrintJobHandler struct {
+	Repo     print_job.PrintJobRepositoryInterface
+	Requests print_request.PrintRequestRepositoryInterface
+	Printers printer.PrinterRepositoryInterface // optional, printer ids are not checked when nil or empty
+	Norm     *normalizer.PrintJobNormalizer
+}
+
+func NewPrintJobHandler(repo print_job.PrintJobRepositoryInterface, requests print_request.PrintRequestRepositoryInterface, printers printer.PrinterRepositoryInterface, norm *normalizer.PrintJobNormalizer) *PrintJobHandler {
+	return &PrintJobHandler{repo, requests, printers, norm}
+}
+
+// handle GET /print-requests/:id/jobs
+func (h *PrintJobHandler) Index(w http.ResponseWriter, r *http.Request, p httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	id, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("id is not a number"))
+	}
+
+	request, err := h.Requests.GetById(id)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+	if request == nil || request.Id == 0 {
+		return http.StatusNotFound, response.WriteNotFoundError(w, errors.New("record not found"))
+	}
+
+	data, err := h.Repo.GetByPrintRequest(id)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+
+	return http.StatusOK, response.WriteSuccess(w, data, "success")
+}
+
+// handle POST /print-requests/:id/jobs
+//
+// Records an attempt at printing an approved request, when it starts or after it ended. The
+// status of the request is not changed, that is still done with PUT /print-requests/:id/status.
+func (h *PrintJobHandler) Create(w http.ResponseWriter, r *http.Request, p httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	id, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("id is not a number"))
+	}
+
+	model, err := h.Norm.ReadAndNormalize(w, r)
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, err)
+	}
+
+	request, err := h.Requests.GetById(id)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+	if request == nil || request.Id == 0 {
+		return http.StatusNotFound, response.WriteNotFoundError(w, errors.New("record not found"))
+	}
+	if !printable(request.Status) {
+		return http.StatusConflict, response.WriteConflictError(w, nil, errors.New("jobs can only be recorded for "+strings.Join(entity.JobStatuses, ", ")+" requests, not "+request.Status))
+	}
+	known, err := h.knownPrinter(model.PrinterId)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+	if !known {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("printer "+strconv.Itoa(model.PrinterId)+" is not in the printer registry"))
+	}
+
+	model.PrintRequestId = id
+	jobId, err := h.Repo.Insert(model)
+	if err != nil {
+		return writeRepositoryError(w, err)
+	}
+
+	data, err := h.Repo.GetById(jobId)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+
+	return http.StatusOK, response.WriteSuccess(w, data, "success")
+}
+
+// handle PUT /print-requests/:id/jobs/:job
+//
+// Ends a job that is printing, or corrects a recorded one
+func (h *PrintJobHandler) Update(w http.ResponseWriter, r *http.Request, p httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	id, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("id is not a number"))
+	}
+	jobId, err := strconv.Atoi(p.ByName("job"))
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("job is not a number"))
+	}
+
+	model, err := h.Norm.ReadAndNormalize(w, r)
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, err)
+	}
+
+	request, err := h.Requests.GetById(id)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+	stored, err := h.Repo.GetById(jobId)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+	if request == nil || request.Id == 0 || stored == nil || stored.PrintRequestId != id {
+		return http.StatusNotFound, response.WriteNotFoundError(w, errors.New("record not found"))
+	}
+	known, err := h.knownPrinter(model.PrinterId)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+	if !known {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("printer "+strconv.Itoa(model.PrinterId)+" is not in the printer registry"))
+	}
+
+	model.Id = jobId
+	model.PrintRequestId = id
+	_, err = h.Repo.Update(model)
+	if err != nil {
+		return writeRepositoryError(w, err)
+	}
+
+	data, err := h.Repo.GetById(jobId)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+
+	return http.StatusOK, response.WriteSuccess(w, data, "success")
+}
+
+// knownPrinter tells whether printerId is in the printer registry. An empty registry is not
+// checked, e.g. with DB_DRIVER memory.
+func (h *PrintJobHandler) knownPrinter(printerId int) (bool, error) {
+	if h.Printers == nil {
+		return true, nil
+	}
+	printers, err := h.Printers.GetAll()
+	if err != nil {
+		return false, err
+	}
+	if len(printers) == 0 {
+		return true, nil
+	}
+	for _, p := range printers {
+		if p.Id == printerId {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// printable tells whether jobs can be recorded for a request in status
+func printable(status string) bool {
+	for _, s := range entity.JobStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,247 @@
+package handler_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"threedee/entity"
+	"threedee/handler"
+	print_request "threedee/interfaces/print-request"
+	"threedee/testdata/mock"
+	"threedee/utility/normalizer"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	testifymock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type PrintJobHandlerTestSuite struct {
+	suite.Suite
+	mockJobRepo     *mock.MockPrintJobRepository
+	mockPanelRepo   *mock.MockPrintRequestRepository
+	mockPrinterRepo *mock.MockPrinterRepository
+	handlerInstance handler.PrintJobHandler
+}
+
+func (suite *PrintJobHandlerTestSuite) SetupTest() {
+	suite.mockJobRepo = &mock.MockPrintJobRepository{}
+	suite.mockPanelRepo = &mock.MockPrintRequestRepository{}
+	suite.mockPrinterRepo = &mock.MockPrinterRepository{}
+	suite.handlerInstance = handler.PrintJobHandler{
+		Repo:     suite.mockJobRepo,
+		Requests: suite.mockPanelRepo,
+		Printers: suite.mockPrinterRepo,
+		Norm:     normalizer.NewPrintJobNormalizer(),
+	}
+	suite.mockPrinterRepo.On("GetAll").Return([]*entity.Printer{{Id: 1, Name: "Prusa MK3"}, {Id: 2, Name: "Ender 3"}}, nil)
+}
+
+// newPrintJob returns a stored job of request 1
+func newPrintJob(id int, outcome string) *entity.PrintJob {
+	started := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+	job := &entity.PrintJob{Id: id, PrintRequestId: 1, PrinterId: 1, StartedOn: started, Outcome: outcome}
+	if outcome != entity.JobPrinting {
+		ended := started.Add(2 * time.Hour)
+		job.EndedOn = &ended
+		job.Duration = 7200
+	}
+	return job
+}
+
+//===============================================INDEX========================================================
+
+func (suite *PrintJobHandlerTestSuite) TestIndex() {
+	suite.mockPanelRepo.On("GetById", 1).Return(&entity.PrintRequest{Id: 1, Status: entity.StatusApproved}, nil)
+	suite.mockPanelRepo.On("GetById", 2).Return(&entity.PrintRequest{}, nil)
+	suite.mockJobRepo.On("GetByPrintRequest", 1).Return([]*entity.PrintJob{newPrintJob(1, entity.JobFailed), newPrintJob(2, entity.JobPrinting)}, nil).Once()
+
+	req, _ := http.NewRequest("GET", "/print-requests/1/jobs", nil)
+	responseRecorder := httptest.NewRecorder()
+	code, err := suite.handlerInstance.Index(responseRecorder, req, []httprouter.Param{{Key: "id", Value: "1"}})
+
+	suite.Equal(http.StatusOK, code)
+	suite.Nil(err)
+	suite.Contains(responseRecorder.Body.String(), `"ended_on":null`)
+
+	code, err = suite.handlerInstance.Index(httptest.NewRecorder(), req, []httprouter.Param{{Key: "id", Value: "2"}})
+	suite.Equal(http.StatusNotFound, code)
+	suite.NotNil(err)
+
+	code, err = suite.handlerInstance.Index(httptest.NewRecorder(), req, []httprouter.Param{{Key: "id", Value: "a"}})
+	suite.Equal(http.StatusBadRequest, code)
+	suite.NotNil(err)
+}
+
+//===============================================CREATE========================================================
+
+func (suite *PrintJobHandlerTestSuite) TestCreate() {
+	var testCase = []struct {
+		testcase string
+		reqBody  string
+		status   string
+		insert   error
+		isError  bool
+		code     int
+	}{
+		{
+			testcase: "started",
+			reqBody:  `{"printer_id":1,"started_on":"2021-03-01T17:00:00+07:00"}`,
+			status:   entity.StatusApproved,
+			code:     http.StatusOK,
+		},
+		{
+			testcase: "recorded after it ended",
+			reqBody:  `{"printer_id":1,"started_on":"2021-03-01T10:00:00Z","ended_on":"2021-03-01T12:30:00Z","filament_used":40.5,"outcome":"succeeded"}`,
+			status:   entity.StatusFinished,
+			code:     http.StatusOK,
+		},
+		{
+			testcase: "succeeded without filament used",
+			reqBody:  `{"printer_id":1,"started_on":"2021-03-01T10:00:00Z","ended_on":"2021-03-01T12:30:00Z","outcome":"succeeded"}`,
+			status:   entity.StatusApproved,
+			isError:  true,
+			code:     http.StatusBadRequest,
+		},
+		{
+			testcase: "failed without reason",
+			reqBody:  `{"printer_id":1,"started_on":"2021-03-01T10:00:00Z","ended_on":"2021-03-01T10:30:00Z","outcome":"failed"}`,
+			status:   entity.StatusApproved,
+			isError:  true,
+			code:     http.StatusBadRequest,
+		},
+		{
+			testcase: "ended without outcome",
+			reqBody:  `{"printer_id":1,"started_on":"2021-03-01T10:00:00Z","ended_on":"2021-03-01T10:30:00Z"}`,
+			status:   entity.StatusApproved,
+			isError:  true,
+			code:     http.StatusBadRequest,
+		},
+		{
+			testcase: "ended before started",
+			reqBody:  `{"printer_id":1,"started_on":"2021-03-01T10:00:00Z","ended_on":"2021-03-01T09:00:00Z","outcome":"cancelled"}`,
+			status:   entity.StatusApproved,
+			isError:  true,
+			code:     http.StatusBadRequest,
+		},
+		{
+			testcase: "no start",
+			reqBody:  `{"printer_id":1}`,
+			status:   entity.StatusApproved,
+			isError:  true,
+			code:     http.StatusBadRequest,
+		},
+		{
+			testcase: "unknown printer",
+			reqBody:  `{"printer_id":3,"started_on":"2021-03-01T10:00:00Z"}`,
+			status:   entity.StatusApproved,
+			isError:  true,
+			code:     http.StatusBadRequest,
+		},
+		{
+			testcase: "not approved",
+			reqBody:  `{"printer_id":1,"started_on":"2021-03-01T10:00:00Z"}`,
+			status:   entity.StatusPendingReview,
+			isError:  true,
+			code:     http.StatusConflict,
+		},
+		{
+			testcase: "request deleted meanwhile",
+			reqBody:  `{"printer_id":1,"started_on":"2021-03-01T10:00:00Z"}`,
+			status:   entity.StatusApproved,
+			insert:   &print_request.NotFoundError{Id: 1},
+			isError:  true,
+			code:     http.StatusNotFound,
+		},
+	}
+	for _, tc := range testCase {
+		suite.SetupTest()
+		suite.mockPanelRepo.On("GetById", 1).Return(&entity.PrintRequest{Id: 1, Status: tc.status}, nil).Once()
+		suite.mockJobRepo.On("Insert", testifymock.MatchedBy(func(model *entity.PrintJob) bool {
+			return model.PrintRequestId == 1 && model.PrinterId == 1 && model.StartedOn.Equal(time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC))
+		})).Return(3, tc.insert).Once()
+		suite.mockJobRepo.On("GetById", 3).Return(newPrintJob(3, entity.JobPrinting), nil).Once()
+
+		req, _ := http.NewRequest("POST", "/print-requests/1/jobs", strings.NewReader(tc.reqBody))
+		code, err := suite.handlerInstance.Create(httptest.NewRecorder(), req, []httprouter.Param{{Key: "id", Value: "1"}})
+
+		suite.Equal(tc.code, code, tc.testcase)
+		if tc.isError {
+			suite.NotNil(err, tc.testcase)
+			if tc.insert == nil {
+				suite.mockJobRepo.AssertNotCalled(suite.T(), "Insert", testifymock.Anything)
+			}
+		} else {
+			suite.Nil(err, tc.testcase)
+			suite.mockJobRepo.AssertExpectations(suite.T())
+		}
+	}
+}
+
+func (suite *PrintJobHandlerTestSuite) TestCreateDuration() {
+	suite.mockPanelRepo.On("GetById", 1).Return(&entity.PrintRequest{Id: 1, Status: entity.StatusApproved}, nil).Once()
+	suite.mockJobRepo.On("Insert", testifymock.MatchedBy(func(model *entity.PrintJob) bool {
+		return model.Duration == 9000 && model.Outcome == entity.JobFailed && model.FailureReason == "Warped corner" && model.FilamentUsed == 12.5
+	})).Return(3, nil).Once()
+	suite.mockJobRepo.On("GetById", 3).Return(newPrintJob(3, entity.JobFailed), nil).Once()
+
+	req, _ := http.NewRequest("POST", "/print-requests/1/jobs", strings.NewReader(`{"printer_id":2,"started_on":"2021-03-01T10:00:00Z","ended_on":"2021-03-01T12:30:00.600Z","filament_used":12.5,"outcome":" Failed ","failure_reason":" Warped corner "}`))
+	code, err := suite.handlerInstance.Create(httptest.NewRecorder(), req, []httprouter.Param{{Key: "id", Value: "1"}})
+
+	suite.Equal(http.StatusOK, code)
+	suite.Nil(err)
+	suite.mockJobRepo.AssertExpectations(suite.T())
+}
+
+//===============================================UPDATE========================================================
+
+func (suite *PrintJobHandlerTestSuite) TestUpdate() {
+	body := `{"printer_id":1,"started_on":"2021-03-01T10:00:00Z","ended_on":"2021-03-01T12:00:00Z","filament_used":36,"outcome":"succeeded"}`
+	suite.mockPanelRepo.On("GetById", 1).Return(&entity.PrintRequest{Id: 1, Status: entity.StatusApproved}, nil)
+	suite.mockPanelRepo.On("GetById", 2).Return(&entity.PrintRequest{Id: 2, Status: entity.StatusApproved}, nil)
+	suite.mockJobRepo.On("GetById", 3).Return(newPrintJob(3, entity.JobPrinting), nil)
+	suite.mockJobRepo.On("GetById", 4).Return(&entity.PrintJob{}, nil)
+	suite.mockJobRepo.On("Update", testifymock.MatchedBy(func(model *entity.PrintJob) bool {
+		return model.Id == 3 && model.PrintRequestId == 1 && model.Outcome == entity.JobSucceeded && model.Duration == 7200
+	})).Return(true, nil).Once()
+
+	req, _ := http.NewRequest("PUT", "/print-requests/1/jobs/3", strings.NewReader(body))
+	code, err := suite.handlerInstance.Update(httptest.NewRecorder(), req, []httprouter.Param{{Key: "id", Value: "1"}, {Key: "job", Value: "3"}})
+	suite.Equal(http.StatusOK, code)
+	suite.Nil(err)
+
+	req, _ = http.NewRequest("PUT", "/print-requests/2/jobs/3", strings.NewReader(body))
+	code, err = suite.handlerInstance.Update(httptest.NewRecorder(), req, []httprouter.Param{{Key: "id", Value: "2"}, {Key: "job", Value: "3"}})
+	suite.Equal(http.StatusNotFound, code, "job of another request")
+	suite.NotNil(err)
+
+	req, _ = http.NewRequest("PUT", "/print-requests/1/jobs/4", strings.NewReader(body))
+	code, err = suite.handlerInstance.Update(httptest.NewRecorder(), req, []httprouter.Param{{Key: "id", Value: "1"}, {Key: "job", Value: "4"}})
+	suite.Equal(http.StatusNotFound, code)
+	suite.NotNil(err)
+
+	req, _ = http.NewRequest("PUT", "/print-requests/1/jobs/a", strings.NewReader(body))
+	code, err = suite.handlerInstance.Update(httptest.NewRecorder(), req, []httprouter.Param{{Key: "id", Value: "1"}, {Key: "job", Value: "a"}})
+	suite.Equal(http.StatusBadRequest, code)
+	suite.NotNil(err)
+
+	suite.mockJobRepo.AssertExpectations(suite.T())
+}
+
+func (suite *PrintJobHandlerTestSuite) TestUpdateRepositoryError() {
+	suite.mockPanelRepo.On("GetById", 1).Return(&entity.PrintRequest{Id: 1, Status: entity.StatusApproved}, nil)
+	suite.mockJobRepo.On("GetById", 3).Return(newPrintJob(3, entity.JobPrinting), nil)
+	suite.mockJobRepo.On("Update", testifymock.Anything).Return(false, errors.New("[TEST] db down")).Once()
+
+	req, _ := http.NewRequest("PUT", "/print-requests/1/jobs/3", strings.NewReader(`{"printer_id":1,"started_on":"2021-03-01T10:00:00Z"}`))
+	code, err := suite.handlerInstance.Update(httptest.NewRecorder(), req, []httprouter.Param{{Key: "id", Value: "1"}, {Key: "job", Value: "3"}})
+
+	suite.Equal(http.StatusInternalServerError, code)
+	suite.NotNil(err)
+}
+
+func TestPrintJobHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(PrintJobHandlerTestSuite))
+}
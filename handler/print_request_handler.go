@@ -1,12 +1,27 @@
 package handler
 
 import (
+	"bytes"
 	"errors"
+	"log"
 	"net/http"
 	"strconv"
+	"threedee/entity"
+	print_job "threedee/interfaces/print-job"
 	print_request "threedee/interfaces/print-request"
+	"threedee/interfaces/printer"
+	"threedee/interfaces/project"
+	requestor_quota "threedee/interfaces/requestor-quota"
+	"threedee/storage"
+	"threedee/utility/buildvolume"
+	"threedee/utility/estimator"
+	"threedee/utility/export"
 	"threedee/utility/normalizer"
+	"threedee/utility/quota"
 	"threedee/utility/response"
+	"threedee/utility/review"
+	"threedee/utility/search"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 )
@@ -25,16 +40,59 @@ import (
  * a Service (making requests to other services i.e. using HTTP REST)
  */
 
+// What Create does when the same requestor uploads a file that is already in an active request
+const (
+	DuplicateReject = "reject"
+	DuplicateMark   = "mark"
+)
+
+// Number of results of GET /print-requests/search
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
 type RequestHandler struct {
-	Repo print_request.PrintRequestRepositoryInterface
-	Norm *normalizer.PrintRequestNormalizer
+	Repo            print_request.PrintRequestRepositoryInterface
+	Norm            *normalizer.PrintRequestNormalizer
+	Files           *storage.Files                           // optional, uploaded files are only analyzed when nil
+	DuplicatePolicy string                                   // DuplicateReject (default) or DuplicateMark
+	Printers        printer.PrinterRepositoryInterface       // optional, parts are not checked against build volumes when nil
+	Quotas          requestor_quota.QuotaRepositoryInterface // optional, quotas are not enforced when nil
+	DefaultQuota    *entity.Quota                            // for requestors without their own quota
+	Reviewers       []string                                 // assigned to new requests in turn, anyone may review when empty
+	Projects        project.ProjectRepositoryInterface       // optional, project ids are not checked when nil
 }
 
-func NewRequestHandler(repo print_request.PrintRequestRepositoryInterface, norm *normalizer.PrintRequestNormalizer) *RequestHandler {
-	return &RequestHandler{repo, norm}
+type DuplicateResponse struct {
+	DuplicateOf int    `json:"duplicate_of"`
+	Link        string `json:"link"`
+}
+
+// errDryRun rolls back the transaction of a dry run import
+var errDryRun = errors.New("dry run")
+
+// ImportResponse is the report of POST /print-requests/import, with one result per row
+type ImportResponse struct {
+	DryRun   bool           `json:"dry_run"`
+	Valid    int            `json:"valid"`
+	Invalid  int            `json:"invalid"`
+	Imported int            `json:"imported"`
+	Rows     []ImportResult `json:"rows"`
 }
 
-// handle GET /print-requests
+// ImportResult has the id of an imported row, or why the row was not imported
+type ImportResult struct {
+	Line  int    `json:"line"`
+	Id    int    `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+func NewRequestHandler(repo print_request.PrintRequestRepositoryInterface, norm *normalizer.PrintRequestNormalizer, files *storage.Files, duplicatePolicy string, printers printer.PrinterRepositoryInterface, quotas requestor_quota.QuotaRepositoryInterface, defaultQuota *entity.Quota, reviewers []string, projects project.ProjectRepositoryInterface) *RequestHandler {
+	return &RequestHandler{repo, norm, files, duplicatePolicy, printers, quotas, defaultQuota, reviewers, projects}
+}
+
+// handle GET /print-requests?requestor=&status=
 func (h *RequestHandler) Index(w http.ResponseWriter, r *http.Request, _ httprouter.Params) (int, error) {
 
 	ctx := r.Context()
@@ -44,7 +102,12 @@ func (h *RequestHandler) Index(w http.ResponseWriter, r *http.Request, _ httprou
 	default:
 	}
 
-	data, err := h.Repo.GetAll()
+	filter, err := h.Norm.ReadAndNormalizeFilter(r)
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, err)
+	}
+
+	data, err := h.Repo.GetAll(filter)
 	if len(data) == 0 {
 		return http.StatusNotFound, response.WriteNotFoundError(w, errors.New("no records found"))
 	}
@@ -81,7 +144,122 @@ func (h *RequestHandler) Show(w http.ResponseWriter, r *http.Request, p httprout
 	return http.StatusOK, response.WriteSuccess(w, data, "success")
 }
 
+// handle GET /print-requests/export?format=csv|xlsx&requestor=&status=
+//
+// Streams the requests of the list as a spreadsheet, row by row from the repository. A
+// failure before anything is sent is answered as JSON like any other error. Once the first
+// bytes are sent the status can not change anymore, so a failure halfway is only logged
+// and leaves the client with a truncated file.
+//
+// On SQLite Each keeps the only connection of the pool until the last row is written, so
+// every other request waits for the download to finish. The rows are still streamed,
+// reading them first would take the memory of the whole export.
+func (h *RequestHandler) Export(w http.ResponseWriter, r *http.Request, _ httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	filter, err := h.Norm.ReadAndNormalizeFilter(r)
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, err)
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = export.FormatCsv
+	}
+	contentType, ok := export.ContentTypes[format]
+	if !ok {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("format must be csv or xlsx"))
+	}
+
+	body := &exportBody{w: w, contentType: contentType, filename: "print-requests." + format}
+	out, err := export.NewWriter(format, body)
+	if err == nil {
+		err = h.Repo.Each(filter, func(item *entity.PrintRequest) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+			return out.Write(item)
+		})
+	}
+	if err == nil {
+		err = out.Close()
+	}
+	if err != nil && !body.written {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+// exportBody sets the download headers of an export when its first bytes are written, so
+// the response can still be a JSON error until then
+type exportBody struct {
+	w           http.ResponseWriter
+	contentType string
+	filename    string
+	written     bool
+}
+
+func (b *exportBody) Write(p []byte) (int, error) {
+	if !b.written {
+		b.w.Header().Set("Content-Type", b.contentType)
+		b.w.Header().Set("Content-Disposition", `attachment; filename="`+b.filename+`"`)
+		b.written = true
+	}
+	return b.w.Write(p)
+}
+
+// handle GET /print-requests/search?q=&limit=
+//
+// Every word of q is matched as a prefix of a word in the item name, requestor or notes.
+func (h *RequestHandler) Search(w http.ResponseWriter, r *http.Request, _ httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	query := r.URL.Query()
+	q := query.Get("q")
+	if len(search.Terms(q)) == 0 {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("q must contain at least one word"))
+	}
+
+	limit := DefaultSearchLimit
+	if v := query.Get("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l < 1 || l > MaxSearchLimit {
+			return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("limit must be a number from 1 to "+strconv.Itoa(MaxSearchLimit)))
+		}
+		limit = l
+	}
+
+	data, err := h.Repo.Search(q, limit)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+	return http.StatusOK, response.WriteSuccess(w, data, "success")
+}
+
 // handle POST /print-requests
+//
+// Accepts either a JSON body or a multipart/form-data body with the print file in "file".
+// Uploaded G-code or 3MF files fill the estimates, see utility/estimator. Uploading a file
+// the requestor already has in an active request is handled according to DuplicatePolicy.
+// Estimates and the estimated cost cover all copies of the request. The request waits for
+// review by the least busy of Reviewers.
 func (h *RequestHandler) Create(w http.ResponseWriter, r *http.Request, p httprouter.Params) (int, error) {
 
 	ctx := r.Context()
@@ -91,17 +269,90 @@ func (h *RequestHandler) Create(w http.ResponseWriter, r *http.Request, p httpro
 	default:
 	}
 
-	model, err := h.Norm.ReadAndNormalize(w, r)
+	var model *entity.PrintRequest
+	var upload *normalizer.Upload
+	var err error
+	if h.Norm.IsMultipart(r) {
+		model, upload, err = h.Norm.ReadAndNormalizeUpload(w, r)
+		if err != nil {
+			return http.StatusBadRequest, response.WriteBadRequestError(w, err)
+		}
+
+		// the file knows better than the requestor, so computed estimates win
+		estimate, err := estimator.FromFile(upload.Filename, upload.Data)
+		if err != nil {
+			return http.StatusBadRequest, response.WriteBadRequestError(w, err)
+		}
+		estimate.Apply(model)
+		model.FileHash = storage.Hash(upload.Data)
+
+		// only model files know the part size, sliced G-code was already made for a printer
+		if estimate.Width > 0 && h.Printers != nil {
+			printers, err := h.Printers.GetAll()
+			if err != nil {
+				return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+			}
+			err = buildvolume.Check(estimate.Width, estimate.Depth, estimate.Height, printers)
+			if err != nil {
+				return http.StatusBadRequest, response.WriteBadRequestError(w, err)
+			}
+		}
+	} else {
+		model, err = h.Norm.ReadAndNormalize(w, r)
+		if err != nil {
+			return http.StatusBadRequest, response.WriteBadRequestError(w, err)
+		}
+	}
+
+	err = h.prepare(model)
+	var duplicate *duplicateError
+	if errors.As(err, &duplicate) {
+		return http.StatusConflict, response.WriteConflictError(w, DuplicateResponse{
+			DuplicateOf: duplicate.Id,
+			Link:        "/print-requests/" + strconv.Itoa(duplicate.Id),
+		}, err)
+	}
 	if err != nil {
-		return http.StatusBadRequest, response.WriteBadRequestError(w, err)
+		return writeProjectError(w, err)
+	}
+
+	// the file is downloaded through GET /print-requests/:id/file, which signs the link, so
+	// file_url stays whatever link the requestor sent
+	var file *entity.StoredFile
+	if upload != nil && h.Files != nil {
+		file, err = h.Files.Save(upload.Filename, bytes.NewReader(upload.Data))
+		if err == storage.ErrFileTooLarge {
+			return http.StatusRequestEntityTooLarge, response.WriteRequestEntityTooLargeError(w, err)
+		}
+		if err != nil {
+			return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+		}
+		model.FileKey = file.Key
 	}
 
-	id, err := h.Repo.Insert(model)
+	limit, err := h.quotaLimit(model.Requestor)
 	if err != nil {
 		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
 	}
 
-	model, err = h.Repo.GetById(id)
+	err = h.Repo.WithTransaction(func(repo print_request.PrintRequestRepositoryInterface) error {
+		id, err := h.insert(repo, model, limit)
+		if err != nil {
+			return err
+		}
+		model, err = repo.GetById(id)
+		return err
+	})
+	if err != nil && file != nil {
+		discardErr := h.Files.Discard(file)
+		if discardErr != nil {
+			log.Println("failed to delete the file " + file.Key + " of a request that was not created: " + discardErr.Error())
+		}
+	}
+	var exceeded *quota.ExceededError
+	if errors.As(err, &exceeded) {
+		return http.StatusTooManyRequests, response.WriteTooManyRequestsError(w, exceeded.RetryAfter, err)
+	}
 	if err != nil {
 		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
 	}
@@ -112,7 +363,234 @@ func (h *RequestHandler) Create(w http.ResponseWriter, r *http.Request, p httpro
 	return http.StatusOK, response.WriteSuccess(w, model, "success")
 }
 
+// duplicateError is returned by prepare when DuplicatePolicy rejects a file that is already
+// in the active request Id of the same requestor
+type duplicateError struct {
+	Id int
+}
+
+func (e *duplicateError) Error() string {
+	return "an active request for the same file already exists"
+}
+
+// prepare computes the cost of a new model and checks it, whether it is created or imported:
+// its project has to be active and its file is handled according to DuplicatePolicy. The
+// quota is checked by insert.
+func (h *RequestHandler) prepare(model *entity.PrintRequest) error {
+	model.EstimatedCost = estimator.Cost(model)
+
+	err := h.checkProject(model)
+	if err != nil {
+		return err
+	}
+
+	if model.FileHash == "" {
+		return nil
+	}
+	duplicates, err := h.Repo.GetActiveByFileHash(model.FileHash, model.Requestor)
+	if err != nil {
+		return err
+	}
+	if len(duplicates) > 0 {
+		if h.DuplicatePolicy != DuplicateMark {
+			return &duplicateError{Id: duplicates[0].Id}
+		}
+		model.DuplicateOf = duplicates[0].Id
+	}
+	return nil
+}
+
+// insert checks model against limit, the quota of its requestor, and assigns the reviewer
+// of the new model, then inserts it. repo has to be in a transaction for the quota to hold,
+// see GetQuotaUsage.
+func (h *RequestHandler) insert(repo print_request.PrintRequestRepositoryInterface, model *entity.PrintRequest, limit *entity.Quota) (int, error) {
+	err := checkQuota(repo, model, limit)
+	if err != nil {
+		return 0, err
+	}
+	err = h.assignReviewer(repo, model)
+	if err != nil {
+		return 0, err
+	}
+	return repo.Insert(model)
+}
+
+// quotaLimit returns the quota of requestor, or DefaultQuota when they have none, and nil
+// when quotas are not enforced. It reads outside of the insert transaction, which on SQLite
+// holds the only connection.
+func (h *RequestHandler) quotaLimit(requestor string) (*entity.Quota, error) {
+	if h.Quotas == nil {
+		return nil, nil
+	}
+	limit, err := h.Quotas.GetByRequestor(requestor)
+	if err != nil {
+		return nil, err
+	}
+	if limit.Requestor == "" {
+		return h.DefaultQuota, nil
+	}
+	return limit, nil
+}
+
+// checkQuota returns a *quota.ExceededError when model does not fit into limit, a nil limit
+// is unlimited
+func checkQuota(repo print_request.PrintRequestRepositoryInterface, model *entity.PrintRequest, limit *entity.Quota) error {
+	if limit == nil {
+		return nil
+	}
+
+	now := time.Now()
+	usage, err := repo.GetQuotaUsage(model.Requestor, quota.MonthStart(now), quota.WeekStart(now))
+	if err != nil {
+		return err
+	}
+	return quota.Check(limit, usage, model, now)
+}
+
+// checkProject returns a *project.NotFoundError when model is part of a project that is not
+// active
+func (h *RequestHandler) checkProject(model *entity.PrintRequest) error {
+	if h.Projects == nil || model.ProjectId == 0 {
+		return nil
+	}
+	data, err := h.Projects.GetById(model.ProjectId)
+	if err != nil {
+		return err
+	}
+	if data == nil || data.Id == 0 {
+		return &project.NotFoundError{Id: model.ProjectId}
+	}
+	return nil
+}
+
+// writeProjectError answers 400 Bad Request for a missing project of a print request
+func writeProjectError(w http.ResponseWriter, err error) (int, error) {
+	var notFound *project.NotFoundError
+	if errors.As(err, &notFound) {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, err)
+	}
+	return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+}
+
+// assignReviewer sets the reviewer of the new model, overriding whatever the client sent
+func (h *RequestHandler) assignReviewer(repo print_request.PrintRequestRepositoryInterface, model *entity.PrintRequest) error {
+	model.Reviewer = ""
+	if len(h.Reviewers) == 0 {
+		return nil
+	}
+	pending, err := repo.GetAll(&entity.PrintRequestFilter{Status: entity.StatusPendingReview})
+	if err != nil {
+		return err
+	}
+	model.Reviewer = review.Assign(h.Reviewers, model.Requestor, pending)
+	return nil
+}
+
+// handle POST /print-requests/import?format=csv|jsonl&dry_run=true
+//
+// Creates the valid rows of a CSV or JSON Lines body in one transaction and skips the
+// invalid ones, see PrintRequestNormalizer.ReadAndNormalizeImport. Rows are checked like
+// Create checks a request, so those in a missing project or over the quota of their
+// requestor are skipped as well. With dry_run the transaction is rolled back, so nothing
+// is created and the report only tells which rows would be.
+func (h *RequestHandler) Import(w http.ResponseWriter, r *http.Request, _ httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	dryRun, err := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+	if err != nil && r.URL.Query().Get("dry_run") != "" {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("dry_run must be true or false"))
+	}
+
+	rows, err := h.Norm.ReadAndNormalizeImport(w, r)
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, err)
+	}
+	if len(rows) == 0 {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("no rows to import"))
+	}
+
+	valid := 0
+	limits := make(map[string]*entity.Quota)
+	for _, row := range rows {
+		if row.Err != nil {
+			continue
+		}
+		err = h.prepare(row.Model)
+		var notFound *project.NotFoundError
+		var duplicate *duplicateError
+		if errors.As(err, &notFound) || errors.As(err, &duplicate) {
+			row.Model, row.Err = nil, err
+			continue
+		}
+		if err != nil {
+			return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+		}
+		valid++
+
+		if _, ok := limits[row.Model.Requestor]; !ok {
+			limits[row.Model.Requestor], err = h.quotaLimit(row.Model.Requestor)
+			if err != nil {
+				return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+			}
+		}
+	}
+
+	ids := make([]int, len(rows))
+	if valid > 0 {
+		err = h.Repo.WithTransaction(func(repo print_request.PrintRequestRepositoryInterface) error {
+			for i, row := range rows {
+				if row.Model == nil {
+					continue
+				}
+				id, err := h.insert(repo, row.Model, limits[row.Model.Requestor])
+				var exceeded *quota.ExceededError
+				if errors.As(err, &exceeded) {
+					row.Model, row.Err = nil, err
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				ids[i] = id
+			}
+			if dryRun {
+				return errDryRun
+			}
+			return nil
+		})
+		if err != nil && err != errDryRun {
+			return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+		}
+	}
+
+	report := &ImportResponse{DryRun: dryRun, Rows: make([]ImportResult, len(rows))}
+	for i, row := range rows {
+		report.Rows[i].Line = row.Line
+		if row.Err != nil {
+			report.Rows[i].Error = row.Err.Error()
+			report.Invalid++
+			continue
+		}
+		report.Valid++
+		if !dryRun {
+			report.Rows[i].Id = ids[i]
+			report.Imported++
+		}
+	}
+
+	return http.StatusOK, response.WriteSuccess(w, report, "success")
+}
+
 // handle PUT /print-requests/:id
+//
+// Only requests that are still in review, or received from before the review step, can be
+// edited. Once a request is decided on it is printed as it was.
 func (h *RequestHandler) Update(w http.ResponseWriter, r *http.Request, p httprouter.Params) (int, error) {
 
 	ctx := r.Context()
@@ -132,24 +610,87 @@ func (h *RequestHandler) Update(w http.ResponseWriter, r *http.Request, p httpro
 		return http.StatusBadRequest, response.WriteBadRequestError(w, err)
 	}
 
-	data, err := h.Repo.GetById(id)
+	// the projects are read outside of the transaction, which on SQLite holds the only
+	// connection and in memory the lock of the requests a project sums up
+	err = h.checkProject(model)
 	if err != nil {
-		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+		return writeProjectError(w, err)
 	}
-	if data == nil || data.Id == 0 {
-		return http.StatusNotFound, response.WriteNotFoundError(w, errors.New("record not found"))
+
+	err = h.Repo.WithTransaction(func(repo print_request.PrintRequestRepositoryInterface) error {
+		data, err := repo.GetById(id)
+		if err != nil {
+			return err
+		}
+		if data == nil || data.Id == 0 {
+			return &print_request.NotFoundError{Id: id}
+		}
+		if !entity.IsInReview(data.Status) && data.Status != entity.StatusReceived {
+			return &editError{data.Status}
+		}
+
+		// the stored file can only be replaced by uploading a new request, and the status and
+		// reviewer only change through ChangeStatus and the review
+		model.Id = id
+		model.FileKey = data.FileKey
+		model.FileHash = data.FileHash
+		model.DuplicateOf = data.DuplicateOf
+		model.Status = data.Status
+		model.Reviewer = data.Reviewer
+		model.EstimatedCost = estimator.Cost(model)
+		_, err = repo.Update(model)
+		return err
+	})
+	var edit *editError
+	if errors.As(err, &edit) {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, err)
+	}
+	if err != nil {
+		return writeRepositoryError(w, err)
+	}
+
+	return http.StatusOK, response.WriteSuccess(w, model, "success")
+}
+
+// editError is returned by Update for a request whose status does not allow edits
+type editError struct {
+	status string
+}
+
+func (e *editError) Error() string {
+	return "you can not edit a request that is already " + e.status
+}
+
+// handle GET /print-requests/:id/file
+//
+// Redirects to a short-lived signed download URL of the uploaded print file.
+func (h *RequestHandler) File(w http.ResponseWriter, r *http.Request, p httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
 	}
-	if data.Status == "processed" || data.Status == "finished" {
-		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("you can not edit a request that is already processed"))
+
+	id, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("id is not a number"))
 	}
 
-	model.Id = id
-	_, err = h.Repo.Update(model)
+	data, err := h.Repo.GetById(id)
 	if err != nil {
 		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
 	}
+	if data == nil || data.Id == 0 {
+		return http.StatusNotFound, response.WriteNotFoundError(w, errors.New("record not found"))
+	}
+	if h.Files == nil || data.FileKey == "" {
+		return http.StatusNotFound, response.WriteNotFoundError(w, errors.New("request has no uploaded file"))
+	}
 
-	return http.StatusOK, response.WriteSuccess(w, model, "success")
+	http.Redirect(w, r, h.Files.SignedUrl(data.FileKey, time.Now()), http.StatusTemporaryRedirect)
+	return http.StatusTemporaryRedirect, nil
 }
 
 // handle DELETE /print-requests/:id
@@ -169,13 +710,18 @@ func (h *RequestHandler) Delete(w http.ResponseWriter, r *http.Request, p httpro
 
 	_, err = h.Repo.Delete(id)
 	if err != nil {
-		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+		return writeRepositoryError(w, err)
 	}
 
 	return http.StatusOK, response.WriteSuccess(w, nil, "success")
 }
 
 // handle PUT /print-requests/:id/status
+//
+// Sets any status, unlike BatchStatus it does not check entity.StatusTransitions. Only the
+// review moves a request into or out of entity.InReviewStatuses, so that is a 409 Conflict.
+// Requestors are emailed when their request becomes approved, rejected, finished or failed,
+// see outbox.EmailSink.
 func (h *RequestHandler) ChangeStatus(w http.ResponseWriter, r *http.Request, p httprouter.Params) (int, error) {
 
 	ctx := r.Context()
@@ -190,24 +736,129 @@ func (h *RequestHandler) ChangeStatus(w http.ResponseWriter, r *http.Request, p
 		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("id is not a number"))
 	}
 
-	model, err := h.Norm.ReadAndNormalize(w, r)
+	model, err := h.Norm.ReadAndNormalizeStatus(w, r)
 	if err != nil {
 		return http.StatusBadRequest, response.WriteBadRequestError(w, err)
 	}
 
-	data, err := h.Repo.GetById(id)
+	var data *entity.PrintRequest
+	err = h.Repo.WithTransaction(func(repo print_request.PrintRequestRepositoryInterface) error {
+		data, err = repo.GetById(id)
+		if err != nil || data == nil || data.Id == 0 {
+			return err
+		}
+		if data.Status != model.Status && (entity.IsInReview(data.Status) || entity.IsInReview(model.Status)) {
+			return &statusTransitionError{data.Status, model.Status}
+		}
+		data.Status = model.Status
+		_, err = repo.Update(data)
+		return err
+	})
+	var transition *statusTransitionError
+	if errors.As(err, &transition) {
+		return http.StatusConflict, response.WriteConflictError(w, nil, err)
+	}
 	if err != nil {
-		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+		return writeRepositoryError(w, err)
 	}
 	if data == nil || data.Id == 0 {
 		return http.StatusNotFound, response.WriteNotFoundError(w, errors.New("record not found"))
 	}
 
-	data.Status = model.Status
-	_, err = h.Repo.Update(data)
+	return http.StatusOK, response.WriteSuccess(w, data, "success")
+}
+
+// handle POST /print-requests/batch/status
+//
+// Moves every request of ids to status in one transaction. Every transition is checked
+// first: when atomic (the default) and any request is missing or can not move to the
+// status, nothing changes and the answer is 409 Conflict. Otherwise the requests that can
+// move do. Either way there is a result per id.
+func (h *RequestHandler) BatchStatus(w http.ResponseWriter, r *http.Request, _ httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	batch, err := h.Norm.ReadAndNormalizeBatchStatus(w, r)
 	if err != nil {
-		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+		return http.StatusBadRequest, response.WriteBadRequestError(w, err)
 	}
 
-	return http.StatusOK, response.WriteSuccess(w, data, "success")
+	var results []*entity.BatchStatusResult
+	failed := 0
+	err = h.Repo.WithTransaction(func(repo print_request.PrintRequestRepositoryInterface) error {
+		results = make([]*entity.BatchStatusResult, 0, len(batch.Ids))
+		failed = 0
+		models := make([]*entity.PrintRequest, 0, len(batch.Ids))
+		for _, id := range batch.Ids {
+			result := &entity.BatchStatusResult{Id: id}
+			results = append(results, result)
+
+			model, err := repo.GetById(id)
+			if err != nil {
+				return err
+			}
+			if model == nil || model.Id == 0 {
+				result.Error = (&print_request.NotFoundError{Id: id}).Error()
+				failed++
+				continue
+			}
+			result.PreviousStatus = model.Status
+			if !entity.CanChangeStatus(model.Status, batch.Status) {
+				result.Error = (&statusTransitionError{model.Status, batch.Status}).Error()
+				failed++
+				continue
+			}
+			models = append(models, model)
+		}
+		if failed > 0 && *batch.Atomic {
+			return nil
+		}
+
+		for _, model := range models {
+			model.Status = batch.Status
+			_, err := repo.Update(model)
+			if err != nil {
+				return err
+			}
+		}
+		for _, result := range results {
+			result.Changed = result.Error == ""
+		}
+		return nil
+	})
+	if err != nil {
+		return writeRepositoryError(w, err)
+	}
+
+	if failed > 0 && *batch.Atomic {
+		return http.StatusConflict, response.WriteConflictError(w, results, errors.New(strconv.Itoa(failed)+" of "+strconv.Itoa(len(results))+" requests can not change status, none was changed"))
+	}
+	return http.StatusOK, response.WriteSuccess(w, results, "success")
+}
+
+// statusTransitionError is returned when a request can not move to a status, see
+// entity.StatusTransitions
+type statusTransitionError struct {
+	from string
+	to   string
+}
+
+func (e *statusTransitionError) Error() string {
+	return "status can not change from " + e.from + " to " + e.to
+}
+
+// writeRepositoryError answers 404 when the request is gone and 500 for anything else
+func writeRepositoryError(w http.ResponseWriter, err error) (int, error) {
+	var notFound *print_request.NotFoundError
+	var projectNotFound *project.NotFoundError
+	var jobNotFound *print_job.NotFoundError
+	if errors.As(err, &notFound) || errors.As(err, &projectNotFound) || errors.As(err, &jobNotFound) {
+		return http.StatusNotFound, response.WriteNotFoundError(w, err)
+	}
+	return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
 }
@@ -1,20 +1,27 @@
 package handler_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"threedee/entity"
 	"threedee/handler"
+	file_storage "threedee/interfaces/file-storage"
+	print_request "threedee/interfaces/print-request"
+	"threedee/storage"
 	"threedee/testdata/mock"
 	"threedee/utility/normalizer"
 	"time"
 
 	"github.com/julienschmidt/httprouter"
+	testifymock "github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"github.com/subosito/gotenv"
 )
@@ -39,13 +46,15 @@ var _ = gotenv.Load("../.env")
 type PrintRequestHandlerTestSuite struct {
 	suite.Suite
 	mockPanelRepo   *mock.MockPrintRequestRepository
+	mockPrinterRepo *mock.MockPrinterRepository
 	handlerInstance handler.RequestHandler
 }
 
 // 2
 func (suite *PrintRequestHandlerTestSuite) SetupTest() {
 	suite.mockPanelRepo = &mock.MockPrintRequestRepository{}
-	suite.handlerInstance = handler.RequestHandler{Repo: suite.mockPanelRepo, Norm: &normalizer.PrintRequestNormalizer{}}
+	suite.mockPrinterRepo = &mock.MockPrinterRepository{}
+	suite.handlerInstance = handler.RequestHandler{Repo: suite.mockPanelRepo, Norm: &normalizer.PrintRequestNormalizer{}, Printers: suite.mockPrinterRepo}
 }
 
 //===============================================INDEX========================================================
@@ -85,7 +94,7 @@ func (suite *PrintRequestHandlerTestSuite) TestIndex() {
 		req, _ := http.NewRequest("GET", "/print-requests", nil)
 		req.Header.Add("Content-Type", "application/json")
 		responseRecorder := httptest.NewRecorder()
-		suite.mockPanelRepo.On("GetAll").Return(tc.getAllResult, tc.getAllError).Times(1)
+		suite.mockPanelRepo.On("GetAll", &entity.PrintRequestFilter{}).Return(tc.getAllResult, tc.getAllError).Times(1)
 
 		var err error
 		if tc.isTimeout {
@@ -104,6 +113,28 @@ func (suite *PrintRequestHandlerTestSuite) TestIndex() {
 	}
 }
 
+func (suite *PrintRequestHandlerTestSuite) TestIndexFilter() {
+	supports := true
+	suite.mockPanelRepo.On("GetAll", &entity.PrintRequestFilter{Material: entity.MaterialPetg, Color: "red", Supports: &supports}).Return([]*entity.PrintRequest{entity.NewPrintRequest()}, nil).Once()
+
+	req, _ := http.NewRequest("GET", "/print-requests?material=PETG&color=red&supports=true", nil)
+	code, err := suite.handlerInstance.Index(httptest.NewRecorder(), req, nil)
+	suite.Equal(http.StatusOK, code)
+	suite.Nil(err)
+
+	req, _ = http.NewRequest("GET", "/print-requests?material=wood", nil)
+	code, err = suite.handlerInstance.Index(httptest.NewRecorder(), req, nil)
+	suite.Equal(http.StatusBadRequest, code)
+	suite.NotNil(err)
+
+	req, _ = http.NewRequest("GET", "/print-requests?supports=maybe", nil)
+	code, err = suite.handlerInstance.Index(httptest.NewRecorder(), req, nil)
+	suite.Equal(http.StatusBadRequest, code)
+	suite.NotNil(err)
+
+	suite.mockPanelRepo.AssertExpectations(suite.T())
+}
+
 //===============================================SHOW========================================================
 
 func (suite *PrintRequestHandlerTestSuite) TestShow() {
@@ -180,10 +211,29 @@ func (suite *PrintRequestHandlerTestSuite) TestCreate() {
 		EstimatedFilamentLength: 5000,
 		EstimatedDuration:       9000,
 		FileUrl:                 "http://drive.google.com/filez/100",
+		FileKey:                 "2021/03/cup.gcode",
+		FileHash:                "abc",
+		DuplicateOf:             3,
 		Requestor:               "Karim Hartono",
 	}
 	reqBodyBytes, _ := json.Marshal(model)
 
+	// settings that are left out get their defaults, and the fields of an uploaded file are
+	// not taken from a JSON body
+	expectedModel := entity.PrintRequest{
+		ItemName:                "Bertaburan Bunga v2",
+		EstimatedWeight:         37.5,
+		EstimatedFilamentLength: 5000,
+		EstimatedDuration:       9000,
+		FileUrl:                 "http://drive.google.com/filez/100",
+		Requestor:               "Karim Hartono",
+		Material:                entity.DefaultMaterial,
+		LayerHeight:             entity.DefaultLayerHeight,
+		NozzleSize:              entity.DefaultNozzleSize,
+		Quantity:                1,
+		EstimatedCost:           0.75,
+	}
+
 	var testCase = []struct {
 		testcase     string
 		reqBody      []byte
@@ -222,10 +272,11 @@ func (suite *PrintRequestHandlerTestSuite) TestCreate() {
 		},
 	}
 	for _, tc := range testCase {
+		suite.SetupTest()
 		req, _ := http.NewRequest("POST", "/print-requests", strings.NewReader(string(tc.reqBody)))
 		req.Header.Add("Content-Type", "application/json")
 		responseRecorder := httptest.NewRecorder()
-		suite.mockPanelRepo.On("Insert", &model).Return(tc.createResult, tc.createError).Times(1)
+		suite.mockPanelRepo.On("Insert", &expectedModel).Return(tc.createResult, tc.createError).Times(1)
 		suite.mockPanelRepo.On("GetById", tc.createResult).Return(tc.showResult, nil).Times(1)
 
 		var err error
@@ -238,9 +289,392 @@ func (suite *PrintRequestHandlerTestSuite) TestCreate() {
 		}
 
 		if tc.isError {
-			suite.NotNil(err)
+			suite.NotNil(err, tc.testcase)
 		} else {
-			suite.Nil(err)
+			suite.Nil(err, tc.testcase)
+		}
+		if !tc.isTimeout {
+			// a failed insert rolls back, so no outbox event is left behind
+			suite.Equal(!tc.isError, suite.mockPanelRepo.Commits == 1, tc.testcase)
+			suite.Equal(tc.isError, suite.mockPanelRepo.Rollbacks == 1, tc.testcase)
+		}
+	}
+}
+
+func (suite *PrintRequestHandlerTestSuite) TestCreateQuota() {
+	reqBody := `{"item_name":"Drone Frame","estimated_weight":300,"estimated_duration":36000,"requestor":"andi"}`
+
+	var testCase = []struct {
+		testcase   string
+		stored     *entity.Quota
+		usage      *entity.QuotaUsage
+		isError    bool
+		code       int
+		retryAfter bool
+	}{
+		{
+			testcase: "within the default quota",
+			stored:   entity.NewQuota(),
+			usage:    &entity.QuotaUsage{Active: 1, GramsThisMonth: 100},
+			code:     http.StatusOK,
+		},
+		{
+			testcase: "over the default quota of active requests",
+			stored:   entity.NewQuota(),
+			usage:    &entity.QuotaUsage{Active: 3},
+			isError:  true,
+			code:     http.StatusTooManyRequests,
+		},
+		{
+			testcase:   "over the own quota of grams",
+			stored:     &entity.Quota{Requestor: "andi", MaxGramsPerMonth: 350},
+			usage:      &entity.QuotaUsage{Active: 10, GramsThisMonth: 100},
+			isError:    true,
+			code:       http.StatusTooManyRequests,
+			retryAfter: true,
+		},
+		{
+			testcase: "own quota overrides the default",
+			stored:   &entity.Quota{Requestor: "andi", MaxActive: 20},
+			usage:    &entity.QuotaUsage{Active: 10, GramsThisMonth: 1000},
+			code:     http.StatusOK,
+		},
+	}
+	for _, tc := range testCase {
+		suite.SetupTest()
+		quotas := &mock.MockQuotaRepository{}
+		suite.handlerInstance.Quotas = quotas
+		suite.handlerInstance.DefaultQuota = &entity.Quota{MaxActive: 3, MaxGramsPerMonth: 500}
+		quotas.On("GetByRequestor", "andi").Return(tc.stored, nil).Once()
+		suite.mockPanelRepo.On("GetQuotaUsage", "andi", testifymock.Anything, testifymock.Anything).Return(tc.usage, nil).Once()
+		if !tc.isError {
+			suite.mockPanelRepo.On("Insert", testifymock.Anything).Return(1, nil).Once()
+			suite.mockPanelRepo.On("GetById", 1).Return(&entity.PrintRequest{Id: 1}, nil).Once()
+		}
+		req, _ := http.NewRequest("POST", "/print-requests", strings.NewReader(reqBody))
+		responseRecorder := httptest.NewRecorder()
+
+		code, err := suite.handlerInstance.Create(responseRecorder, req, nil)
+
+		suite.Equal(tc.code, code, tc.testcase)
+		if tc.isError {
+			suite.NotNil(err, tc.testcase)
+		} else {
+			suite.Nil(err, tc.testcase)
+		}
+		suite.Equal(tc.retryAfter, responseRecorder.Header().Get("Retry-After") != "", tc.testcase)
+		if tc.isError {
+			suite.Equal(1, suite.mockPanelRepo.Rollbacks, "the quota is checked in the insert transaction")
+		}
+		quotas.AssertExpectations(suite.T())
+		suite.mockPanelRepo.AssertExpectations(suite.T())
+	}
+}
+
+func (suite *PrintRequestHandlerTestSuite) TestCreateSettings() {
+	var testCase = []struct {
+		testcase string
+		reqBody  string
+		isError  bool
+		cost     float32
+	}{
+		{
+			testcase: "cost of all copies",
+			reqBody:  `{"item_name":"Gear","estimated_weight":50,"requestor":"andi","material":"PETG","color":" Red ","infill":0,"quantity":4}`,
+			cost:     1.1,
+		},
+		{
+			testcase: "unknown material",
+			reqBody:  `{"item_name":"Gear","requestor":"andi","material":"wood"}`,
+			isError:  true,
+		},
+		{
+			testcase: "layers thicker than the nozzle allows",
+			reqBody:  `{"item_name":"Gear","requestor":"andi","layer_height":0.4,"nozzle_size":0.4}`,
+			isError:  true,
+		},
+		{
+			testcase: "infill over 100",
+			reqBody:  `{"item_name":"Gear","requestor":"andi","infill":120}`,
+			isError:  true,
+		},
+		{
+			testcase: "too many copies",
+			reqBody:  `{"item_name":"Gear","requestor":"andi","quantity":101}`,
+			isError:  true,
+		},
+	}
+	for _, tc := range testCase {
+		suite.SetupTest()
+		if !tc.isError {
+			suite.mockPanelRepo.On("Insert", testifymock.MatchedBy(func(model *entity.PrintRequest) bool {
+				return model.Material == entity.MaterialPetg && model.Color == "Red" && model.Infill == 0 &&
+					model.LayerHeight == entity.DefaultLayerHeight && model.Quantity == 4 && model.EstimatedCost == tc.cost
+			})).Return(1, nil).Once()
+			suite.mockPanelRepo.On("GetById", 1).Return(&entity.PrintRequest{Id: 1}, nil).Once()
+		}
+		req, _ := http.NewRequest("POST", "/print-requests", strings.NewReader(tc.reqBody))
+		req.Header.Add("Content-Type", "application/json")
+
+		code, err := suite.handlerInstance.Create(httptest.NewRecorder(), req, nil)
+
+		if tc.isError {
+			suite.Equal(http.StatusBadRequest, code, tc.testcase)
+			suite.NotNil(err, tc.testcase)
+		} else {
+			suite.Equal(http.StatusOK, code, tc.testcase)
+			suite.Nil(err, tc.testcase)
+		}
+		suite.mockPanelRepo.AssertExpectations(suite.T())
+	}
+}
+
+func (suite *PrintRequestHandlerTestSuite) TestCreateInProject() {
+	projects := &mock.MockProjectRepository{}
+	suite.handlerInstance.Projects = projects
+	projects.On("GetById", 1).Return(&entity.Project{Id: 1}, nil)
+	projects.On("GetById", 2).Return(entity.NewProject(), nil)
+	suite.mockPanelRepo.On("Insert", testifymock.MatchedBy(func(model *entity.PrintRequest) bool {
+		return model.ProjectId == 1
+	})).Return(1, nil).Once()
+	suite.mockPanelRepo.On("GetById", 1).Return(&entity.PrintRequest{Id: 1, ProjectId: 1}, nil).Once()
+
+	req, _ := http.NewRequest("POST", "/print-requests", strings.NewReader(`{"item_name":"Arm","requestor":"andi","project_id":1}`))
+	code, err := suite.handlerInstance.Create(httptest.NewRecorder(), req, nil)
+	suite.Equal(http.StatusOK, code)
+	suite.Nil(err)
+
+	req, _ = http.NewRequest("POST", "/print-requests", strings.NewReader(`{"item_name":"Arm","requestor":"andi","project_id":2}`))
+	code, err = suite.handlerInstance.Create(httptest.NewRecorder(), req, nil)
+	suite.Equal(http.StatusBadRequest, code)
+	suite.NotNil(err)
+
+	suite.mockPanelRepo.AssertExpectations(suite.T())
+}
+
+func (suite *PrintRequestHandlerTestSuite) TestCreateWithUpload() {
+	expectedModel := entity.PrintRequest{
+		ItemName:                "Bertaburan Bunga v2",
+		EstimatedWeight:         7.01,
+		EstimatedFilamentLength: 234.57,
+		EstimatedDuration:       3723,
+		FileKey:                 "26e71eeeddedba738bf8ac3b780d961b0efa03478991286dd1b34160752b534c.gcode",
+		FileHash:                "26e71eeeddedba738bf8ac3b780d961b0efa03478991286dd1b34160752b534c",
+		Requestor:               "Karim Hartono",
+		Material:                entity.DefaultMaterial,
+		LayerHeight:             entity.DefaultLayerHeight,
+		Infill:                  entity.DefaultInfill,
+		NozzleSize:              entity.DefaultNozzleSize,
+		Quantity:                1,
+		EstimatedCost:           0.14,
+	}
+
+	local, _ := storage.NewLocalStorage(suite.T().TempDir())
+	suite.handlerInstance.Files = storage.NewFiles(local, 1<<20, "secret", time.Minute)
+
+	gcodeFile := "G1 X10 Y10 E1\n" +
+		"; filament used [mm] = 2345.67\n" +
+		"; filament used [g] = 7.01\n" +
+		"; estimated printing time (normal mode) = 1h 2m 3s\n"
+
+	var testCase = []struct {
+		testcase string
+		filename string
+		content  string
+		isError  bool
+	}{
+		{
+			testcase: "success",
+			filename: "bunga.gcode",
+			content:  gcodeFile,
+			isError:  false,
+		},
+		{
+			testcase: "unsupported file type",
+			filename: "bunga.txt",
+			content:  gcodeFile,
+			isError:  true,
+		},
+		{
+			testcase: "gcode without extrusion",
+			filename: "bunga.gcode",
+			content:  "G28\n",
+			isError:  true,
+		},
+	}
+	for _, tc := range testCase {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		writer.WriteField("item_name", "Bertaburan Bunga v2")
+		writer.WriteField("requestor", "Karim Hartono")
+		writer.WriteField("estimated_weight", "1")
+		part, _ := writer.CreateFormFile("file", tc.filename)
+		part.Write([]byte(tc.content))
+		writer.Close()
+
+		req, _ := http.NewRequest("POST", "/print-requests", body)
+		req.Header.Add("Content-Type", writer.FormDataContentType())
+		responseRecorder := httptest.NewRecorder()
+		suite.mockPanelRepo.On("GetActiveByFileHash", expectedModel.FileHash, "Karim Hartono").Return([]*entity.PrintRequest{}, nil).Times(1)
+		suite.mockPanelRepo.On("Insert", &expectedModel).Return(1, nil).Times(1)
+		suite.mockPanelRepo.On("GetById", 1).Return(&entity.PrintRequest{Id: 1}, nil).Times(1)
+
+		_, err := suite.handlerInstance.Create(responseRecorder, req, nil)
+
+		if tc.isError {
+			suite.NotNil(err, tc.testcase)
+		} else {
+			suite.Nil(err, tc.testcase)
+		}
+	}
+}
+
+func (suite *PrintRequestHandlerTestSuite) TestCreateWithUploadInsertFails() {
+	local, _ := storage.NewLocalStorage(suite.T().TempDir())
+	files := storage.NewFiles(local, 1<<20, "secret", time.Minute)
+	suite.handlerInstance.Files = files
+	gcodeFile := "G1 X10 Y10 E1\n; filament used [mm] = 1000\n; estimated printing time (normal mode) = 1h\n"
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("item_name", "Bertaburan Bunga v2")
+	writer.WriteField("requestor", "Karim Hartono")
+	part, _ := writer.CreateFormFile("file", "bunga.gcode")
+	part.Write([]byte(gcodeFile))
+	writer.Close()
+	req, _ := http.NewRequest("POST", "/print-requests", body)
+	req.Header.Add("Content-Type", writer.FormDataContentType())
+	suite.mockPanelRepo.On("GetActiveByFileHash", storage.Hash([]byte(gcodeFile)), "Karim Hartono").Return([]*entity.PrintRequest{}, nil).Once()
+	suite.mockPanelRepo.On("Insert", testifymock.Anything).Return(0, errors.New("[TEST] Failed to insert")).Once()
+
+	code, err := suite.handlerInstance.Create(httptest.NewRecorder(), req, nil)
+
+	suite.Equal(http.StatusInternalServerError, code)
+	suite.NotNil(err)
+	_, err = files.Open(storage.Hash([]byte(gcodeFile)) + ".gcode")
+	suite.Equal(file_storage.ErrFileNotFound, err, "the file of the request that was not created is deleted")
+}
+
+func (suite *PrintRequestHandlerTestSuite) TestCreateDuplicate() {
+	gcodeFile := "G1 X10 Y10 E1\n; filament used [mm] = 1000\n; estimated printing time (normal mode) = 1h\n"
+	hash := storage.Hash([]byte(gcodeFile))
+	existing := []*entity.PrintRequest{{Id: 7, FileHash: hash, Requestor: "Karim Hartono"}}
+
+	var testCase = []struct {
+		testcase    string
+		policy      string
+		duplicates  []*entity.PrintRequest
+		isError     bool
+		code        int
+		duplicateOf int
+	}{
+		{
+			testcase:    "not a duplicate",
+			policy:      handler.DuplicateReject,
+			duplicates:  []*entity.PrintRequest{},
+			isError:     false,
+			code:        http.StatusOK,
+			duplicateOf: 0,
+		},
+		{
+			testcase:   "rejected by default",
+			policy:     "",
+			duplicates: existing,
+			isError:    true,
+			code:       http.StatusConflict,
+		},
+		{
+			testcase:    "marked as duplicate",
+			policy:      handler.DuplicateMark,
+			duplicates:  existing,
+			isError:     false,
+			code:        http.StatusOK,
+			duplicateOf: 7,
+		},
+	}
+	for _, tc := range testCase {
+		suite.SetupTest()
+		suite.handlerInstance.DuplicatePolicy = tc.policy
+
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		writer.WriteField("item_name", "phone holder")
+		writer.WriteField("requestor", "Karim Hartono")
+		part, _ := writer.CreateFormFile("file", "phone_holder.gcode")
+		part.Write([]byte(gcodeFile))
+		writer.Close()
+
+		req, _ := http.NewRequest("POST", "/print-requests", body)
+		req.Header.Add("Content-Type", writer.FormDataContentType())
+		responseRecorder := httptest.NewRecorder()
+		suite.mockPanelRepo.On("GetActiveByFileHash", hash, "Karim Hartono").Return(tc.duplicates, nil).Times(1)
+		suite.mockPanelRepo.On("Insert", testifymock.MatchedBy(func(m *entity.PrintRequest) bool {
+			return m.FileHash == hash && m.DuplicateOf == tc.duplicateOf
+		})).Return(8, nil).Times(1)
+		suite.mockPanelRepo.On("GetById", 8).Return(&entity.PrintRequest{Id: 8}, nil).Times(1)
+
+		code, err := suite.handlerInstance.Create(responseRecorder, req, nil)
+
+		suite.Equal(tc.code, code, tc.testcase)
+		if tc.isError {
+			suite.NotNil(err, tc.testcase)
+			suite.Contains(responseRecorder.Body.String(), `"link":"/print-requests/7"`, tc.testcase)
+			suite.mockPanelRepo.AssertNotCalled(suite.T(), "Insert", testifymock.Anything)
+		} else {
+			suite.Nil(err, tc.testcase)
+			suite.mockPanelRepo.AssertExpectations(suite.T())
+		}
+	}
+}
+
+func (suite *PrintRequestHandlerTestSuite) TestCreateBuildVolume() {
+	printers := []*entity.Printer{{Id: 1, Name: "Prusa MK3S", BuildWidth: 250, BuildDepth: 210, BuildHeight: 210}}
+
+	var testCase = []struct {
+		testcase string
+		filename string
+		isError  bool
+		code     int
+	}{
+		{
+			testcase: "fits",
+			filename: "cube_20mm.3mf",
+			isError:  false,
+			code:     http.StatusOK,
+		},
+		{
+			testcase: "too large for every printer",
+			filename: "cube_300mm.3mf",
+			isError:  true,
+			code:     http.StatusBadRequest,
+		},
+	}
+	for _, tc := range testCase {
+		content, _ := ioutil.ReadFile("../testdata/models/" + tc.filename)
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		writer.WriteField("item_name", "cube")
+		writer.WriteField("requestor", "Kosasih")
+		part, _ := writer.CreateFormFile("file", tc.filename)
+		part.Write(content)
+		writer.Close()
+
+		req, _ := http.NewRequest("POST", "/print-requests", body)
+		req.Header.Add("Content-Type", writer.FormDataContentType())
+		responseRecorder := httptest.NewRecorder()
+		suite.mockPrinterRepo.On("GetAll").Return(printers, nil).Times(1)
+		suite.mockPanelRepo.On("GetActiveByFileHash", testifymock.Anything, "Kosasih").Return([]*entity.PrintRequest{}, nil).Times(1)
+		suite.mockPanelRepo.On("Insert", testifymock.Anything).Return(1, nil).Times(1)
+		suite.mockPanelRepo.On("GetById", 1).Return(&entity.PrintRequest{Id: 1}, nil).Times(1)
+
+		code, err := suite.handlerInstance.Create(responseRecorder, req, nil)
+
+		suite.Equal(tc.code, code, tc.testcase)
+		if tc.isError {
+			suite.NotNil(err, tc.testcase)
+			suite.Contains(err.Error(), "Prusa MK3S (width 300.0 > 250.0 mm", tc.testcase)
+		} else {
+			suite.Nil(err, tc.testcase)
 		}
 	}
 }
@@ -264,10 +698,12 @@ func (suite *PrintRequestHandlerTestSuite) TestUpdate() {
 	}
 
 	showModelProcessed := entity.PrintRequest{
+		Id:     2,
 		Status: "processed",
 	}
 
 	showModelFinished := entity.PrintRequest{
+		Id:     3,
 		Status: "finished",
 	}
 
@@ -279,6 +715,12 @@ func (suite *PrintRequestHandlerTestSuite) TestUpdate() {
 		EstimatedDuration:       9000,
 		FileUrl:                 "http://drive.google.com/filez/100",
 		Requestor:               "Karim Hartono",
+		Material:                entity.DefaultMaterial,
+		LayerHeight:             entity.DefaultLayerHeight,
+		NozzleSize:              entity.DefaultNozzleSize,
+		Quantity:                1,
+		EstimatedCost:           0.75,
+		Status:                  "received",
 	}
 
 	var testCase = []struct {
@@ -362,6 +804,40 @@ func (suite *PrintRequestHandlerTestSuite) TestUpdate() {
 	}
 }
 
+func (suite *PrintRequestHandlerTestSuite) TestUpdateByStatus() {
+	reqBody := `{"item_name":"Cup Holder","requestor":"andi"}`
+	for status, code := range map[string]int{
+		entity.StatusPendingReview:    http.StatusOK,
+		entity.StatusChangesRequested: http.StatusOK,
+		entity.StatusReceived:         http.StatusOK,
+		entity.StatusProcessed:        http.StatusBadRequest,
+		entity.StatusApproved:         http.StatusBadRequest,
+		entity.StatusRejected:         http.StatusBadRequest,
+		entity.StatusFinished:         http.StatusBadRequest,
+		entity.StatusFailed:           http.StatusBadRequest,
+	} {
+		suite.SetupTest()
+		suite.mockPanelRepo.On("GetById", 1).Return(&entity.PrintRequest{Id: 1, Status: status}, nil).Once()
+		if code == http.StatusOK {
+			suite.mockPanelRepo.On("Update", testifymock.Anything).Return(true, nil).Once()
+		}
+		req, _ := http.NewRequest("PUT", "/print-requests/1", strings.NewReader(reqBody))
+		responseRecorder := httptest.NewRecorder()
+
+		result, err := suite.handlerInstance.Update(responseRecorder, req, []httprouter.Param{{Key: "id", Value: "1"}})
+
+		suite.Equal(code, result, status)
+		if code == http.StatusOK {
+			suite.Nil(err, status)
+			suite.Equal(1, suite.mockPanelRepo.Commits, "the edit is made in a transaction")
+		} else {
+			suite.EqualError(err, "you can not edit a request that is already "+status, status)
+			suite.Equal(1, suite.mockPanelRepo.Rollbacks, status)
+		}
+		suite.mockPanelRepo.AssertExpectations(suite.T())
+	}
+}
+
 //===============================================DELETE========================================================
 
 func (suite *PrintRequestHandlerTestSuite) TestDelete() {
@@ -373,6 +849,7 @@ func (suite *PrintRequestHandlerTestSuite) TestDelete() {
 		isError      bool
 		deleteResult bool
 		deleteError  error
+		code         int
 	}{
 		{
 			testcase:     "success",
@@ -398,20 +875,32 @@ func (suite *PrintRequestHandlerTestSuite) TestDelete() {
 			deleteResult: false,
 			deleteError:  nil,
 		},
+		{
+			testcase:     "not found",
+			id:           "1",
+			isTimeout:    false,
+			isError:      true,
+			deleteResult: false,
+			deleteError:  &print_request.NotFoundError{Id: 1},
+			code:         http.StatusNotFound,
+		},
 	}
 	for _, tc := range testCase {
 		req, _ := http.NewRequest("DELETE", "/print-requests/:id", nil)
 		req.Header.Add("Content-Type", "application/json")
 		responseRecorder := httptest.NewRecorder()
-		suite.mockPanelRepo.On("Delete", 1).Return(tc.deleteResult, tc.deleteError).Times(1)
+		if !tc.isTimeout {
+			suite.mockPanelRepo.On("Delete", 1).Return(tc.deleteResult, tc.deleteError).Once()
+		}
 
+		var code int
 		var err error
 		if tc.isTimeout {
 			ctx, cancel := context.WithTimeout(req.Context(), -7*time.Hour)
 			defer cancel()
-			_, err = suite.handlerInstance.Delete(responseRecorder, req.WithContext(ctx), []httprouter.Param{{Key: "id", Value: tc.id}})
+			code, err = suite.handlerInstance.Delete(responseRecorder, req.WithContext(ctx), []httprouter.Param{{Key: "id", Value: tc.id}})
 		} else {
-			_, err = suite.handlerInstance.Delete(responseRecorder, req, []httprouter.Param{{Key: "id", Value: tc.id}})
+			code, err = suite.handlerInstance.Delete(responseRecorder, req, []httprouter.Param{{Key: "id", Value: tc.id}})
 		}
 
 		if tc.isError {
@@ -419,7 +908,289 @@ func (suite *PrintRequestHandlerTestSuite) TestDelete() {
 		} else {
 			suite.Nil(err)
 		}
+		if tc.code != 0 {
+			suite.Equal(tc.code, code, tc.testcase)
+		}
+	}
+}
+
+//===============================================SEARCH========================================================
+
+func (suite *PrintRequestHandlerTestSuite) TestSearch() {
+	found := []*entity.SearchResult{{PrintRequest: &entity.PrintRequest{Id: 1, ItemName: "Cup Holder"}, Rank: 0.6}}
+
+	var testCase = []struct {
+		testcase     string
+		query        string
+		isError      bool
+		code         int
+		limit        int
+		searchResult []*entity.SearchResult
+		searchError  error
+	}{
+		{
+			testcase:     "success",
+			query:        "?q=cup+hold",
+			isError:      false,
+			code:         http.StatusOK,
+			limit:        handler.DefaultSearchLimit,
+			searchResult: found,
+		},
+		{
+			testcase:     "with limit",
+			query:        "?q=cup&limit=5",
+			isError:      false,
+			code:         http.StatusOK,
+			limit:        5,
+			searchResult: found,
+		},
+		{
+			testcase: "no words",
+			query:    "?q=%26%21",
+			isError:  true,
+			code:     http.StatusBadRequest,
+		},
+		{
+			testcase: "limit too big",
+			query:    "?q=cup&limit=1000",
+			isError:  true,
+			code:     http.StatusBadRequest,
+		},
+		{
+			testcase:     "returns error",
+			query:        "?q=broken",
+			isError:      true,
+			code:         http.StatusInternalServerError,
+			limit:        handler.DefaultSearchLimit,
+			searchResult: nil,
+			searchError:  errors.New("[TEST] Failed to search"),
+		},
+	}
+	for _, tc := range testCase {
+		req, _ := http.NewRequest("GET", "/print-requests/search"+tc.query, nil)
+		responseRecorder := httptest.NewRecorder()
+		if tc.limit > 0 {
+			suite.mockPanelRepo.On("Search", req.URL.Query().Get("q"), tc.limit).Return(tc.searchResult, tc.searchError).Once()
+		}
+
+		code, err := suite.handlerInstance.Search(responseRecorder, req, httprouter.Params{})
+
+		suite.Equal(tc.code, code, tc.testcase)
+		if tc.isError {
+			suite.NotNil(err, tc.testcase)
+		} else {
+			suite.Nil(err, tc.testcase)
+			suite.Contains(responseRecorder.Body.String(), `"item_name":"Cup Holder"`, tc.testcase)
+		}
+	}
+}
+
+//===============================================IMPORT========================================================
+
+func (suite *PrintRequestHandlerTestSuite) TestImport() {
+	csvBody := "item_name,requestor,estimated_weight,notes\n" +
+		"Cup Holder,andi,37.5,red\n" +
+		",budi,10,\n" +
+		"Phone Holder,citra,heavy,\n" +
+		"Gantungan baju,dewi,5,\n"
+	jsonlBody := `{"item_name":"Cup Holder","requestor":"andi","estimated_weight":37.5}` + "\n\n" +
+		`{"item_name":"Phone Holder"` + "\n"
+
+	var testCase = []struct {
+		testcase    string
+		query       string
+		contentType string
+		body        string
+		isError     bool
+		code        int
+		inserted    []string
+		insertError error
+		contains    []string
+	}{
+		{
+			testcase:    "csv",
+			contentType: "text/csv",
+			body:        csvBody,
+			code:        http.StatusOK,
+			inserted:    []string{"Cup Holder", "Gantungan baju"},
+			contains:    []string{`"valid":2`, `"invalid":2`, `"imported":2`, `{"line":2,"id":1}`, `{"line":3,"error":"item_name is required"}`, `{"line":4,"error":"estimated_weight is not a number"}`, `{"line":5,"id":2}`},
+		},
+		{
+			testcase:    "jsonl dry run",
+			query:       "?format=jsonl&dry_run=true",
+			contentType: "application/octet-stream",
+			body:        jsonlBody,
+			code:        http.StatusOK,
+			inserted:    []string{"Cup Holder"},
+			contains:    []string{`"dry_run":true`, `"valid":1`, `"imported":0`, `{"line":1}`, `{"line":3,"error":"failed to unmarshal line"}`},
+		},
+		{
+			testcase:    "unknown format",
+			contentType: "application/json",
+			body:        jsonlBody,
+			isError:     true,
+			code:        http.StatusBadRequest,
+		},
+		{
+			testcase:    "no rows",
+			contentType: "text/csv",
+			body:        "item_name,requestor\n",
+			isError:     true,
+			code:        http.StatusBadRequest,
+		},
+		{
+			testcase:    "insert fails",
+			contentType: "text/csv",
+			body:        csvBody,
+			isError:     true,
+			code:        http.StatusInternalServerError,
+			inserted:    []string{"Cup Holder"},
+			insertError: errors.New("[TEST] Failed to insert"),
+		},
+	}
+	for _, tc := range testCase {
+		suite.SetupTest()
+		for i, name := range tc.inserted {
+			name := name
+			suite.mockPanelRepo.On("Insert", testifymock.MatchedBy(func(model *entity.PrintRequest) bool {
+				return model.ItemName == name
+			})).Return(i+1, tc.insertError).Once()
+		}
+		req, _ := http.NewRequest("POST", "/print-requests/import"+tc.query, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", tc.contentType)
+		responseRecorder := httptest.NewRecorder()
+
+		code, err := suite.handlerInstance.Import(responseRecorder, req, httprouter.Params{})
+
+		suite.Equal(tc.code, code, tc.testcase)
+		if tc.isError {
+			suite.NotNil(err, tc.testcase)
+		} else {
+			suite.Nil(err, tc.testcase)
+			for _, c := range tc.contains {
+				suite.Contains(responseRecorder.Body.String(), c, tc.testcase)
+			}
+		}
+		if tc.insertError != nil || strings.Contains(tc.query, "dry_run=true") {
+			suite.Equal(1, suite.mockPanelRepo.Rollbacks, "rows inserted before the failure are rolled back")
+			suite.Equal(0, suite.mockPanelRepo.Commits, tc.testcase)
+		}
+		suite.mockPanelRepo.AssertExpectations(suite.T())
+	}
+}
+
+func (suite *PrintRequestHandlerTestSuite) TestImportLikeCreate() {
+	body := `{"item_name":"Cup Holder","requestor":"andi","status":"finished"}` + "\n" +
+		`{"item_name":"Phone Holder","requestor":"andi","estimated_weight":50,"status":"pending_review","file_key":"a.stl","file_hash":"a","duplicate_of":1}` + "\n"
+	suite.mockPanelRepo.On("Insert", testifymock.MatchedBy(func(model *entity.PrintRequest) bool {
+		return model.ItemName == "Phone Holder" && model.FileKey == "" && model.FileHash == "" && model.DuplicateOf == 0 && model.EstimatedCost > 0
+	})).Return(1, nil).Once()
+	req, _ := http.NewRequest("POST", "/print-requests/import?format=jsonl", strings.NewReader(body))
+	responseRecorder := httptest.NewRecorder()
+
+	code, err := suite.handlerInstance.Import(responseRecorder, req, httprouter.Params{})
+
+	suite.Equal(http.StatusOK, code)
+	suite.Nil(err)
+	suite.Contains(responseRecorder.Body.String(), `{"line":1,"error":"status must be empty or pending_review, imported requests wait for review"}`)
+	suite.Contains(responseRecorder.Body.String(), `{"line":2,"id":1}`)
+	suite.mockPanelRepo.AssertExpectations(suite.T())
+}
+
+func (suite *PrintRequestHandlerTestSuite) TestImportQuota() {
+	body := "item_name,requestor,estimated_weight\n" +
+		"Cup Holder,andi,300\n" +
+		"Phone Holder,andi,300\n"
+	quotas := &mock.MockQuotaRepository{}
+	suite.handlerInstance.Quotas = quotas
+	quotas.On("GetByRequestor", "andi").Return(&entity.Quota{Requestor: "andi", MaxGramsPerMonth: 500}, nil).Once()
+	suite.mockPanelRepo.On("GetQuotaUsage", "andi", testifymock.Anything, testifymock.Anything).Return(&entity.QuotaUsage{}, nil).Once()
+	suite.mockPanelRepo.On("GetQuotaUsage", "andi", testifymock.Anything, testifymock.Anything).Return(&entity.QuotaUsage{Active: 1, GramsThisMonth: 300}, nil).Once()
+	suite.mockPanelRepo.On("Insert", testifymock.Anything).Return(1, nil).Once()
+	req, _ := http.NewRequest("POST", "/print-requests/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/csv")
+	responseRecorder := httptest.NewRecorder()
+
+	code, err := suite.handlerInstance.Import(responseRecorder, req, httprouter.Params{})
+
+	suite.Equal(http.StatusOK, code)
+	suite.Nil(err)
+	suite.Contains(responseRecorder.Body.String(), `"valid":1,"invalid":1,"imported":1`)
+	suite.Contains(responseRecorder.Body.String(), `{"line":3,"error":"quota exceeded: 600 of 500 grams this month"}`)
+	quotas.AssertExpectations(suite.T())
+	suite.mockPanelRepo.AssertExpectations(suite.T())
+}
+
+//===============================================EXPORT========================================================
+
+func (suite *PrintRequestHandlerTestSuite) TestExport() {
+	rows := []*entity.PrintRequest{{Id: 1, ItemName: "Cup Holder", Requestor: "andi", Status: "finished"}}
+
+	var testCase = []struct {
+		testcase    string
+		query       string
+		isError     bool
+		code        int
+		contentType string
+		filter      *entity.PrintRequestFilter
+		eachError   error
+	}{
+		{
+			testcase:    "csv by default",
+			query:       "",
+			code:        http.StatusOK,
+			contentType: "text/csv; charset=utf-8",
+			filter:      &entity.PrintRequestFilter{},
+		},
+		{
+			testcase:    "xlsx with filters",
+			query:       "?format=xlsx&requestor=andi&status=finished",
+			code:        http.StatusOK,
+			contentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			filter:      &entity.PrintRequestFilter{Requestor: "andi", Status: "finished"},
+		},
+		{
+			testcase: "unknown format",
+			query:    "?format=ods",
+			isError:  true,
+			code:     http.StatusBadRequest,
+		},
+		{
+			testcase: "unknown status",
+			query:    "?status=lost",
+			isError:  true,
+			code:     http.StatusBadRequest,
+		},
+		{
+			testcase:  "returns error",
+			query:     "?requestor=broken",
+			isError:   true,
+			code:      http.StatusInternalServerError,
+			filter:    &entity.PrintRequestFilter{Requestor: "broken"},
+			eachError: errors.New("[TEST] Failed to retrieve data"),
+		},
+	}
+	for _, tc := range testCase {
+		req, _ := http.NewRequest("GET", "/print-requests/export"+tc.query, nil)
+		responseRecorder := httptest.NewRecorder()
+		if tc.filter != nil {
+			suite.mockPanelRepo.On("Each", tc.filter).Return(rows, tc.eachError).Once()
+		}
+
+		code, err := suite.handlerInstance.Export(responseRecorder, req, httprouter.Params{})
+
+		suite.Equal(tc.code, code, tc.testcase)
+		if tc.isError {
+			suite.NotNil(err, tc.testcase)
+			suite.Equal("application/json", responseRecorder.Header().Get("Content-Type"), tc.testcase)
+			suite.Empty(responseRecorder.Header().Get("Content-Disposition"), tc.testcase)
+		} else {
+			suite.Nil(err, tc.testcase)
+			suite.Equal(tc.contentType, responseRecorder.Header().Get("Content-Type"), tc.testcase)
+			suite.Contains(responseRecorder.Header().Get("Content-Disposition"), "attachment", tc.testcase)
+		}
 	}
+	suite.mockPanelRepo.AssertExpectations(suite.T())
 }
 
 //===============================================CHANGESTATUS========================================================
@@ -429,6 +1200,7 @@ func (suite *PrintRequestHandlerTestSuite) TestChangeStatus() {
 		Status: "processed",
 	}
 	reqBodyBytesProcessed, _ := json.Marshal(modelProcessed)
+	reqBodyBytesUnknown, _ := json.Marshal(entity.PrintRequest{Status: "shipped"})
 
 	showModelReceived := entity.PrintRequest{
 		Id:                      1,
@@ -492,6 +1264,16 @@ func (suite *PrintRequestHandlerTestSuite) TestChangeStatus() {
 			changeStatusError:  nil,
 			showResult:         nil,
 		},
+		{
+			testcase:           "unknown status",
+			id:                 "1",
+			reqBody:            reqBodyBytesUnknown,
+			isTimeout:          false,
+			isError:            true,
+			changeStatusResult: false,
+			changeStatusError:  nil,
+			showResult:         &showModelReceived,
+		},
 	}
 	for _, tc := range testCase {
 		req, _ := http.NewRequest("PUT", "/print-requests/:id/status", strings.NewReader(string(tc.reqBody)))
@@ -517,6 +1299,143 @@ func (suite *PrintRequestHandlerTestSuite) TestChangeStatus() {
 	}
 }
 
+func (suite *PrintRequestHandlerTestSuite) TestChangeStatusTransition() {
+	var testCase = []struct {
+		testcase string
+		from     string
+		to       string
+		code     int
+		error    string
+	}{
+		{
+			testcase: "any status outside of review",
+			from:     entity.StatusFinished,
+			to:       entity.StatusProcessed,
+			code:     http.StatusOK,
+		},
+		{
+			testcase: "out of review",
+			from:     entity.StatusPendingReview,
+			to:       entity.StatusApproved,
+			code:     http.StatusConflict,
+			error:    "status can not change from pending_review to approved",
+		},
+		{
+			testcase: "into review",
+			from:     entity.StatusReceived,
+			to:       entity.StatusChangesRequested,
+			code:     http.StatusConflict,
+			error:    "status can not change from received to changes_requested",
+		},
+	}
+	for _, tc := range testCase {
+		suite.SetupTest()
+		suite.mockPanelRepo.On("GetById", 1).Return(&entity.PrintRequest{Id: 1, Status: tc.from}, nil).Once()
+		if tc.error == "" {
+			suite.mockPanelRepo.On("Update", testifymock.MatchedBy(func(model *entity.PrintRequest) bool {
+				return model.Status == tc.to
+			})).Return(true, nil).Once()
+		}
+		req, _ := http.NewRequest("PUT", "/print-requests/1/status", strings.NewReader(`{"status":"`+tc.to+`"}`))
+		responseRecorder := httptest.NewRecorder()
+
+		code, err := suite.handlerInstance.ChangeStatus(responseRecorder, req, []httprouter.Param{{Key: "id", Value: "1"}})
+
+		suite.Equal(tc.code, code, tc.testcase)
+		if tc.error != "" {
+			suite.EqualError(err, tc.error, tc.testcase)
+		} else {
+			suite.Nil(err, tc.testcase)
+		}
+		suite.mockPanelRepo.AssertExpectations(suite.T())
+	}
+}
+
+//===============================================BATCHSTATUS========================================================
+
+func (suite *PrintRequestHandlerTestSuite) TestBatchStatus() {
+	var testCase = []struct {
+		testcase string
+		reqBody  string
+		isError  bool
+		code     int
+		stored   map[int]string
+		updated  []int
+		contains []string
+	}{
+		{
+			testcase: "success",
+			reqBody:  `{"ids":[1,2,1],"status":"finished"}`,
+			code:     http.StatusOK,
+			stored:   map[int]string{1: entity.StatusApproved, 2: entity.StatusApproved},
+			updated:  []int{1, 2},
+			contains: []string{`{"id":1,"previous_status":"approved","changed":true}`, `{"id":2,"previous_status":"approved","changed":true}`},
+		},
+		{
+			testcase: "atomic with a failure",
+			reqBody:  `{"ids":[1,2,3],"status":"finished"}`,
+			isError:  true,
+			code:     http.StatusConflict,
+			stored:   map[int]string{1: entity.StatusApproved, 2: entity.StatusReceived},
+			contains: []string{`"changed":false`, `"error":"status can not change from received to finished"`, `"error":"print request 3 not found"`},
+		},
+		{
+			testcase: "not atomic with a failure",
+			reqBody:  `{"ids":[1,2],"status":"finished","atomic":false}`,
+			code:     http.StatusOK,
+			stored:   map[int]string{1: entity.StatusApproved, 2: entity.StatusRejected},
+			updated:  []int{1},
+			contains: []string{`{"id":1,"previous_status":"approved","changed":true}`, `{"id":2,"previous_status":"rejected","changed":false,"error":"status can not change from rejected to finished"}`},
+		},
+		{
+			testcase: "unknown status",
+			reqBody:  `{"ids":[1],"status":"shipped"}`,
+			isError:  true,
+			code:     http.StatusBadRequest,
+		},
+		{
+			testcase: "no ids",
+			reqBody:  `{"ids":[],"status":"finished"}`,
+			isError:  true,
+			code:     http.StatusBadRequest,
+		},
+	}
+	for _, tc := range testCase {
+		suite.SetupTest()
+		for id := 1; id <= 3; id++ {
+			model := entity.NewPrintRequest()
+			if status, ok := tc.stored[id]; ok {
+				model = &entity.PrintRequest{Id: id, Status: status}
+			}
+			suite.mockPanelRepo.On("GetById", id).Return(model, nil).Maybe()
+		}
+		for _, id := range tc.updated {
+			id := id
+			suite.mockPanelRepo.On("Update", testifymock.MatchedBy(func(model *entity.PrintRequest) bool {
+				return model.Id == id && model.Status == entity.StatusFinished
+			})).Return(true, nil).Once()
+		}
+		req, _ := http.NewRequest("POST", "/print-requests/batch/status", strings.NewReader(tc.reqBody))
+		responseRecorder := httptest.NewRecorder()
+
+		code, err := suite.handlerInstance.BatchStatus(responseRecorder, req, httprouter.Params{})
+
+		suite.Equal(tc.code, code, tc.testcase)
+		if tc.isError {
+			suite.NotNil(err, tc.testcase)
+		} else {
+			suite.Nil(err, tc.testcase)
+		}
+		for _, c := range tc.contains {
+			suite.Contains(responseRecorder.Body.String(), c, tc.testcase)
+		}
+		suite.mockPanelRepo.AssertExpectations(suite.T())
+		if len(tc.updated) == 0 {
+			suite.mockPanelRepo.AssertNotCalled(suite.T(), "Update", testifymock.Anything)
+		}
+	}
+}
+
 //===============================================TESTING========================================================
 
 // 4
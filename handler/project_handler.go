@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	print_request "threedee/interfaces/print-request"
+	"threedee/interfaces/project"
+	"threedee/utility/normalizer"
+	"threedee/utility/response"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type ProjectHandler struct {
+	Repo        project.ProjectRepositoryInterface
+	Requests    print_request.PrintRequestRepositoryInterface
+	Norm        *normalizer.ProjectNormalizer
+	RequestNorm *normalizer.PrintRequestNormalizer // reads the filters of the parts
+}
+
+func NewProjectHandler(repo project.ProjectRepositoryInterface, requests print_request.PrintRequestRepositoryInterface, norm *normalizer.ProjectNormalizer, requestNorm *normalizer.PrintRequestNormalizer) *ProjectHandler {
+	return &ProjectHandler{repo, requests, norm, requestNorm}
+}
+
+// handle GET /projects
+func (h *ProjectHandler) Index(w http.ResponseWriter, r *http.Request, _ httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	data, err := h.Repo.GetAll()
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+	return http.StatusOK, response.WriteSuccess(w, data, "success")
+}
+
+// handle GET /projects/:id
+func (h *ProjectHandler) Show(w http.ResponseWriter, r *http.Request, p httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	id, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("id is not a number"))
+	}
+
+	data, err := h.Repo.GetById(id)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+	if data == nil || data.Id == 0 {
+		return http.StatusNotFound, response.WriteNotFoundError(w, errors.New("record not found"))
+	}
+
+	return http.StatusOK, response.WriteSuccess(w, data, "success")
+}
+
+// handle GET /projects/:id/print-requests?requestor=&status=
+//
+// Lists the parts of a project, taking the filters of GET /print-requests
+func (h *ProjectHandler) PrintRequests(w http.ResponseWriter, r *http.Request, p httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	id, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("id is not a number"))
+	}
+	filter, err := h.RequestNorm.ReadAndNormalizeFilter(r)
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, err)
+	}
+
+	data, err := h.Repo.GetById(id)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+	if data == nil || data.Id == 0 {
+		return http.StatusNotFound, response.WriteNotFoundError(w, errors.New("record not found"))
+	}
+
+	filter.ProjectId = id
+	parts, err := h.Requests.GetAll(filter)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+
+	return http.StatusOK, response.WriteSuccess(w, parts, "success")
+}
+
+// handle POST /projects
+//
+// Parts are added by creating or updating print requests with the project_id
+func (h *ProjectHandler) Create(w http.ResponseWriter, r *http.Request, _ httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	model, err := h.Norm.ReadAndNormalize(w, r)
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, err)
+	}
+
+	id, err := h.Repo.Insert(model)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+
+	data, err := h.Repo.GetById(id)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+
+	return http.StatusOK, response.WriteSuccess(w, data, "success")
+}
+
+// handle PUT /projects/:id
+func (h *ProjectHandler) Update(w http.ResponseWriter, r *http.Request, p httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	id, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("id is not a number"))
+	}
+
+	model, err := h.Norm.ReadAndNormalize(w, r)
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, err)
+	}
+
+	model.Id = id
+	_, err = h.Repo.Update(model)
+	if err != nil {
+		return writeRepositoryError(w, err)
+	}
+
+	data, err := h.Repo.GetById(id)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+
+	return http.StatusOK, response.WriteSuccess(w, data, "success")
+}
+
+// handle DELETE /projects/:id
+//
+// Only projects without active parts can be deleted, so no request points to a deleted one
+func (h *ProjectHandler) Delete(w http.ResponseWriter, r *http.Request, p httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	id, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("id is not a number"))
+	}
+
+	_, err = h.Repo.Delete(id)
+	var hasParts *project.HasPartsError
+	if errors.As(err, &hasParts) {
+		return http.StatusConflict, response.WriteConflictError(w, nil, err)
+	}
+	if err != nil {
+		return writeRepositoryError(w, err)
+	}
+
+	return http.StatusOK, response.WriteSuccess(w, nil, "success")
+}
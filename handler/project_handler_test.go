@@ -0,0 +1,236 @@
+package handler_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"threedee/entity"
+	"threedee/handler"
+	"threedee/interfaces/project"
+	"threedee/testdata/mock"
+	"threedee/utility/normalizer"
+
+	"github.com/julienschmidt/httprouter"
+	testifymock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ProjectHandlerTestSuite struct {
+	suite.Suite
+	mockProjectRepo *mock.MockProjectRepository
+	mockPanelRepo   *mock.MockPrintRequestRepository
+	handlerInstance handler.ProjectHandler
+}
+
+func (suite *ProjectHandlerTestSuite) SetupTest() {
+	suite.mockProjectRepo = &mock.MockProjectRepository{}
+	suite.mockPanelRepo = &mock.MockPrintRequestRepository{}
+	suite.handlerInstance = handler.ProjectHandler{
+		Repo:        suite.mockProjectRepo,
+		Requests:    suite.mockPanelRepo,
+		Norm:        normalizer.NewProjectNormalizer(),
+		RequestNorm: normalizer.NewPrintRequestNormalizer(),
+	}
+}
+
+// newProject returns a stored project with parts by status
+func newProject(id int, parts map[string]int) *entity.Project {
+	model := entity.NewProject()
+	model.Id = id
+	model.Name = "Drone Frame"
+	model.Owner = "andi"
+	for status, count := range parts {
+		model.AddParts(status, count, 37.5*float32(count), 0, 9000*count, 0.75*float32(count))
+	}
+	return model
+}
+
+//===============================================INDEX========================================================
+
+func (suite *ProjectHandlerTestSuite) TestIndex() {
+	suite.mockProjectRepo.On("GetAll").Return([]*entity.Project{newProject(1, map[string]int{entity.StatusFinished: 2, entity.StatusApproved: 1})}, nil).Once()
+
+	req, _ := http.NewRequest("GET", "/projects", nil)
+	responseRecorder := httptest.NewRecorder()
+	code, err := suite.handlerInstance.Index(responseRecorder, req, nil)
+
+	suite.Equal(http.StatusOK, code)
+	suite.Nil(err)
+	suite.Contains(responseRecorder.Body.String(), `"status":"in_progress","parts":{"approved":1,"finished":2},"estimated_weight":112.5`)
+
+	suite.mockProjectRepo.On("GetAll").Return([]*entity.Project(nil), errors.New("[TEST] Failed to retrieve data")).Once()
+	code, err = suite.handlerInstance.Index(httptest.NewRecorder(), req, nil)
+
+	suite.Equal(http.StatusInternalServerError, code)
+	suite.NotNil(err)
+}
+
+//===============================================SHOW========================================================
+
+func (suite *ProjectHandlerTestSuite) TestShow() {
+	suite.mockProjectRepo.On("GetById", 1).Return(newProject(1, nil), nil).Once()
+	suite.mockProjectRepo.On("GetById", 2).Return(entity.NewProject(), nil).Once()
+
+	req, _ := http.NewRequest("GET", "/projects/1", nil)
+	code, err := suite.handlerInstance.Show(httptest.NewRecorder(), req, []httprouter.Param{{Key: "id", Value: "1"}})
+	suite.Equal(http.StatusOK, code)
+	suite.Nil(err)
+
+	code, err = suite.handlerInstance.Show(httptest.NewRecorder(), req, []httprouter.Param{{Key: "id", Value: "2"}})
+	suite.Equal(http.StatusNotFound, code)
+	suite.NotNil(err)
+
+	code, err = suite.handlerInstance.Show(httptest.NewRecorder(), req, []httprouter.Param{{Key: "id", Value: "a"}})
+	suite.Equal(http.StatusBadRequest, code)
+	suite.NotNil(err)
+}
+
+//===============================================PRINT REQUESTS========================================================
+
+func (suite *ProjectHandlerTestSuite) TestPrintRequests() {
+	parts := []*entity.PrintRequest{{Id: 4, ProjectId: 1, Status: entity.StatusFinished}}
+	suite.mockProjectRepo.On("GetById", 1).Return(newProject(1, map[string]int{entity.StatusFinished: 1}), nil)
+	suite.mockProjectRepo.On("GetById", 2).Return(entity.NewProject(), nil)
+	suite.mockPanelRepo.On("GetAll", &entity.PrintRequestFilter{Status: entity.StatusFinished, ProjectId: 1}).Return(parts, nil).Once()
+
+	req, _ := http.NewRequest("GET", "/projects/1/print-requests?status=finished", nil)
+	responseRecorder := httptest.NewRecorder()
+	code, err := suite.handlerInstance.PrintRequests(responseRecorder, req, []httprouter.Param{{Key: "id", Value: "1"}})
+
+	suite.Equal(http.StatusOK, code)
+	suite.Nil(err)
+	suite.Contains(responseRecorder.Body.String(), `"project_id":1`)
+
+	req, _ = http.NewRequest("GET", "/projects/2/print-requests", nil)
+	code, err = suite.handlerInstance.PrintRequests(httptest.NewRecorder(), req, []httprouter.Param{{Key: "id", Value: "2"}})
+	suite.Equal(http.StatusNotFound, code)
+	suite.NotNil(err)
+
+	req, _ = http.NewRequest("GET", "/projects/1/print-requests?status=lost", nil)
+	code, err = suite.handlerInstance.PrintRequests(httptest.NewRecorder(), req, []httprouter.Param{{Key: "id", Value: "1"}})
+	suite.Equal(http.StatusBadRequest, code)
+	suite.NotNil(err)
+
+	suite.mockPanelRepo.AssertExpectations(suite.T())
+}
+
+//===============================================CREATE========================================================
+
+func (suite *ProjectHandlerTestSuite) TestCreate() {
+	var testCase = []struct {
+		testcase string
+		reqBody  string
+		isError  bool
+		code     int
+	}{
+		{
+			testcase: "success",
+			reqBody:  `{"name":" Drone Frame ","owner":"andi","description":"250 mm quad","status":"finished"}`,
+			code:     http.StatusOK,
+		},
+		{
+			testcase: "no owner",
+			reqBody:  `{"name":"Drone Frame"}`,
+			isError:  true,
+			code:     http.StatusBadRequest,
+		},
+		{
+			testcase: "name too long",
+			reqBody:  `{"name":"` + strings.Repeat("a", 101) + `","owner":"andi"}`,
+			isError:  true,
+			code:     http.StatusBadRequest,
+		},
+	}
+	for _, tc := range testCase {
+		suite.SetupTest()
+		suite.mockProjectRepo.On("Insert", testifymock.MatchedBy(func(model *entity.Project) bool {
+			return model.Name == "Drone Frame" && model.Owner == "andi" && model.Description == "250 mm quad"
+		})).Return(1, nil).Once()
+		suite.mockProjectRepo.On("GetById", 1).Return(newProject(1, nil), nil).Once()
+
+		req, _ := http.NewRequest("POST", "/projects", strings.NewReader(tc.reqBody))
+		req.Header.Add("Content-Type", "application/json")
+		responseRecorder := httptest.NewRecorder()
+		code, err := suite.handlerInstance.Create(responseRecorder, req, nil)
+
+		suite.Equal(tc.code, code, tc.testcase)
+		if tc.isError {
+			suite.NotNil(err, tc.testcase)
+			suite.mockProjectRepo.AssertNotCalled(suite.T(), "Insert", testifymock.Anything)
+		} else {
+			suite.Nil(err, tc.testcase)
+			suite.Contains(responseRecorder.Body.String(), `"status":"empty"`, tc.testcase)
+		}
+	}
+}
+
+//===============================================UPDATE========================================================
+
+func (suite *ProjectHandlerTestSuite) TestUpdate() {
+	suite.mockProjectRepo.On("Update", testifymock.MatchedBy(func(model *entity.Project) bool {
+		return model.Id == 1 && model.Name == "Drone Frame v2"
+	})).Return(true, nil).Once()
+	suite.mockProjectRepo.On("Update", testifymock.MatchedBy(func(model *entity.Project) bool {
+		return model.Id == 2
+	})).Return(false, &project.NotFoundError{Id: 2}).Once()
+	suite.mockProjectRepo.On("GetById", 1).Return(newProject(1, nil), nil).Once()
+
+	req, _ := http.NewRequest("PUT", "/projects/1", strings.NewReader(`{"name":"Drone Frame v2","owner":"andi"}`))
+	code, err := suite.handlerInstance.Update(httptest.NewRecorder(), req, []httprouter.Param{{Key: "id", Value: "1"}})
+	suite.Equal(http.StatusOK, code)
+	suite.Nil(err)
+
+	req, _ = http.NewRequest("PUT", "/projects/2", strings.NewReader(`{"name":"Drone Frame v2","owner":"andi"}`))
+	code, err = suite.handlerInstance.Update(httptest.NewRecorder(), req, []httprouter.Param{{Key: "id", Value: "2"}})
+	suite.Equal(http.StatusNotFound, code)
+	suite.NotNil(err)
+}
+
+//===============================================DELETE========================================================
+
+func (suite *ProjectHandlerTestSuite) TestDelete() {
+	var testCase = []struct {
+		testcase    string
+		deleteError error
+		isError     bool
+		code        int
+	}{
+		{
+			testcase: "success",
+			code:     http.StatusOK,
+		},
+		{
+			testcase:    "has parts",
+			deleteError: &project.HasPartsError{Id: 1},
+			isError:     true,
+			code:        http.StatusConflict,
+		},
+		{
+			testcase:    "not found",
+			deleteError: &project.NotFoundError{Id: 1},
+			isError:     true,
+			code:        http.StatusNotFound,
+		},
+	}
+	for _, tc := range testCase {
+		suite.SetupTest()
+		suite.mockProjectRepo.On("Delete", 1).Return(tc.deleteError == nil, tc.deleteError).Once()
+
+		req, _ := http.NewRequest("DELETE", "/projects/1", nil)
+		code, err := suite.handlerInstance.Delete(httptest.NewRecorder(), req, []httprouter.Param{{Key: "id", Value: "1"}})
+
+		suite.Equal(tc.code, code, tc.testcase)
+		if tc.isError {
+			suite.NotNil(err, tc.testcase)
+		} else {
+			suite.Nil(err, tc.testcase)
+		}
+		suite.mockProjectRepo.AssertExpectations(suite.T())
+	}
+}
+
+func TestProjectHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(ProjectHandlerTestSuite))
+}
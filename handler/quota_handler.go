@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"net/http"
+	"threedee/entity"
+	requestor_quota "threedee/interfaces/requestor-quota"
+	"threedee/utility/normalizer"
+	"threedee/utility/quota"
+	"threedee/utility/response"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type QuotaHandler struct {
+	Repo         requestor_quota.QuotaRepositoryInterface
+	Norm         *normalizer.QuotaNormalizer
+	DefaultQuota *entity.Quota // for requestors without their own quota
+}
+
+// QuotaResponse is the quota that applies to a requestor and what they used of it
+type QuotaResponse struct {
+	*entity.Quota
+	Default bool               `json:"default"` // true when the requestor has no quota of their own
+	Usage   *entity.QuotaUsage `json:"usage"`
+}
+
+func NewQuotaHandler(repo requestor_quota.QuotaRepositoryInterface, norm *normalizer.QuotaNormalizer, defaultQuota *entity.Quota) *QuotaHandler {
+	return &QuotaHandler{repo, norm, defaultQuota}
+}
+
+// handle GET /quotas/:requestor
+func (h *QuotaHandler) Show(w http.ResponseWriter, r *http.Request, p httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	requestor := p.ByName("requestor")
+	data, err := h.Repo.GetByRequestor(requestor)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+	isDefault := data.Requestor == ""
+	if isDefault && h.DefaultQuota != nil {
+		limit := *h.DefaultQuota
+		data = &limit
+	}
+	data.Requestor = requestor
+
+	now := time.Now()
+	usage, err := h.Repo.GetUsage(requestor, quota.MonthStart(now), quota.WeekStart(now))
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+
+	return http.StatusOK, response.WriteSuccess(w, QuotaResponse{data, isDefault, usage}, "success")
+}
+
+// handle PUT /quotas/:requestor
+func (h *QuotaHandler) Update(w http.ResponseWriter, r *http.Request, p httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	model, err := h.Norm.ReadAndNormalize(w, r)
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, err)
+	}
+
+	model.Requestor = p.ByName("requestor")
+	err = h.Repo.Upsert(model)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+
+	return http.StatusOK, response.WriteSuccess(w, model, "success")
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"net/http"
+	print_job "threedee/interfaces/print-job"
+	"threedee/interfaces/report"
+	"threedee/utility/estimator"
+	"threedee/utility/normalizer"
+	"threedee/utility/response"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type ReportHandler struct {
+	Repo report.ReportRepositoryInterface
+	Jobs print_job.PrintJobRepositoryInterface
+	Norm *normalizer.ReportNormalizer
+}
+
+func NewReportHandler(repo report.ReportRepositoryInterface, jobs print_job.PrintJobRepositoryInterface, norm *normalizer.ReportNormalizer) *ReportHandler {
+	return &ReportHandler{repo, jobs, norm}
+}
+
+// handle GET /reports/usage
+//
+// Sums the estimates of the requests created in a date range per day, week or month,
+// optionally per requestor and status, see ReportNormalizer.ReadAndNormalizeUsage.
+func (h *ReportHandler) Usage(w http.ResponseWriter, r *http.Request, _ httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	query, err := h.Norm.ReadAndNormalizeUsage(r)
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, err)
+	}
+
+	data, err := h.Repo.GetUsage(query)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+	return http.StatusOK, response.WriteSuccess(w, data, "success")
+}
+
+// handle GET /reports/estimates
+//
+// Compares the estimated duration and weight of the requests with what their succeeded jobs
+// took, for the jobs that ended in a date range, see estimator.Accuracy
+func (h *ReportHandler) Estimates(w http.ResponseWriter, r *http.Request, _ httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	query, err := h.Norm.ReadAndNormalizeEstimates(r)
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, err)
+	}
+
+	rows, err := h.Jobs.GetEstimates(query)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+	return http.StatusOK, response.WriteSuccess(w, estimator.Accuracy(rows), "success")
+}
@@ -0,0 +1,145 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"threedee/entity"
+	"threedee/handler"
+	"threedee/testdata/mock"
+	"threedee/utility/normalizer"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReportHandlerTestSuite struct {
+	suite.Suite
+	mockReportRepo  *mock.MockReportRepository
+	mockJobRepo     *mock.MockPrintJobRepository
+	handlerInstance handler.ReportHandler
+}
+
+func (suite *ReportHandlerTestSuite) SetupTest() {
+	suite.mockReportRepo = &mock.MockReportRepository{}
+	suite.mockJobRepo = &mock.MockPrintJobRepository{}
+	suite.handlerInstance = handler.ReportHandler{Repo: suite.mockReportRepo, Jobs: suite.mockJobRepo, Norm: normalizer.NewReportNormalizer()}
+}
+
+//===============================================USAGE========================================================
+
+func (suite *ReportHandlerTestSuite) TestUsage() {
+	march := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	var testCase = []struct {
+		testcase    string
+		isTimeout   bool
+		isError     bool
+		code        int
+		query       string
+		usageQuery  *entity.UsageQuery
+		usageResult []*entity.UsageRow
+		usageError  error
+	}{
+		{
+			testcase:    "success",
+			code:        http.StatusOK,
+			query:       "from=2021-03-01&to=2021-03-31&interval=week&group_by=requestor,status",
+			usageQuery:  &entity.UsageQuery{From: march, To: march.AddDate(0, 1, 0), Interval: entity.IntervalWeek, ByRequestor: true, ByStatus: true},
+			usageResult: []*entity.UsageRow{{Period: "2021-03-01", Requestor: "andi", Status: entity.StatusFinished, Requests: 1}},
+		},
+		{
+			testcase: "invalid interval",
+			isError:  true,
+			code:     http.StatusBadRequest,
+			query:    "interval=year",
+		},
+		{
+			testcase: "invalid group",
+			isError:  true,
+			code:     http.StatusBadRequest,
+			query:    "group_by=printer",
+		},
+		{
+			testcase: "from after to",
+			isError:  true,
+			code:     http.StatusBadRequest,
+			query:    "from=2021-03-02&to=2021-03-01",
+		},
+		{
+			testcase:    "returns error",
+			isError:     true,
+			code:        http.StatusInternalServerError,
+			query:       "from=2021-03-01&to=2021-03-31&interval=day",
+			usageQuery:  &entity.UsageQuery{From: march, To: march.AddDate(0, 1, 0), Interval: entity.IntervalDay},
+			usageResult: []*entity.UsageRow(nil),
+			usageError:  errors.New("[TEST] db down"),
+		},
+		{
+			testcase:  "timeout",
+			isTimeout: true,
+			isError:   true,
+			code:      http.StatusRequestTimeout,
+		},
+	}
+	for _, tc := range testCase {
+		suite.SetupTest()
+		if tc.usageQuery != nil {
+			suite.mockReportRepo.On("GetUsage", tc.usageQuery).Return(tc.usageResult, tc.usageError).Once()
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		if tc.isTimeout {
+			cancel()
+		}
+		req, _ := http.NewRequestWithContext(ctx, "GET", "/reports/usage?"+tc.query, nil)
+		responseRecorder := httptest.NewRecorder()
+
+		code, err := suite.handlerInstance.Usage(responseRecorder, req, httprouter.Params{})
+		cancel()
+
+		suite.Equal(tc.code, code, tc.testcase)
+		if tc.isError {
+			suite.NotNil(err, tc.testcase)
+		} else {
+			suite.Nil(err, tc.testcase)
+		}
+		suite.mockReportRepo.AssertExpectations(suite.T())
+	}
+}
+
+//===============================================ESTIMATES========================================================
+
+func (suite *ReportHandlerTestSuite) TestEstimates() {
+	march := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	rows := []*entity.EstimateRow{{PrintRequestId: 1, ItemName: "Cup Holder", Jobs: 1, EstimatedDuration: 9000, ActualDuration: 10800, EstimatedWeight: 37.5, ActualWeight: 36}}
+	suite.mockJobRepo.On("GetEstimates", &entity.EstimateQuery{From: march, To: march.AddDate(0, 1, 0)}).Return(rows, nil).Once()
+
+	req, _ := http.NewRequest("GET", "/reports/estimates?from=2021-03-01&to=2021-03-31", nil)
+	responseRecorder := httptest.NewRecorder()
+	code, err := suite.handlerInstance.Estimates(responseRecorder, req, httprouter.Params{})
+
+	suite.Equal(http.StatusOK, code)
+	suite.Nil(err)
+	suite.Contains(responseRecorder.Body.String(), `"requests":1,"requests_with_duration":1,"duration_bias":0.2,"duration_mean_absolute_error":0.2`)
+	suite.Contains(responseRecorder.Body.String(), `"weight_error":-0.04`)
+
+	req, _ = http.NewRequest("GET", "/reports/estimates?from=2021-03-31&to=2021-03-01", nil)
+	code, err = suite.handlerInstance.Estimates(httptest.NewRecorder(), req, httprouter.Params{})
+	suite.Equal(http.StatusBadRequest, code)
+	suite.NotNil(err)
+
+	suite.mockJobRepo.On("GetEstimates", &entity.EstimateQuery{From: march, To: march.AddDate(0, 0, 1)}).Return([]*entity.EstimateRow(nil), errors.New("[TEST] db down")).Once()
+	req, _ = http.NewRequest("GET", "/reports/estimates?from=2021-03-01&to=2021-03-01", nil)
+	code, err = suite.handlerInstance.Estimates(httptest.NewRecorder(), req, httprouter.Params{})
+	suite.Equal(http.StatusInternalServerError, code)
+	suite.NotNil(err)
+
+	suite.mockJobRepo.AssertExpectations(suite.T())
+}
+
+func TestReportHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(ReportHandlerTestSuite))
+}
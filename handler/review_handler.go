@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"threedee/entity"
+	print_request "threedee/interfaces/print-request"
+	"threedee/utility/normalizer"
+	"threedee/utility/response"
+	"threedee/utility/review"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type ReviewHandler struct {
+	Repo print_request.PrintRequestRepositoryInterface
+	Norm *normalizer.ReviewNormalizer
+}
+
+// ReviewResponse is a print request with its review thread
+type ReviewResponse struct {
+	*entity.PrintRequest
+	Thread []*entity.ReviewEntry `json:"thread"`
+}
+
+func NewReviewHandler(repo print_request.PrintRequestRepositoryInterface, norm *normalizer.ReviewNormalizer) *ReviewHandler {
+	return &ReviewHandler{repo, norm}
+}
+
+// handle GET /print-requests/:id/review
+func (h *ReviewHandler) Thread(w http.ResponseWriter, r *http.Request, p httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	id, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("id is not a number"))
+	}
+
+	data, err := h.Repo.GetById(id)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+	if data == nil || data.Id == 0 {
+		return http.StatusNotFound, response.WriteNotFoundError(w, errors.New("record not found"))
+	}
+
+	thread, err := h.Repo.GetReviewThread(id)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+
+	return http.StatusOK, response.WriteSuccess(w, ReviewResponse{data, thread}, "success")
+}
+
+// handle PUT /print-requests/:id/review
+//
+// Takes a review action and adds it to the thread. The reviewer approves, rejects or
+// requests changes of a request pending review, the requestor resubmits it after making
+// the changes, see review.Check for who may do what.
+func (h *ReviewHandler) Review(w http.ResponseWriter, r *http.Request, p httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	id, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("id is not a number"))
+	}
+
+	entry, err := h.Norm.ReadAndNormalize(w, r)
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, err)
+	}
+
+	var data *entity.PrintRequest
+	var thread []*entity.ReviewEntry
+	err = h.Repo.WithTransaction(func(repo print_request.PrintRequestRepositoryInterface) error {
+		data, err = repo.GetById(id)
+		if err != nil || data == nil || data.Id == 0 {
+			return err
+		}
+		err = review.Check(data, entry)
+		if err != nil {
+			return err
+		}
+
+		data.Status = entity.ReviewStatuses[entry.Action]
+		_, err = repo.Update(data)
+		if err != nil {
+			return err
+		}
+		entry.PrintRequestId = id
+		_, err = repo.AddReviewEntry(entry)
+		if err != nil {
+			return err
+		}
+		thread, err = repo.GetReviewThread(id)
+		return err
+	})
+	var forbidden *review.ForbiddenError
+	if errors.As(err, &forbidden) {
+		return http.StatusForbidden, response.WriteForbiddenError(w, err)
+	}
+	var conflict *review.ConflictError
+	if errors.As(err, &conflict) {
+		return http.StatusConflict, response.WriteConflictError(w, nil, err)
+	}
+	if err != nil {
+		return writeRepositoryError(w, err)
+	}
+	if data == nil || data.Id == 0 {
+		return http.StatusNotFound, response.WriteNotFoundError(w, errors.New("record not found"))
+	}
+
+	return http.StatusOK, response.WriteSuccess(w, ReviewResponse{data, thread}, "success")
+}
@@ -0,0 +1,162 @@
+package handler_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"threedee/entity"
+	"threedee/handler"
+	"threedee/testdata/mock"
+	"threedee/utility/normalizer"
+
+	"github.com/julienschmidt/httprouter"
+	testifymock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReviewHandlerTestSuite struct {
+	suite.Suite
+	mockPanelRepo   *mock.MockPrintRequestRepository
+	handlerInstance handler.ReviewHandler
+}
+
+func (suite *ReviewHandlerTestSuite) SetupTest() {
+	suite.mockPanelRepo = &mock.MockPrintRequestRepository{}
+	suite.handlerInstance = handler.ReviewHandler{Repo: suite.mockPanelRepo, Norm: normalizer.NewReviewNormalizer()}
+}
+
+//===============================================THREAD========================================================
+
+func (suite *ReviewHandlerTestSuite) TestThread() {
+	thread := []*entity.ReviewEntry{{Id: 1, PrintRequestId: 1, Author: "sari", Action: entity.ReviewRequestChanges, Comment: "Please use 20% infill"}}
+	suite.mockPanelRepo.On("GetById", 1).Return(&entity.PrintRequest{Id: 1, Status: entity.StatusChangesRequested}, nil).Once()
+	suite.mockPanelRepo.On("GetById", 2).Return(&entity.PrintRequest{}, nil).Once()
+	suite.mockPanelRepo.On("GetReviewThread", 1).Return(thread, nil).Once()
+
+	req, _ := http.NewRequest("GET", "/print-requests/1/review", nil)
+	responseRecorder := httptest.NewRecorder()
+	code, err := suite.handlerInstance.Thread(responseRecorder, req, []httprouter.Param{{Key: "id", Value: "1"}})
+
+	suite.Equal(http.StatusOK, code)
+	suite.Nil(err)
+	suite.Contains(responseRecorder.Body.String(), `"thread":[{"id":1,"print_request_id":1,"author":"sari","action":"request_changes"`)
+
+	req, _ = http.NewRequest("GET", "/print-requests/2/review", nil)
+	code, err = suite.handlerInstance.Thread(httptest.NewRecorder(), req, []httprouter.Param{{Key: "id", Value: "2"}})
+
+	suite.Equal(http.StatusNotFound, code)
+	suite.NotNil(err)
+}
+
+//===============================================REVIEW========================================================
+
+func (suite *ReviewHandlerTestSuite) TestReview() {
+	var testCase = []struct {
+		testcase string
+		reqBody  string
+		stored   *entity.PrintRequest
+		isError  bool
+		code     int
+		status   string
+	}{
+		{
+			testcase: "approve",
+			reqBody:  `{"author":"sari","action":"approve"}`,
+			stored:   &entity.PrintRequest{Id: 1, Requestor: "andi", Reviewer: "sari", Status: entity.StatusPendingReview},
+			code:     http.StatusOK,
+			status:   entity.StatusApproved,
+		},
+		{
+			testcase: "request changes",
+			reqBody:  `{"author":"sari","action":"request_changes","comment":"Please use 20% infill"}`,
+			stored:   &entity.PrintRequest{Id: 1, Requestor: "andi", Reviewer: "sari", Status: entity.StatusPendingReview},
+			code:     http.StatusOK,
+			status:   entity.StatusChangesRequested,
+		},
+		{
+			testcase: "resubmit",
+			reqBody:  `{"author":"andi","action":"resubmit"}`,
+			stored:   &entity.PrintRequest{Id: 1, Requestor: "andi", Reviewer: "sari", Status: entity.StatusChangesRequested},
+			code:     http.StatusOK,
+			status:   entity.StatusPendingReview,
+		},
+		{
+			testcase: "reject without comment",
+			reqBody:  `{"author":"sari","action":"reject"}`,
+			isError:  true,
+			code:     http.StatusBadRequest,
+		},
+		{
+			testcase: "unknown action",
+			reqBody:  `{"author":"sari","action":"finish"}`,
+			isError:  true,
+			code:     http.StatusBadRequest,
+		},
+		{
+			testcase: "not the reviewer",
+			reqBody:  `{"author":"tono","action":"approve"}`,
+			stored:   &entity.PrintRequest{Id: 1, Requestor: "andi", Reviewer: "sari", Status: entity.StatusPendingReview},
+			isError:  true,
+			code:     http.StatusForbidden,
+		},
+		{
+			testcase: "already approved",
+			reqBody:  `{"author":"sari","action":"reject","comment":"Too big"}`,
+			stored:   &entity.PrintRequest{Id: 1, Requestor: "andi", Reviewer: "sari", Status: entity.StatusApproved},
+			isError:  true,
+			code:     http.StatusConflict,
+		},
+		{
+			testcase: "not found",
+			reqBody:  `{"author":"sari","action":"approve"}`,
+			stored:   &entity.PrintRequest{},
+			isError:  true,
+			code:     http.StatusNotFound,
+		},
+	}
+	for _, tc := range testCase {
+		suite.SetupTest()
+		if tc.stored != nil {
+			suite.mockPanelRepo.On("GetById", 1).Return(tc.stored, nil).Once()
+		}
+		if tc.status != "" {
+			suite.mockPanelRepo.On("Update", testifymock.MatchedBy(func(model *entity.PrintRequest) bool {
+				return model.Status == tc.status
+			})).Return(true, nil).Once()
+			suite.mockPanelRepo.On("AddReviewEntry", testifymock.AnythingOfType("*entity.ReviewEntry")).Return(1, nil).Once()
+			suite.mockPanelRepo.On("GetReviewThread", 1).Return([]*entity.ReviewEntry{{Id: 1}}, nil).Once()
+		}
+
+		req, _ := http.NewRequest("PUT", "/print-requests/1/review", strings.NewReader(tc.reqBody))
+		req.Header.Add("Content-Type", "application/json")
+		responseRecorder := httptest.NewRecorder()
+		code, err := suite.handlerInstance.Review(responseRecorder, req, []httprouter.Param{{Key: "id", Value: "1"}})
+
+		suite.Equal(tc.code, code, tc.testcase)
+		if tc.isError {
+			suite.NotNil(err, tc.testcase)
+		} else {
+			suite.Nil(err, tc.testcase)
+			suite.Contains(responseRecorder.Body.String(), `"status":"`+tc.status+`"`, tc.testcase)
+		}
+		suite.mockPanelRepo.AssertExpectations(suite.T())
+	}
+}
+
+func (suite *ReviewHandlerTestSuite) TestReviewError() {
+	suite.mockPanelRepo.On("GetById", 1).Return(&entity.PrintRequest{Id: 1, Requestor: "andi", Status: entity.StatusPendingReview}, nil).Once()
+	suite.mockPanelRepo.On("Update", testifymock.Anything).Return(true, nil).Once()
+	suite.mockPanelRepo.On("AddReviewEntry", testifymock.Anything).Return(0, errors.New("[TEST] Failed to insert data")).Once()
+
+	req, _ := http.NewRequest("PUT", "/print-requests/1/review", strings.NewReader(`{"author":"sari","action":"approve"}`))
+	code, err := suite.handlerInstance.Review(httptest.NewRecorder(), req, []httprouter.Param{{Key: "id", Value: "1"}})
+
+	suite.Equal(http.StatusInternalServerError, code)
+	suite.NotNil(err)
+}
+
+func TestReviewHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(ReviewHandlerTestSuite))
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	webhook_subscription "threedee/interfaces/webhook-subscription"
+	"threedee/utility/normalizer"
+	"threedee/utility/response"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type WebhookHandler struct {
+	Repo webhook_subscription.WebhookRepositoryInterface
+	Norm *normalizer.WebhookNormalizer
+}
+
+func NewWebhookHandler(repo webhook_subscription.WebhookRepositoryInterface, norm *normalizer.WebhookNormalizer) *WebhookHandler {
+	return &WebhookHandler{repo, norm}
+}
+
+// handle GET /webhooks
+func (h *WebhookHandler) Index(w http.ResponseWriter, r *http.Request, _ httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	data, err := h.Repo.GetAll()
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+
+	// secrets are write-only
+	for _, item := range data {
+		item.Secret = ""
+	}
+	return http.StatusOK, response.WriteSuccess(w, data, "success")
+}
+
+// handle POST /webhooks
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request, _ httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	model, err := h.Norm.ReadAndNormalize(w, r)
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, err)
+	}
+
+	id, err := h.Repo.Insert(model)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+
+	model.Id = id
+	model.Secret = ""
+	return http.StatusOK, response.WriteSuccess(w, model, "success")
+}
+
+// handle DELETE /webhooks/:id
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request, p httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	id, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("id is not a number"))
+	}
+
+	deleted, err := h.Repo.Delete(id)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+	if !deleted {
+		return http.StatusNotFound, response.WriteNotFoundError(w, errors.New("record not found"))
+	}
+
+	return http.StatusOK, response.WriteSuccess(w, nil, "success")
+}
+
+// handle GET /webhooks/:id/deliveries
+func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request, p httprouter.Params) (int, error) {
+
+	ctx := r.Context()
+	select {
+	case <-ctx.Done():
+		return http.StatusRequestTimeout, ctx.Err()
+	default:
+	}
+
+	id, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		return http.StatusBadRequest, response.WriteBadRequestError(w, errors.New("id is not a number"))
+	}
+
+	sub, err := h.Repo.GetById(id)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+	if sub == nil || sub.Id == 0 {
+		return http.StatusNotFound, response.WriteNotFoundError(w, errors.New("record not found"))
+	}
+
+	data, err := h.Repo.GetDeliveries(id)
+	if err != nil {
+		return http.StatusInternalServerError, response.WriteInternalServerError(w, err)
+	}
+	return http.StatusOK, response.WriteSuccess(w, data, "success")
+}
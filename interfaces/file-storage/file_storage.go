@@ -0,0 +1,22 @@
+package file_storage
+
+import (
+	"errors"
+	"io"
+)
+
+/*
+ * Storage backends only move bytes around by key. Hashing, size limits and signed download
+ * URLs are shared by every backend and live in the storage package (storage/files.go), so
+ * a new backend only has to implement these three methods.
+ *
+ * In threedee, the backends are written in "storage/local.go" and "storage/s3.go".
+ */
+
+var ErrFileNotFound = errors.New("file not found")
+
+type FileStorageInterface interface {
+	Put(key string, r io.Reader, size int64, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
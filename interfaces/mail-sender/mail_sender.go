@@ -0,0 +1,8 @@
+package mail_sender
+
+// In threedee, the SMTP sender is written in "email/smtp.go".
+
+type MailSenderInterface interface {
+	// Send delivers a plain text email to a single recipient
+	Send(to string, subject string, body string) error
+}
@@ -0,0 +1,10 @@
+package notification_preference
+
+import "threedee/entity"
+
+// In threedee, the actual repo code is written in "repository/notification_preference.go".
+
+type NotificationPreferenceRepositoryInterface interface {
+	GetByRequestor(requestor string) (*entity.NotificationPreference, error)
+	Upsert(model *entity.NotificationPreference) error
+}
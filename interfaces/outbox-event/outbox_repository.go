@@ -0,0 +1,27 @@
+package outbox_event
+
+import (
+	"threedee/entity"
+	"time"
+)
+
+// Outbox events are written by the print request repository in the same transaction as
+// the change. This interface is the reading side used by the relay in "outbox/relay.go".
+// In threedee, the actual repo code is written in "repository/outbox.go".
+//
+// Every sink of the relay keeps its own delivery state of an event, so the sinks that
+// delivered it are not called again when another sink fails.
+
+type OutboxRepositoryInterface interface {
+	// GetPending returns the events sink has not delivered yet, oldest first, leaving out
+	// those that used up maxAttempts of sink or are not to be retried before now. Attempts
+	// and LastError of the events are those of sink.
+	GetPending(sink string, limit int, maxAttempts int, now time.Time) ([]*entity.OutboxEvent, error)
+
+	// MarkDelivered records that sink delivered the event. Once sinks sinks did, the event
+	// is delivered and not pending for any sink anymore.
+	MarkDelivered(id int, sink string, sinks int) error
+
+	// MarkFailed records a failed attempt of sink, which retries the event from retryAt
+	MarkFailed(id int, sink string, reason string, retryAt time.Time) error
+}
@@ -0,0 +1,35 @@
+package print_job
+
+import (
+	"strconv"
+	"threedee/entity"
+)
+
+// In threedee, the actual repo code is written in "repository/print_job.go".
+
+// NotFoundError is returned by Update when there is no job with the id
+type NotFoundError struct {
+	Id int
+}
+
+func (e *NotFoundError) Error() string {
+	return "print job " + strconv.Itoa(e.Id) + " not found"
+}
+
+type PrintJobRepositoryInterface interface {
+	// GetByPrintRequest returns the jobs of a request, oldest first
+	GetByPrintRequest(printRequestId int) ([]*entity.PrintJob, error)
+	// GetById returns an empty job, without an id, when there is none
+	GetById(id int) (*entity.PrintJob, error)
+	// Insert adds a job to an active request and returns its id, a
+	// print_request.NotFoundError when the request is missing or deleted
+	Insert(model *entity.PrintJob) (int, error)
+	// Update changes the printer, times, filament and outcome of a job, the request of a job
+	// does not change
+	Update(model *entity.PrintJob) (bool, error)
+
+	// GetEstimates returns the active requests with succeeded jobs that ended in the range of
+	// query, ordered by id, with the sums of those jobs. The errors of the rows are not
+	// computed.
+	GetEstimates(query *entity.EstimateQuery) ([]*entity.EstimateRow, error)
+}
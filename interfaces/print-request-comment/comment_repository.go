@@ -0,0 +1,14 @@
+package print_request_comment
+
+import "threedee/entity"
+
+// In threedee, the actual repo code is written in "repository/comment.go".
+
+type CommentRepositoryInterface interface {
+	// GetByPrintRequest returns the comments of a request with their attachments, oldest
+	// first
+	GetByPrintRequest(printRequestId int) ([]*entity.Comment, error)
+	// Insert adds a comment and its attachments to an active request and returns its id,
+	// a print_request.NotFoundError when the request is missing or deleted
+	Insert(model *entity.Comment) (int, error)
+}
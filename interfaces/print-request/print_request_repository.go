@@ -1,6 +1,10 @@
 package print_request
 
-import "threedee/entity"
+import (
+	"strconv"
+	"threedee/entity"
+	"time"
+)
 
 /*
  * FOURTH LAYER => Repository package and/or Service Package
@@ -19,10 +23,44 @@ import "threedee/entity"
  * follow the interface, we will be notified.
  */
 
+// NotFoundError is returned by Update and Delete when there is no active request with the id
+type NotFoundError struct {
+	Id int
+}
+
+func (e *NotFoundError) Error() string {
+	return "print request " + strconv.Itoa(e.Id) + " not found"
+}
+
 type PrintRequestRepositoryInterface interface {
-	GetAll() ([]*entity.PrintRequest, error)
+	GetAll(filter *entity.PrintRequestFilter) ([]*entity.PrintRequest, error)
+
+	// Each calls fn with the active requests matching filter one at a time, ordered by id,
+	// without loading them all. It stops at the first error of fn and returns it.
+	Each(filter *entity.PrintRequestFilter, fn func(item *entity.PrintRequest) error) error
 	GetById(id int) (*entity.PrintRequest, error)
+	GetActiveByFileHash(hash string, requestor string) ([]*entity.PrintRequest, error)
+
+	// Search finds active requests by the words of query in their item name, requestor and
+	// notes, best match first. See utility/search for how the query is split into terms.
+	Search(query string, limit int) ([]*entity.SearchResult, error)
+
+	// GetQuotaUsage is QuotaRepositoryInterface.GetUsage for a quota check before an Insert
+	// in WithTransaction. In a transaction it locks the requestor, so the check and insert of
+	// a concurrent transaction for the same requestor wait until this one ends.
+	GetQuotaUsage(requestor string, monthStart time.Time, weekStart time.Time) (*entity.QuotaUsage, error)
+
 	Insert(model *entity.PrintRequest) (int, error)
 	Update(model *entity.PrintRequest) (bool, error)
 	Delete(id int) (bool, error)
+
+	// GetReviewThread returns the review entries of a request, oldest first
+	GetReviewThread(printRequestId int) ([]*entity.ReviewEntry, error)
+	// AddReviewEntry appends entry to the review thread of its request, which has to be
+	// active, and returns the entry id
+	AddReviewEntry(entry *entity.ReviewEntry) (int, error)
+
+	// WithTransaction runs fn with a repository whose calls share one transaction, which
+	// is rolled back when fn returns an error and committed otherwise.
+	WithTransaction(fn func(repo PrintRequestRepositoryInterface) error) error
 }
@@ -0,0 +1,9 @@
+package printer
+
+import "threedee/entity"
+
+// In threedee, the actual repo code is written in "repository/printer.go".
+
+type PrinterRepositoryInterface interface {
+	GetAll() ([]*entity.Printer, error)
+}
@@ -0,0 +1,40 @@
+package project
+
+import (
+	"strconv"
+	"threedee/entity"
+)
+
+// In threedee, the actual repo code is written in "repository/project.go".
+
+// NotFoundError is returned by Update and Delete when there is no active project with the id
+type NotFoundError struct {
+	Id int
+}
+
+func (e *NotFoundError) Error() string {
+	return "project " + strconv.Itoa(e.Id) + " not found"
+}
+
+// HasPartsError is returned by Delete when the project still has active print requests
+type HasPartsError struct {
+	Id int
+}
+
+func (e *HasPartsError) Error() string {
+	return "project " + strconv.Itoa(e.Id) + " still has parts, move or delete them first"
+}
+
+type ProjectRepositoryInterface interface {
+	// GetAll returns the active projects ordered by id, with the totals and status of their
+	// parts, the active print requests with their project_id
+	GetAll() ([]*entity.Project, error)
+	// GetById returns an empty project, without an id, when there is no active one
+	GetById(id int) (*entity.Project, error)
+
+	Insert(model *entity.Project) (int, error)
+	Update(model *entity.Project) (bool, error)
+	// Delete checks that the project has no active parts and deletes it in one statement, so
+	// a part added in between can not be left in a deleted project
+	Delete(id int) (bool, error)
+}
@@ -0,0 +1,12 @@
+package report
+
+import "threedee/entity"
+
+// In threedee, the actual repo code is written in "repository/report.go". Reports are
+// aggregated by the database, they never load the print requests themselves.
+
+type ReportRepositoryInterface interface {
+	// GetUsage returns the rows ordered by period, requestor and status. Deleted requests
+	// are not counted.
+	GetUsage(query *entity.UsageQuery) ([]*entity.UsageRow, error)
+}
@@ -0,0 +1,18 @@
+package requestor_quota
+
+import (
+	"threedee/entity"
+	"time"
+)
+
+// In threedee, the actual repo code is written in "repository/quota.go".
+
+type QuotaRepositoryInterface interface {
+	// GetByRequestor returns an empty quota, without a requestor, when none was set
+	GetByRequestor(requestor string) (*entity.Quota, error)
+	Upsert(model *entity.Quota) error
+
+	// GetUsage sums the estimates of the requests of requestor created since monthStart
+	// and since weekStart
+	GetUsage(requestor string, monthStart time.Time, weekStart time.Time) (*entity.QuotaUsage, error)
+}
@@ -0,0 +1,15 @@
+package webhook_subscription
+
+import "threedee/entity"
+
+// In threedee, the actual repo code is written in "repository/webhook.go".
+
+type WebhookRepositoryInterface interface {
+	GetAll() ([]*entity.WebhookSubscription, error)
+	GetById(id int) (*entity.WebhookSubscription, error)
+	GetByEvent(event string) ([]*entity.WebhookSubscription, error)
+	Insert(model *entity.WebhookSubscription) (int, error)
+	Delete(id int) (bool, error)
+	InsertDelivery(model *entity.WebhookDelivery) (int, error)
+	GetDeliveries(subscriptionId int) ([]*entity.WebhookDelivery, error)
+}
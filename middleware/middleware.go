@@ -1,9 +1,11 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"os"
 	"strconv"
+	"threedee/utility/response"
 	"time"
 
 	"github.com/julienschmidt/httprouter"
@@ -51,3 +53,21 @@ func SetupLogger() {
 	// Output to stdout instead of the default stderr
 	log.SetOutput(os.Stdout)
 }
+
+// Static sends a request to the handler registered for the value of the named parameter and
+// to fallback otherwise. httprouter can not register a static route like
+// "/print-requests/events" next to the wildcard "/print-requests/:id", so static routes on
+// the same segment are dispatched from the wildcard route instead.
+func Static(param string, routes map[string]Handler, fallback Handler) Handler {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) (int, error) {
+		if handle, ok := routes[params.ByName(param)]; ok {
+			return handle(w, r, params)
+		}
+		return fallback(w, r, params)
+	}
+}
+
+// NotFound answers 404, the fallback of Static when only some values have a route
+func NotFound(w http.ResponseWriter, r *http.Request, params httprouter.Params) (int, error) {
+	return http.StatusNotFound, response.WriteNotFoundError(w, errors.New("route not found"))
+}
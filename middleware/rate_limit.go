@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"threedee/utility/response"
+	"time"
+)
+
+// RateLimiter is a token bucket per client. Every client starts with Burst tokens, a
+// request takes one and Rate tokens per second flow back up to Burst. A request without a
+// token is answered 429 Too Many Requests with the seconds until the next token in
+// Retry-After.
+type RateLimiter struct {
+	Rate  float64
+	Burst int
+
+	// Key tells the client of a request, the remote IP by default. Threedee has no
+	// authentication yet, so there is no trustworthy principal to key by.
+	Key func(r *http.Request) string
+
+	now     func() time.Time
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		Rate:    rate,
+		Burst:   burst,
+		Key:     RemoteIp,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// RemoteIp is the IP address of the client connection, without the port
+func RemoteIp(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Allow takes a token of key, or returns false and the time until there is one
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(l.Burst), b.tokens+now.Sub(b.last).Seconds()*l.Rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.Rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep forgets, once a minute, the buckets that are full again, which is the same as
+// never having seen the client
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < time.Minute {
+		return
+	}
+	l.swept = now
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.Rate >= float64(l.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Handler limits every request to next
+func (l *RateLimiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ok, retryAfter := l.Allow(l.Key(r))
+		if !ok {
+			response.WriteTooManyRequestsError(w, retryAfter, errors.New("rate limit exceeded"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
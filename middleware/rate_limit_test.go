@@ -0,0 +1,59 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	m "threedee/middleware"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RateLimitTestSuite struct {
+	suite.Suite
+}
+
+func (suite *RateLimitTestSuite) TestHandler() {
+	limiter := m.NewRateLimiter(0.5, 2)
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	var testCase = []struct {
+		testcase   string
+		remoteAddr string
+		code       int
+		retryAfter string
+	}{
+		{testcase: "first", remoteAddr: "10.0.0.1:1234", code: http.StatusNoContent},
+		{testcase: "burst", remoteAddr: "10.0.0.1:1235", code: http.StatusNoContent},
+		{testcase: "limited", remoteAddr: "10.0.0.1:1236", code: http.StatusTooManyRequests, retryAfter: "2"},
+		{testcase: "other client", remoteAddr: "10.0.0.2:1234", code: http.StatusNoContent},
+	}
+	for _, tc := range testCase {
+		req := httptest.NewRequest("GET", "/print-requests", nil)
+		req.RemoteAddr = tc.remoteAddr
+		responseRecorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(responseRecorder, req)
+
+		suite.Equal(tc.code, responseRecorder.Code, tc.testcase)
+		suite.Equal(tc.retryAfter, responseRecorder.Header().Get("Retry-After"), tc.testcase)
+	}
+}
+
+func (suite *RateLimitTestSuite) TestAllow() {
+	limiter := m.NewRateLimiter(1, 1)
+
+	ok, _ := limiter.Allow("andi")
+	suite.True(ok)
+	ok, retryAfter := limiter.Allow("andi")
+	suite.False(ok)
+	suite.InDelta(1, retryAfter.Seconds(), 0.1)
+	ok, _ = limiter.Allow("budi")
+	suite.True(ok)
+}
+
+func TestRateLimitTestSuite(t *testing.T) {
+	suite.Run(t, new(RateLimitTestSuite))
+}
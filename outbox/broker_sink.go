@@ -0,0 +1,38 @@
+package outbox
+
+import (
+	"encoding/json"
+	"threedee/entity"
+	"threedee/sse"
+)
+
+// BrokerSink feeds the in-memory broker behind GET /print-requests/events
+type BrokerSink struct {
+	Broker *sse.Broker
+}
+
+func NewBrokerSink(broker *sse.Broker) *BrokerSink {
+	return &BrokerSink{broker}
+}
+
+func (*BrokerSink) Name() string {
+	return "sse"
+}
+
+func (s *BrokerSink) Publish(event *entity.OutboxEvent) error {
+	// every event payload carries the requestor and status of the print request
+	var fields struct {
+		Requestor string `json:"requestor"`
+		Status    string `json:"status"`
+	}
+	json.Unmarshal(event.Payload, &fields)
+
+	s.Broker.Publish(sse.Event{
+		Id:        event.Id,
+		Type:      event.Event,
+		Requestor: fields.Requestor,
+		Status:    fields.Status,
+		Data:      event.Payload,
+	})
+	return nil
+}
@@ -0,0 +1,66 @@
+package outbox
+
+import (
+	"encoding/json"
+	"log"
+	"net/mail"
+	"threedee/email"
+	"threedee/entity"
+	mail_sender "threedee/interfaces/mail-sender"
+	notification_preference "threedee/interfaces/notification-preference"
+)
+
+// EmailSink emails requestors when their request reaches a status that has an email
+// template. Requestors can opt out or set an address with /notification-preferences, by
+// default the requestor name is used when it is an email address.
+type EmailSink struct {
+	Prefs     notification_preference.NotificationPreferenceRepositoryInterface
+	Sender    mail_sender.MailSenderInterface
+	Templates *email.Templates
+}
+
+func NewEmailSink(prefs notification_preference.NotificationPreferenceRepositoryInterface, sender mail_sender.MailSenderInterface, templates *email.Templates) *EmailSink {
+	return &EmailSink{prefs, sender, templates}
+}
+
+func (*EmailSink) Name() string {
+	return "email"
+}
+
+func (s *EmailSink) Publish(event *entity.OutboxEvent) error {
+	if event.Event != entity.EventStatusChanged {
+		return nil
+	}
+
+	var changed entity.StatusChangedEvent
+	err := json.Unmarshal(event.Payload, &changed)
+	if err != nil {
+		return err
+	}
+	if changed.PrintRequest == nil || !s.Templates.Has(changed.Status) {
+		return nil
+	}
+
+	pref, err := s.Prefs.GetByRequestor(changed.Requestor)
+	if err != nil {
+		return err
+	}
+	if pref.OptOut {
+		return nil
+	}
+
+	to := pref.Email
+	if to == "" {
+		to = changed.Requestor
+	}
+	if _, err := mail.ParseAddress(to); err != nil {
+		log.Printf("outbox: no email address for requestor %q, skipping event %d", changed.Requestor, event.Id)
+		return nil
+	}
+
+	subject, body, err := s.Templates.Render(&changed)
+	if err != nil {
+		return err
+	}
+	return s.Sender.Send(to, subject, body)
+}
@@ -0,0 +1,138 @@
+package outbox_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"threedee/email"
+	"threedee/entity"
+	"threedee/outbox"
+	"threedee/testdata/mock"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type sentMail struct {
+	to      string
+	subject string
+	body    string
+}
+
+type recordingSender struct {
+	sent []sentMail
+}
+
+func (s *recordingSender) Send(to string, subject string, body string) error {
+	s.sent = append(s.sent, sentMail{to, subject, body})
+	return nil
+}
+
+type EmailSinkTestSuite struct {
+	suite.Suite
+	mockPrefsRepo *mock.MockNotificationPreferenceRepository
+	sender        *recordingSender
+	sink          *outbox.EmailSink
+}
+
+func (suite *EmailSinkTestSuite) SetupTest() {
+	templates, err := email.NewTemplates()
+	suite.Require().Nil(err)
+	suite.mockPrefsRepo = &mock.MockNotificationPreferenceRepository{}
+	suite.sender = &recordingSender{}
+	suite.sink = outbox.NewEmailSink(suite.mockPrefsRepo, suite.sender, templates)
+}
+
+func statusChanged(requestor string, status string) *entity.OutboxEvent {
+	payload, _ := json.Marshal(&entity.StatusChangedEvent{
+		PrintRequest: &entity.PrintRequest{
+			Id:                7,
+			ItemName:          "Phone Holder",
+			EstimatedDuration: 9000,
+			Requestor:         requestor,
+			Status:            status,
+		},
+		PreviousStatus: entity.StatusReceived,
+	})
+	return &entity.OutboxEvent{Id: 1, Event: entity.EventStatusChanged, Payload: payload}
+}
+
+func (suite *EmailSinkTestSuite) TestPublish() {
+	var testCase = []struct {
+		testcase  string
+		event     *entity.OutboxEvent
+		pref      *entity.NotificationPreference
+		prefError error
+		isError   bool
+		to        string
+		subject   string
+	}{
+		{
+			testcase: "requestor is an address",
+			event:    statusChanged("andi@example.com", entity.StatusFinished),
+			pref:     entity.NewNotificationPreference(),
+			to:       "andi@example.com",
+			subject:  `Your print "Phone Holder" is ready`,
+		},
+		{
+			testcase: "preferred address",
+			event:    statusChanged("Andi", entity.StatusApproved),
+			pref:     &entity.NotificationPreference{Requestor: "Andi", Email: "andi@example.com"},
+			to:       "andi@example.com",
+			subject:  `Your print request "Phone Holder" was approved`,
+		},
+		{
+			testcase: "opted out",
+			event:    statusChanged("andi@example.com", entity.StatusRejected),
+			pref:     &entity.NotificationPreference{Requestor: "andi@example.com", OptOut: true},
+		},
+		{
+			testcase: "no address",
+			event:    statusChanged("Andi", entity.StatusFailed),
+			pref:     entity.NewNotificationPreference(),
+		},
+		{
+			testcase: "status without template",
+			event:    statusChanged("andi@example.com", entity.StatusProcessed),
+		},
+		{
+			testcase: "other event",
+			event:    &entity.OutboxEvent{Id: 1, Event: entity.EventCreated, Payload: json.RawMessage(`{}`)},
+		},
+		{
+			testcase:  "preference lookup fails",
+			event:     statusChanged("budi@example.com", entity.StatusFinished),
+			pref:      entity.NewNotificationPreference(),
+			prefError: errors.New("[TEST] connection refused"),
+			isError:   true,
+		},
+	}
+	for _, tc := range testCase {
+		suite.sender.sent = nil
+		var changed entity.StatusChangedEvent
+		json.Unmarshal(tc.event.Payload, &changed)
+		if tc.pref != nil {
+			suite.mockPrefsRepo.On("GetByRequestor", changed.Requestor).Return(tc.pref, tc.prefError).Once()
+		}
+
+		err := suite.sink.Publish(tc.event)
+
+		if tc.isError {
+			suite.NotNil(err, tc.testcase)
+		} else {
+			suite.Nil(err, tc.testcase)
+		}
+		if tc.to == "" {
+			suite.Len(suite.sender.sent, 0, tc.testcase)
+			continue
+		}
+		suite.Require().Len(suite.sender.sent, 1, tc.testcase)
+		suite.Equal(tc.to, suite.sender.sent[0].to, tc.testcase)
+		suite.Equal(tc.subject, suite.sender.sent[0].subject, tc.testcase)
+		suite.Contains(suite.sender.sent[0].body, "#7 \"Phone Holder\"", tc.testcase)
+	}
+	suite.mockPrefsRepo.AssertExpectations(suite.T())
+}
+
+func TestEmailSinkTestSuite(t *testing.T) {
+	suite.Run(t, new(EmailSinkTestSuite))
+}
@@ -0,0 +1,29 @@
+package outbox
+
+import (
+	"threedee/entity"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// LogSink writes every event to the service log
+type LogSink struct {
+}
+
+func NewLogSink() *LogSink {
+	return &LogSink{}
+}
+
+func (*LogSink) Name() string {
+	return "log"
+}
+
+func (*LogSink) Publish(event *entity.OutboxEvent) error {
+	log.WithFields(log.Fields{
+		"outbox_id":    event.Id,
+		"event":        event.Event,
+		"aggregate_id": event.AggregateId,
+		"payload":      string(event.Payload),
+	}).Info("print request event")
+	return nil
+}
@@ -0,0 +1,117 @@
+package outbox_test
+
+import (
+	"encoding/json"
+	"testing"
+	"threedee/entity"
+	"threedee/outbox"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/suite"
+)
+
+/*
+ * The sink is tested against an embedded nats-server. The threedee user may only publish
+ * print request status changes, so a created event is a permissions violation.
+ */
+
+type NatsSinkTestSuite struct {
+	suite.Suite
+	server *server.Server
+	sink   *outbox.NatsSink
+}
+
+func (suite *NatsSinkTestSuite) SetupTest() {
+	s, err := server.NewServer(&server.Options{
+		Host:   "127.0.0.1",
+		Port:   server.RANDOM_PORT,
+		NoLog:  true,
+		NoSigs: true,
+		Users: []*server.User{
+			{Username: "threedee", Password: "s3cret", Permissions: &server.Permissions{
+				Publish: &server.SubjectPermission{Allow: []string{"threedee.print_request.status_changed"}},
+			}},
+			{Username: "consumer", Password: "s3cret"},
+		},
+	})
+	suite.Require().Nil(err)
+	go s.Start()
+	suite.Require().True(s.ReadyForConnections(5 * time.Second))
+	suite.server = s
+	suite.sink = outbox.NewNatsSink(suite.url("threedee"), "")
+	suite.sink.Timeout = time.Second
+}
+
+func (suite *NatsSinkTestSuite) TearDownTest() {
+	suite.sink.Close()
+	suite.server.Shutdown()
+}
+
+func (suite *NatsSinkTestSuite) url(user string) string {
+	return "nats://" + user + ":s3cret@" + suite.server.Addr().String()
+}
+
+func (suite *NatsSinkTestSuite) TestPublish() {
+	consumer, err := nats.Connect(suite.url("consumer"))
+	suite.Require().Nil(err)
+	defer consumer.Close()
+	messages := make(chan *nats.Msg, 2)
+	_, err = consumer.ChanSubscribe("threedee.print_request.>", messages)
+	suite.Require().Nil(err)
+	suite.Require().Nil(consumer.Flush())
+
+	event := &entity.OutboxEvent{Id: 7, Event: entity.EventStatusChanged, AggregateId: 3, Payload: json.RawMessage(`{"id":3,"status":"finished"}`)}
+
+	suite.Nil(suite.sink.Publish(event))
+	suite.Nil(suite.sink.Publish(event))
+
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-messages:
+			suite.Equal("threedee.print_request.status_changed", msg.Subject)
+			var message outbox.Message
+			suite.Nil(json.Unmarshal(msg.Data, &message))
+			suite.Equal(7, message.Id)
+			suite.Equal(3, message.AggregateId)
+			suite.JSONEq(`{"id":3,"status":"finished"}`, string(message.Data))
+		case <-time.After(time.Second):
+			suite.Fail("message not received")
+		}
+	}
+	suite.Equal(2, suite.server.NumClients(), "the sink keeps one connection next to the consumer")
+}
+
+func (suite *NatsSinkTestSuite) TestPublishRejected() {
+	created := &entity.OutboxEvent{Id: 1, Event: entity.EventCreated, Payload: json.RawMessage(`{}`)}
+
+	err := suite.sink.Publish(created)
+
+	suite.NotNil(err)
+	suite.Contains(err.Error(), "Permissions Violation")
+	suite.Nil(suite.sink.Publish(&entity.OutboxEvent{Id: 2, Event: entity.EventStatusChanged, Payload: json.RawMessage(`{}`)}))
+	suite.NotNil(suite.sink.Publish(created), "every rejected event fails")
+}
+
+func (suite *NatsSinkTestSuite) TestPublishWrongPassword() {
+	sink := outbox.NewNatsSink("nats://threedee:wrong@"+suite.server.Addr().String(), "")
+
+	err := sink.Publish(&entity.OutboxEvent{Id: 1, Event: entity.EventStatusChanged, Payload: json.RawMessage(`{}`)})
+
+	suite.NotNil(err)
+}
+
+func (suite *NatsSinkTestSuite) TestPublishServerDown() {
+	url := suite.url("threedee")
+	suite.server.Shutdown()
+	sink := outbox.NewNatsSink(url, "")
+
+	err := sink.Publish(&entity.OutboxEvent{Id: 1, Event: entity.EventCreated, Payload: json.RawMessage(`{}`)})
+
+	suite.NotNil(err)
+}
+
+func TestNatsSinkTestSuite(t *testing.T) {
+	suite.Run(t, new(NatsSinkTestSuite))
+}
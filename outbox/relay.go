@@ -0,0 +1,139 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+	"threedee/entity"
+	outbox_event "threedee/interfaces/outbox-event"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+ * Outbox Relay
+ *
+ * The print request repository writes an outbox event in the same transaction as every
+ * change. The relay hands the pending events to every sink in order and records per sink
+ * which events it delivered, so a failing sink only retries itself and the others do not
+ * publish the event again. The event is delivered once all sinks accepted it.
+ *
+ * Every sink runs on its own, so a slow or unreachable one does not hold up the others. An
+ * event a sink failed is retried by that sink after RetryDelay, doubling with every attempt
+ * up to MaxRetryDelay, until it used up MaxAttempts. It then stays in tbl_t_outbox with the
+ * last_error of the sink for someone to look at.
+ *
+ * Delivery is at-least-once: a sink can fail after it published, e.g. when a webhook
+ * subscriber got the event but another one did not. Sinks should pass the event id on so
+ * consumers can deduplicate.
+ */
+
+// Sink publishes outbox events somewhere, e.g. a log, webhooks or a message broker
+type Sink interface {
+	Name() string
+	Publish(event *entity.OutboxEvent) error
+}
+
+type Relay struct {
+	Repo          outbox_event.OutboxRepositoryInterface
+	Sinks         []Sink
+	Interval      time.Duration
+	BatchSize     int
+	MaxAttempts   int
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+}
+
+func NewRelay(repo outbox_event.OutboxRepositoryInterface, sinks ...Sink) *Relay {
+	return &Relay{
+		Repo:          repo,
+		Sinks:         sinks,
+		Interval:      2 * time.Second,
+		BatchSize:     100,
+		MaxAttempts:   10,
+		RetryDelay:    5 * time.Second,
+		MaxRetryDelay: 10 * time.Minute,
+	}
+}
+
+// Run relays the pending events of every sink every Interval until ctx is cancelled
+func (r *Relay) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, sink := range r.Sinks {
+		wg.Add(1)
+		go func(sink Sink) {
+			defer wg.Done()
+			r.run(ctx, sink)
+		}(sink)
+	}
+	wg.Wait()
+}
+
+func (r *Relay) run(ctx context.Context, sink Sink) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := r.RelaySink(sink, time.Now())
+		if err != nil {
+			log.WithField("sink", sink.Name()).Warning("outbox: " + err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayPending publishes one batch of pending events to every sink, one sink after the
+// other, and returns how many deliveries there were
+func (r *Relay) RelayPending() (int, error) {
+	delivered := 0
+	for _, sink := range r.Sinks {
+		n, err := r.RelaySink(sink, time.Now())
+		delivered += n
+		if err != nil {
+			return delivered, err
+		}
+	}
+	return delivered, nil
+}
+
+// RelaySink publishes one batch of the events that are pending for sink at now and returns
+// how many it delivered
+func (r *Relay) RelaySink(sink Sink, now time.Time) (int, error) {
+	events, err := r.Repo.GetPending(sink.Name(), r.BatchSize, r.MaxAttempts, now)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, event := range events {
+		err := sink.Publish(event)
+		if err != nil {
+			log.WithFields(log.Fields{"outbox_id": event.Id, "event": event.Event, "sink": sink.Name()}).Warning("outbox: " + err.Error())
+			err = r.Repo.MarkFailed(event.Id, sink.Name(), err.Error(), now.Add(r.retryDelay(event.Attempts+1)))
+		} else {
+			delivered++
+			err = r.Repo.MarkDelivered(event.Id, sink.Name(), len(r.Sinks))
+		}
+		if err != nil {
+			return delivered, err
+		}
+	}
+	return delivered, nil
+}
+
+// retryDelay is how long a sink waits before it retries an event it failed attempts times
+func (r *Relay) retryDelay(attempts int) time.Duration {
+	delay := r.RetryDelay
+	for i := 1; i < attempts && delay < r.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > r.MaxRetryDelay {
+		return r.MaxRetryDelay
+	}
+	return delay
+}
@@ -0,0 +1,105 @@
+package outbox_test
+
+import (
+	"errors"
+	"testing"
+	"threedee/entity"
+	"threedee/outbox"
+	"threedee/testdata/mock"
+	"time"
+
+	testifymock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type recordingSink struct {
+	name   string
+	fail   map[int]bool
+	events []int
+}
+
+func (s *recordingSink) Name() string {
+	return s.name
+}
+
+func (s *recordingSink) Publish(event *entity.OutboxEvent) error {
+	if s.fail[event.Id] {
+		return errors.New("unavailable")
+	}
+	s.events = append(s.events, event.Id)
+	return nil
+}
+
+type RelayTestSuite struct {
+	suite.Suite
+	mockOutboxRepo *mock.MockOutboxRepository
+}
+
+func (suite *RelayTestSuite) SetupTest() {
+	suite.mockOutboxRepo = &mock.MockOutboxRepository{}
+}
+
+func (suite *RelayTestSuite) TestRelayPending() {
+	pending := []*entity.OutboxEvent{
+		{Id: 1, Event: entity.EventCreated},
+		{Id: 2, Event: entity.EventStatusChanged},
+		{Id: 3, Event: entity.EventDeleted},
+	}
+	logSink := &recordingSink{name: "log"}
+	natsSink := &recordingSink{name: "nats", fail: map[int]bool{2: true}}
+	relay := outbox.NewRelay(suite.mockOutboxRepo, logSink, natsSink)
+
+	now := testifymock.AnythingOfType("time.Time")
+	suite.mockOutboxRepo.On("GetPending", "log", relay.BatchSize, relay.MaxAttempts, now).Return(pending, nil).Once()
+	suite.mockOutboxRepo.On("GetPending", "nats", relay.BatchSize, relay.MaxAttempts, now).Return(pending, nil).Once()
+	for _, id := range []int{1, 2, 3} {
+		suite.mockOutboxRepo.On("MarkDelivered", id, "log", 2).Return(nil).Once()
+	}
+	suite.mockOutboxRepo.On("MarkDelivered", 1, "nats", 2).Return(nil).Once()
+	suite.mockOutboxRepo.On("MarkFailed", 2, "nats", "unavailable", now).Return(nil).Once()
+	suite.mockOutboxRepo.On("MarkDelivered", 3, "nats", 2).Return(nil).Once()
+
+	delivered, err := relay.RelayPending()
+
+	suite.Nil(err)
+	suite.Equal(5, delivered)
+	suite.Equal([]int{1, 2, 3}, logSink.events)
+	suite.Equal([]int{1, 3}, natsSink.events)
+	suite.mockOutboxRepo.AssertExpectations(suite.T())
+}
+
+func (suite *RelayTestSuite) TestRelaySinkRetryDelay() {
+	natsSink := &recordingSink{name: "nats", fail: map[int]bool{1: true, 2: true, 3: true}}
+	relay := outbox.NewRelay(suite.mockOutboxRepo, natsSink)
+	relay.RetryDelay = time.Second
+	relay.MaxRetryDelay = 5 * time.Second
+	now := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	suite.mockOutboxRepo.On("GetPending", "nats", relay.BatchSize, relay.MaxAttempts, now).Return([]*entity.OutboxEvent{
+		{Id: 1, Event: entity.EventCreated},
+		{Id: 2, Event: entity.EventCreated, Attempts: 2},
+		{Id: 3, Event: entity.EventCreated, Attempts: 8},
+	}, nil).Once()
+	suite.mockOutboxRepo.On("MarkFailed", 1, "nats", "unavailable", now.Add(time.Second)).Return(nil).Once()
+	suite.mockOutboxRepo.On("MarkFailed", 2, "nats", "unavailable", now.Add(4*time.Second)).Return(nil).Once()
+	suite.mockOutboxRepo.On("MarkFailed", 3, "nats", "unavailable", now.Add(5*time.Second)).Return(nil).Once()
+
+	delivered, err := relay.RelaySink(natsSink, now)
+
+	suite.Nil(err)
+	suite.Equal(0, delivered)
+	suite.mockOutboxRepo.AssertExpectations(suite.T())
+}
+
+func (suite *RelayTestSuite) TestRelayPendingError() {
+	relay := outbox.NewRelay(suite.mockOutboxRepo, &recordingSink{name: "log"})
+	suite.mockOutboxRepo.On("GetPending", "log", relay.BatchSize, relay.MaxAttempts, testifymock.AnythingOfType("time.Time")).Return([]*entity.OutboxEvent(nil), errors.New("[TEST] db down")).Once()
+
+	_, err := relay.RelayPending()
+
+	suite.NotNil(err)
+}
+
+func TestRelayTestSuite(t *testing.T) {
+	suite.Run(t, new(RelayTestSuite))
+}
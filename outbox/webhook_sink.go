@@ -0,0 +1,24 @@
+package outbox
+
+import (
+	"threedee/entity"
+	"threedee/webhook"
+)
+
+// WebhookSink delivers events to the webhook subscriptions of their event type, one attempt
+// per publish. Failed events are retried by the relay.
+type WebhookSink struct {
+	Dispatcher *webhook.Dispatcher
+}
+
+func NewWebhookSink(dispatcher *webhook.Dispatcher) *WebhookSink {
+	return &WebhookSink{dispatcher}
+}
+
+func (*WebhookSink) Name() string {
+	return "webhook"
+}
+
+func (s *WebhookSink) Publish(event *entity.OutboxEvent) error {
+	return s.Dispatcher.Dispatch(event.Id, event.Event, event.CreatedOn, event.Payload, event.Attempts+1)
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"database/sql"
+	"threedee/database"
+	"threedee/entity"
+	print_request "threedee/interfaces/print-request"
+)
+
+type CommentRepository struct {
+}
+
+func NewCommentRepository() *CommentRepository {
+	return &CommentRepository{}
+}
+
+func (*CommentRepository) GetByPrintRequest(printRequestId int) ([]*entity.Comment, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return queryComments(db, printRequestId)
+}
+
+func (*CommentRepository) Insert(model *entity.Comment) (int, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	var id int
+	err = runTransaction(db, func(tx *sql.Tx) error {
+		id, err = insertComment(tx, model)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// The comment queries work for Postgres and SQLite
+const (
+	commentsQuery = "select " +
+		"a.id," +
+		"a.print_request_id," +
+		"a.author," +
+		"a.body," +
+		"a.created_on " +
+		"from tbl_t_comment a where a.print_request_id = $1 order by a.id"
+
+	attachmentsQuery = "select " +
+		"b.comment_id," +
+		"b.file_key," +
+		"b.filename," +
+		"b.content_type," +
+		"b.size " +
+		"from tbl_t_comment_attachment b join tbl_t_comment a on a.id = b.comment_id " +
+		"where a.print_request_id = $1 order by b.id"
+
+	// inserts nothing when the request is not active
+	insertCommentQuery = "INSERT INTO tbl_t_comment(print_request_id, author, body) " +
+		"SELECT a.id, $2, $3 FROM tbl_m_3d_print_request a WHERE a.id = $1 AND a.is_active = true " +
+		"RETURNING id;"
+
+	insertAttachmentQuery = "INSERT INTO tbl_t_comment_attachment(" +
+		"comment_id," +
+		"file_key," +
+		"filename," +
+		"content_type," +
+		"size) " +
+		"VALUES ($1, $2, $3, $4, $5);"
+)
+
+// queryComments reads the comments of a request and then their attachments
+func queryComments(db querier, printRequestId int) ([]*entity.Comment, error) {
+	rows, err := db.Query(commentsQuery, printRequestId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*entity.Comment, 0)
+	byId := make(map[int]*entity.Comment)
+	for rows.Next() {
+		item := entity.NewComment()
+		err := rows.Scan(
+			&item.Id,
+			&item.PrintRequestId,
+			&item.Author,
+			&item.Body,
+			&item.CreatedOn,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+		byId[item.Id] = item
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	attachments, err := db.Query(attachmentsQuery, printRequestId)
+	if err != nil {
+		return nil, err
+	}
+	defer attachments.Close()
+
+	for attachments.Next() {
+		var commentId int
+		item := entity.NewAttachment()
+		err := attachments.Scan(
+			&commentId,
+			&item.Key,
+			&item.Filename,
+			&item.ContentType,
+			&item.Size,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if comment, ok := byId[commentId]; ok {
+			comment.Attachments = append(comment.Attachments, item)
+		}
+	}
+	err = attachments.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// insertComment adds model and its attachments in tx
+func insertComment(tx *sql.Tx, model *entity.Comment) (int, error) {
+	var id int
+	err := tx.QueryRow(insertCommentQuery,
+		model.PrintRequestId,
+		model.Author,
+		model.Body).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, &print_request.NotFoundError{Id: model.PrintRequestId}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	for _, attachment := range model.Attachments {
+		_, err = tx.Exec(insertAttachmentQuery,
+			id,
+			attachment.Key,
+			attachment.Filename,
+			attachment.ContentType,
+			attachment.Size)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return id, nil
+}
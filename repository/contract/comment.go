@@ -0,0 +1,85 @@
+package contract
+
+import (
+	"errors"
+	"testing"
+	"threedee/entity"
+	print_request "threedee/interfaces/print-request"
+	print_request_comment "threedee/interfaces/print-request-comment"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+/*
+ * CommentSuite is the contract of CommentRepositoryInterface:
+ *
+ * - comments are listed per request ordered by id, each with its attachments in the order
+ *   they were added
+ * - comments can only be added to active requests, others give a print_request.NotFoundError
+ */
+
+type CommentSuite struct {
+	suite.Suite
+
+	// New returns an empty print request repository and the comment repository on it
+	New func(t *testing.T) (print_request.PrintRequestRepositoryInterface, print_request_comment.CommentRepositoryInterface)
+
+	requests print_request.PrintRequestRepositoryInterface
+	repo     print_request_comment.CommentRepositoryInterface
+}
+
+func (s *CommentSuite) SetupTest() {
+	s.requests, s.repo = s.New(s.T())
+}
+
+func newAttachment(key string, filename string) *entity.Attachment {
+	return &entity.Attachment{Key: key, Filename: filename, ContentType: "image/png", Size: 2048}
+}
+
+func (s *CommentSuite) TestInsert() {
+	id, _ := s.requests.Insert(newPrintRequest("Cup Holder", "andi"))
+	other, _ := s.requests.Insert(newPrintRequest("Phone Holder", "budi"))
+
+	comments := []*entity.Comment{
+		{PrintRequestId: id, Author: "andi", Body: "Red please, 20% infill", Attachments: []*entity.Attachment{
+			newAttachment("aaaa.png", "front.png"),
+			newAttachment("bbbb.png", "side.png"),
+		}},
+		{PrintRequestId: other, Author: "budi", Body: "Any color"},
+		{PrintRequestId: id, Author: "sari", Body: "Printing it lying down"},
+	}
+	for _, comment := range comments {
+		commentId, err := s.repo.Insert(comment)
+		s.Nil(err)
+		s.Greater(commentId, 0)
+	}
+
+	stored, err := s.repo.GetByPrintRequest(id)
+
+	s.Nil(err)
+	s.Require().Len(stored, 2)
+	s.Less(stored[0].Id, stored[1].Id)
+	s.Equal(id, stored[0].PrintRequestId)
+	s.Equal("andi", stored[0].Author)
+	s.Equal("Red please, 20% infill", stored[0].Body)
+	s.WithinDuration(time.Now(), stored[0].CreatedOn, time.Minute)
+	s.Equal([]*entity.Attachment{newAttachment("aaaa.png", "front.png"), newAttachment("bbbb.png", "side.png")}, stored[0].Attachments)
+	s.Equal("sari", stored[1].Author)
+	s.Equal([]*entity.Attachment{}, stored[1].Attachments)
+
+	empty, err := s.repo.GetByPrintRequest(100)
+	s.Nil(err)
+	s.Len(empty, 0)
+}
+
+func (s *CommentSuite) TestInsertNotFound() {
+	id, _ := s.requests.Insert(newPrintRequest("Cup Holder", "andi"))
+	s.requests.Delete(id)
+
+	var notFound *print_request.NotFoundError
+	_, err := s.repo.Insert(&entity.Comment{PrintRequestId: id, Author: "andi", Body: "Still there?"})
+	s.True(errors.As(err, &notFound))
+	_, err = s.repo.Insert(&entity.Comment{PrintRequestId: 100, Author: "andi", Body: "Hello"})
+	s.True(errors.As(err, &notFound))
+}
@@ -0,0 +1,88 @@
+package contract
+
+import (
+	"testing"
+	"threedee/entity"
+	outbox_event "threedee/interfaces/outbox-event"
+	print_request "threedee/interfaces/print-request"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+/*
+ * OutboxSuite is the contract of OutboxRepositoryInterface:
+ *
+ * - every sink has its own pending events, what one sink did does not change those of
+ *   another, and Attempts and LastError are those of the sink
+ * - a failed event is pending again from its retry time until it used up the attempts
+ * - once all sinks delivered an event it is not pending for any sink, not even a new one
+ */
+
+type OutboxSuite struct {
+	suite.Suite
+
+	// New returns an empty print request repository and the outbox it writes its events to
+	New func(t *testing.T) (print_request.PrintRequestRepositoryInterface, outbox_event.OutboxRepositoryInterface)
+
+	requests print_request.PrintRequestRepositoryInterface
+	repo     outbox_event.OutboxRepositoryInterface
+}
+
+func (s *OutboxSuite) SetupTest() {
+	s.requests, s.repo = s.New(s.T())
+}
+
+// pending returns the ids of the events pending for sink at now, oldest first
+func (s *OutboxSuite) pending(sink string, now time.Time) []int {
+	events, err := s.repo.GetPending(sink, 100, 3, now)
+	s.Require().Nil(err)
+
+	result := make([]int, 0)
+	for _, e := range events {
+		result = append(result, e.Id)
+	}
+	return result
+}
+
+func (s *OutboxSuite) TestPerSink() {
+	s.requests.Insert(newPrintRequest("Cup Holder", "andi"))
+	s.requests.Insert(newPrintRequest("Phone Holder", "budi"))
+	now := time.Now()
+	events, _ := s.repo.GetPending("log", 100, 3, now)
+	s.Require().Len(events, 2)
+	first, second := events[0].Id, events[1].Id
+
+	s.Nil(s.repo.MarkDelivered(first, "log", 2))
+	s.Nil(s.repo.MarkFailed(first, "nats", "unavailable", now.Add(time.Minute)))
+
+	s.Equal([]int{second}, s.pending("log", now))
+	s.Equal([]int{second}, s.pending("nats", now), "a failed event waits for its retry")
+	s.Equal([]int{first, second}, s.pending("nats", now.Add(2*time.Minute)))
+
+	retry, err := s.repo.GetPending("nats", 1, 3, now.Add(2*time.Minute))
+	s.Nil(err)
+	s.Require().Len(retry, 1)
+	s.Equal(1, retry[0].Attempts)
+	s.Equal("unavailable", retry[0].LastError)
+	s.Equal(entity.EventCreated, retry[0].Event)
+
+	s.Nil(s.repo.MarkDelivered(first, "nats", 2))
+	s.Equal([]int{second}, s.pending("nats", now.Add(2*time.Minute)))
+	s.Equal([]int{second}, s.pending("sse", now), "an event all sinks delivered is done")
+}
+
+func (s *OutboxSuite) TestMaxAttempts() {
+	s.requests.Insert(newPrintRequest("Cup Holder", "andi"))
+	now := time.Now()
+	events, _ := s.repo.GetPending("nats", 100, 3, now)
+	s.Require().Len(events, 1)
+	id := events[0].Id
+
+	for i := 0; i < 3; i++ {
+		s.Nil(s.repo.MarkFailed(id, "nats", "unavailable", now))
+	}
+
+	s.Len(s.pending("nats", now.Add(time.Hour)), 0)
+	s.Equal([]int{id}, s.pending("log", now))
+}
@@ -0,0 +1,177 @@
+package contract
+
+import (
+	"errors"
+	"testing"
+	"threedee/entity"
+	print_job "threedee/interfaces/print-job"
+	print_request "threedee/interfaces/print-request"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+/*
+ * PrintJobSuite is the contract of PrintJobRepositoryInterface:
+ *
+ * - jobs are listed per request ordered by id, GetById returns an empty job when there is
+ *   none, and times come back as they went in, EndedOn stays nil while printing
+ * - jobs can only be added to active requests, others give a print_request.NotFoundError,
+ *   and Update of a missing job gives a print_job.NotFoundError
+ * - GetEstimates sums the succeeded jobs that ended in the range per active request
+ */
+
+type PrintJobSuite struct {
+	suite.Suite
+
+	// New returns an empty print request repository and the print job repository on it
+	New func(t *testing.T) (print_request.PrintRequestRepositoryInterface, print_job.PrintJobRepositoryInterface)
+
+	requests print_request.PrintRequestRepositoryInterface
+	repo     print_job.PrintJobRepositoryInterface
+}
+
+func (s *PrintJobSuite) SetupTest() {
+	s.requests, s.repo = s.New(s.T())
+}
+
+// newPrintJob returns a job of printRequestId started on startedOn, ended after duration
+// with outcome unless it is printing
+func newPrintJob(printRequestId int, startedOn time.Time, duration time.Duration, outcome string) *entity.PrintJob {
+	job := &entity.PrintJob{
+		PrintRequestId: printRequestId,
+		PrinterId:      2,
+		StartedOn:      startedOn,
+		Outcome:        outcome,
+	}
+	if outcome != entity.JobPrinting {
+		endedOn := startedOn.Add(duration)
+		job.EndedOn = &endedOn
+		job.Duration = int(duration.Seconds())
+		job.FilamentUsed = 40.5
+	}
+	return job
+}
+
+func (s *PrintJobSuite) TestInsert() {
+	id, _ := s.requests.Insert(newPrintRequest("Cup Holder", "andi"))
+	other, _ := s.requests.Insert(newPrintRequest("Phone Holder", "budi"))
+	started := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	failed := newPrintJob(id, started, 30*time.Minute, entity.JobFailed)
+	failed.FailureReason = "Spaghetti after layer 12"
+	jobs := []*entity.PrintJob{
+		failed,
+		newPrintJob(other, started, 0, entity.JobPrinting),
+		newPrintJob(id, started.Add(time.Hour), 0, entity.JobPrinting),
+	}
+	for _, job := range jobs {
+		jobId, err := s.repo.Insert(job)
+		s.Nil(err)
+		s.Greater(jobId, 0)
+	}
+
+	stored, err := s.repo.GetByPrintRequest(id)
+
+	s.Nil(err)
+	s.Require().Len(stored, 2)
+	s.Less(stored[0].Id, stored[1].Id)
+	s.Equal(id, stored[0].PrintRequestId)
+	s.Equal(2, stored[0].PrinterId)
+	s.True(started.Equal(stored[0].StartedOn))
+	s.Require().NotNil(stored[0].EndedOn)
+	s.True(started.Add(30 * time.Minute).Equal(*stored[0].EndedOn))
+	s.Equal(1800, stored[0].Duration)
+	s.Equal(float32(40.5), stored[0].FilamentUsed)
+	s.Equal(entity.JobFailed, stored[0].Outcome)
+	s.Equal("Spaghetti after layer 12", stored[0].FailureReason)
+	s.WithinDuration(time.Now(), stored[0].CreatedOn, time.Minute)
+	s.Equal(entity.JobPrinting, stored[1].Outcome)
+	s.Nil(stored[1].EndedOn)
+
+	job, err := s.repo.GetById(stored[1].Id)
+	s.Nil(err)
+	s.Equal(stored[1], job)
+
+	missing, err := s.repo.GetById(100)
+	s.Nil(err)
+	s.Equal(0, missing.Id)
+	empty, err := s.repo.GetByPrintRequest(100)
+	s.Nil(err)
+	s.Len(empty, 0)
+}
+
+func (s *PrintJobSuite) TestUpdate() {
+	id, _ := s.requests.Insert(newPrintRequest("Cup Holder", "andi"))
+	started := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+	jobId, _ := s.repo.Insert(newPrintJob(id, started, 0, entity.JobPrinting))
+
+	ended := newPrintJob(0, started, 150*time.Minute, entity.JobSucceeded)
+	ended.Id = jobId
+	ended.PrinterId = 3
+	updated, err := s.repo.Update(ended)
+
+	s.True(updated)
+	s.Nil(err)
+	stored, _ := s.repo.GetById(jobId)
+	s.Equal(id, stored.PrintRequestId, "the request of a job does not change")
+	s.Equal(3, stored.PrinterId)
+	s.Require().NotNil(stored.EndedOn)
+	s.True(started.Add(150 * time.Minute).Equal(*stored.EndedOn))
+	s.Equal(9000, stored.Duration)
+	s.Equal(entity.JobSucceeded, stored.Outcome)
+}
+
+func (s *PrintJobSuite) TestNotFound() {
+	id, _ := s.requests.Insert(newPrintRequest("Cup Holder", "andi"))
+	s.requests.Delete(id)
+	started := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	var notFound *print_request.NotFoundError
+	_, err := s.repo.Insert(newPrintJob(id, started, 0, entity.JobPrinting))
+	s.True(errors.As(err, &notFound))
+	_, err = s.repo.Insert(newPrintJob(100, started, 0, entity.JobPrinting))
+	s.True(errors.As(err, &notFound))
+
+	var jobNotFound *print_job.NotFoundError
+	job := newPrintJob(id, started, time.Hour, entity.JobSucceeded)
+	job.Id = 100
+	_, err = s.repo.Update(job)
+	s.True(errors.As(err, &jobNotFound))
+}
+
+func (s *PrintJobSuite) TestGetEstimates() {
+	cup, _ := s.requests.Insert(newPrintRequest("Cup Holder", "andi"))
+	phone, _ := s.requests.Insert(newPrintRequest("Phone Holder", "budi"))
+	deleted, _ := s.requests.Insert(newPrintRequest("Key Hook", "andi"))
+	s.requests.Insert(newPrintRequest("Tray", "andi"))
+	march := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	jobs := []*entity.PrintJob{
+		newPrintJob(cup, march, 2*time.Hour, entity.JobSucceeded),
+		newPrintJob(cup, march.Add(24*time.Hour), 3*time.Hour, entity.JobSucceeded),
+		newPrintJob(cup, march, time.Hour, entity.JobFailed),
+		newPrintJob(cup, march, time.Hour, entity.JobCancelled),
+		newPrintJob(cup, march.AddDate(0, 1, 0), time.Hour, entity.JobSucceeded),    // ends after the range
+		newPrintJob(phone, march.Add(-time.Hour), 2*time.Hour, entity.JobSucceeded), // ends in the range
+		newPrintJob(phone, march, 0, entity.JobPrinting),
+		newPrintJob(deleted, march, time.Hour, entity.JobSucceeded),
+	}
+	for _, job := range jobs {
+		_, err := s.repo.Insert(job)
+		s.Require().Nil(err)
+	}
+	s.requests.Delete(deleted)
+
+	rows, err := s.repo.GetEstimates(&entity.EstimateQuery{From: march, To: march.AddDate(0, 1, 0)})
+
+	s.Nil(err)
+	s.Equal([]*entity.EstimateRow{
+		{PrintRequestId: cup, ItemName: "Cup Holder", Jobs: 2, EstimatedDuration: 9000, ActualDuration: 18000, EstimatedWeight: 37.5, ActualWeight: 81},
+		{PrintRequestId: phone, ItemName: "Phone Holder", Jobs: 1, EstimatedDuration: 9000, ActualDuration: 7200, EstimatedWeight: 37.5, ActualWeight: 40.5},
+	}, rows)
+
+	empty, err := s.repo.GetEstimates(&entity.EstimateQuery{From: march.AddDate(1, 0, 0), To: march.AddDate(1, 1, 0)})
+	s.Nil(err)
+	s.Len(empty, 0)
+}
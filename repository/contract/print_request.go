@@ -0,0 +1,496 @@
+package contract
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"threedee/entity"
+	outbox_event "threedee/interfaces/outbox-event"
+	print_request "threedee/interfaces/print-request"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+/*
+ * PrintRequestSuite is the contract of PrintRequestRepositoryInterface. Every backend has
+ * to pass it, so handlers can rely on the same behavior whatever DB_DRIVER is:
+ *
+ * - ids count up, a new request is StatusPendingReview whatever status it was inserted with
+ * - lists are ordered by id, whatever was updated last, and Each streams the same rows
+ * - Delete is a soft delete, deleted requests are gone from every read
+ * - GetById of a missing request returns an empty request, Update and Delete return a
+ *   print_request.NotFoundError
+ * - every change writes its outbox event in the same transaction
+ * - Search ranks item name matches above requestor above notes matches
+ * - review threads are ordered by id, entries can only be added to active requests
+ * - WithTransaction commits everything or nothing
+ *
+ * Run it from a test with suite.Run(t, &contract.PrintRequestSuite{New: ...}).
+ */
+
+type PrintRequestSuite struct {
+	suite.Suite
+
+	// New returns an empty repository and the outbox it writes its events to
+	New func(t *testing.T) (print_request.PrintRequestRepositoryInterface, outbox_event.OutboxRepositoryInterface)
+
+	repo   print_request.PrintRequestRepositoryInterface
+	outbox outbox_event.OutboxRepositoryInterface
+}
+
+func (s *PrintRequestSuite) SetupTest() {
+	s.repo, s.outbox = s.New(s.T())
+}
+
+func newPrintRequest(itemName string, requestor string) *entity.PrintRequest {
+	return &entity.PrintRequest{
+		ItemName:                itemName,
+		EstimatedWeight:         37.5,
+		EstimatedFilamentLength: 1250.5,
+		EstimatedDuration:       9000,
+		FileUrl:                 "http://drive.google.com/filez/100",
+		Requestor:               requestor,
+	}
+}
+
+// events returns the names of the pending outbox events, oldest first
+func (s *PrintRequestSuite) events() []string {
+	pending, err := s.outbox.GetPending("log", 100, 10, time.Now())
+	s.Require().Nil(err)
+
+	result := make([]string, 0)
+	for _, e := range pending {
+		result = append(result, e.Event)
+	}
+	return result
+}
+
+// jsonField returns the raw JSON of one field of an event payload
+func (s *PrintRequestSuite) jsonField(payload json.RawMessage, field string) json.RawMessage {
+	var fields map[string]json.RawMessage
+	s.Require().Nil(json.Unmarshal(payload, &fields))
+	return fields[field]
+}
+
+func (s *PrintRequestSuite) TestInsert() {
+	model := newPrintRequest("Cup Holder", "andi")
+	model.Reviewer = "sari"
+	model.Status = entity.StatusFinished
+	model.Material = entity.MaterialPetg
+	model.Color = "Red"
+	model.LayerHeight = 0.12
+	model.Infill = 0
+	model.Supports = true
+	model.NozzleSize = 0.6
+	model.Quantity = 3
+	model.EstimatedCost = 2.48
+	model.ProjectId = 4
+
+	id, err := s.repo.Insert(model)
+
+	s.Nil(err)
+	s.Greater(id, 0)
+	created, err := s.repo.GetById(id)
+	s.Nil(err)
+	expected := *model
+	expected.Id = id
+	expected.Status = entity.StatusPendingReview
+	s.Equal(&expected, created)
+	s.Equal([]string{entity.EventCreated}, s.events())
+}
+
+func (s *PrintRequestSuite) TestGetAll() {
+	first, _ := s.repo.Insert(newPrintRequest("Cup Holder", "andi"))
+	second, _ := s.repo.Insert(newPrintRequest("Phone Holder", "budi"))
+
+	all, err := s.repo.GetAll(nil)
+
+	s.Nil(err)
+	s.Require().Len(all, 2)
+	s.Equal(first, all[0].Id)
+	s.Equal(second, all[1].Id)
+	s.Greater(second, first)
+}
+
+func (s *PrintRequestSuite) TestGetAllOrderedById() {
+	ids := make([]int, 0)
+	for _, name := range []string{"Cup Holder", "Phone Holder", "Gantungan baju"} {
+		id, _ := s.repo.Insert(newPrintRequest(name, "andi"))
+		ids = append(ids, id)
+	}
+
+	// rows that were written last must not move to the end
+	first, _ := s.repo.GetById(ids[0])
+	first.Status = entity.StatusProcessed
+	s.repo.Update(first)
+
+	all, err := s.repo.GetAll(nil)
+
+	s.Nil(err)
+	s.Require().Len(all, 3)
+	for i, item := range all {
+		s.Equal(ids[i], item.Id)
+	}
+}
+
+func (s *PrintRequestSuite) TestGetAllFiltered() {
+	s.repo.Insert(newPrintRequest("Cup Holder", "andi"))
+	second, _ := s.repo.Insert(newPrintRequest("Phone Holder", "andi"))
+	s.repo.Insert(newPrintRequest("Gantungan baju", "budi"))
+	approved, _ := s.repo.GetById(second)
+	approved.Status = entity.StatusApproved
+	s.repo.Update(approved)
+
+	byRequestor, err := s.repo.GetAll(&entity.PrintRequestFilter{Requestor: "andi"})
+	s.Nil(err)
+	s.Len(byRequestor, 2)
+
+	byBoth, err := s.repo.GetAll(&entity.PrintRequestFilter{Requestor: "andi", Status: entity.StatusApproved})
+	s.Nil(err)
+	s.Require().Len(byBoth, 1)
+	s.Equal(second, byBoth[0].Id)
+}
+
+func (s *PrintRequestSuite) TestGetAllFilteredBySettings() {
+	red := newPrintRequest("Cup Holder", "andi")
+	red.Material = entity.MaterialPetg
+	red.Color = "Red"
+	red.Supports = true
+	first, _ := s.repo.Insert(red)
+	s.repo.Insert(newPrintRequest("Phone Holder", "andi"))
+
+	supports := true
+	byAll, err := s.repo.GetAll(&entity.PrintRequestFilter{Material: entity.MaterialPetg, Color: "red", Supports: &supports})
+	s.Nil(err)
+	s.Require().Len(byAll, 1, "colors match in any case")
+	s.Equal(first, byAll[0].Id)
+
+	supports = false
+	withoutSupports, err := s.repo.GetAll(&entity.PrintRequestFilter{Supports: &supports})
+	s.Nil(err)
+	s.Require().Len(withoutSupports, 1)
+	s.NotEqual(first, withoutSupports[0].Id)
+}
+
+func (s *PrintRequestSuite) TestEach() {
+	ids := make([]int, 0)
+	for _, name := range []string{"Cup Holder", "Phone Holder", "Gantungan baju"} {
+		id, _ := s.repo.Insert(newPrintRequest(name, "andi"))
+		ids = append(ids, id)
+	}
+	s.repo.Delete(ids[1])
+
+	seen := make([]int, 0)
+	err := s.repo.Each(nil, func(item *entity.PrintRequest) error {
+		seen = append(seen, item.Id)
+		return nil
+	})
+
+	s.Nil(err)
+	s.Equal([]int{ids[0], ids[2]}, seen)
+
+	// an error of fn stops the iteration
+	stop := errors.New("stop")
+	calls := 0
+	err = s.repo.Each(&entity.PrintRequestFilter{Requestor: "andi"}, func(*entity.PrintRequest) error {
+		calls++
+		return stop
+	})
+
+	s.Equal(stop, err)
+	s.Equal(1, calls)
+}
+
+func (s *PrintRequestSuite) TestGetByIdNotFound() {
+	id, _ := s.repo.Insert(newPrintRequest("Cup Holder", "andi"))
+
+	missing, err := s.repo.GetById(id + 1)
+
+	s.Nil(err)
+	s.Equal(entity.NewPrintRequest(), missing)
+}
+
+func (s *PrintRequestSuite) TestUpdate() {
+	id, _ := s.repo.Insert(newPrintRequest("Cup Holder", "andi"))
+	model, _ := s.repo.GetById(id)
+
+	model.ItemName = "Cup Holder v2"
+	model.Reviewer = "tono"
+	model.Material = entity.MaterialAbs
+	model.Infill = 40
+	model.Quantity = 2
+	model.EstimatedCost = 1.5
+	updated, err := s.repo.Update(model)
+	s.True(updated)
+	s.Nil(err)
+
+	model.Status = entity.StatusApproved
+	updated, err = s.repo.Update(model)
+	s.True(updated)
+	s.Nil(err)
+
+	stored, _ := s.repo.GetById(id)
+	s.Equal(model, stored)
+	s.Equal([]string{entity.EventCreated, entity.EventUpdated, entity.EventStatusChanged}, s.events())
+
+	pending, _ := s.outbox.GetPending("log", 100, 10, time.Now())
+	s.JSONEq(`"`+entity.StatusPendingReview+`"`, string(s.jsonField(pending[2].Payload, "previous_status")))
+}
+
+func (s *PrintRequestSuite) TestDelete() {
+	id, _ := s.repo.Insert(newPrintRequest("Cup Holder", "andi"))
+
+	deleted, err := s.repo.Delete(id)
+
+	s.True(deleted)
+	s.Nil(err)
+	gone, err := s.repo.GetById(id)
+	s.Nil(err)
+	s.Equal(0, gone.Id)
+	all, _ := s.repo.GetAll(nil)
+	s.Len(all, 0)
+	s.Equal([]string{entity.EventCreated, entity.EventDeleted}, s.events())
+}
+
+func (s *PrintRequestSuite) TestNotFound() {
+	id, _ := s.repo.Insert(newPrintRequest("Cup Holder", "andi"))
+	deleted, _ := s.repo.Insert(newPrintRequest("Phone Holder", "andi"))
+	s.repo.Delete(deleted)
+
+	var testCase = []struct {
+		testcase string
+		id       int
+	}{
+		{
+			testcase: "missing",
+			id:       deleted + 1,
+		},
+		{
+			testcase: "deleted",
+			id:       deleted,
+		},
+	}
+	for _, tc := range testCase {
+		var notFound *print_request.NotFoundError
+
+		model := newPrintRequest("Cup Holder", "andi")
+		model.Id = tc.id
+		updated, err := s.repo.Update(model)
+		s.False(updated, tc.testcase)
+		s.True(errors.As(err, &notFound), tc.testcase)
+		s.Equal(tc.id, notFound.Id, tc.testcase)
+
+		removed, err := s.repo.Delete(tc.id)
+		s.False(removed, tc.testcase)
+		s.True(errors.As(err, &notFound), tc.testcase)
+	}
+
+	// nothing was written for the failed calls
+	s.Equal([]string{entity.EventCreated, entity.EventCreated, entity.EventDeleted}, s.events())
+	stored, _ := s.repo.GetById(id)
+	s.Equal("Cup Holder", stored.ItemName)
+}
+
+func (s *PrintRequestSuite) TestGetActiveByFileHash() {
+	model := newPrintRequest("Cup Holder", "andi")
+	model.FileHash = "abc"
+	active, _ := s.repo.Insert(model)
+	deleted, _ := s.repo.Insert(model)
+	other := newPrintRequest("Cup Holder", "budi")
+	other.FileHash = "abc"
+	s.repo.Insert(other)
+	newer, _ := s.repo.Insert(model)
+
+	for _, status := range entity.TerminalStatuses {
+		id, _ := s.repo.Insert(model)
+		done, _ := s.repo.GetById(id)
+		done.Status = status
+		s.repo.Update(done)
+	}
+	s.repo.Delete(deleted)
+
+	result, err := s.repo.GetActiveByFileHash("abc", "andi")
+
+	s.Nil(err)
+	s.Require().Len(result, 2)
+	s.Equal(active, result[0].Id)
+	s.Equal(newer, result[1].Id)
+}
+
+func (s *PrintRequestSuite) TestSearch() {
+	inNotes := newPrintRequest("Phone Stand", "andi")
+	inNotes.Notes = "same color as the cup holder please"
+	notesId, _ := s.repo.Insert(inNotes)
+	nameId, _ := s.repo.Insert(newPrintRequest("Cup Holder v2", "budi"))
+	deleted, _ := s.repo.Insert(newPrintRequest("Cup Holder v1", "budi"))
+	s.repo.Delete(deleted)
+	s.repo.Insert(newPrintRequest("Gantungan baju", "Holder Kosasih"))
+
+	var testCase = []struct {
+		testcase string
+		query    string
+		ids      []int
+	}{
+		{
+			testcase: "item name ranks above notes",
+			query:    "cup holder",
+			ids:      []int{nameId, notesId},
+		},
+		{
+			testcase: "prefix",
+			query:    "Hold CU",
+			ids:      []int{nameId, notesId},
+		},
+		{
+			testcase: "every word has to match",
+			query:    "cup baju",
+			ids:      []int{},
+		},
+		{
+			testcase: "query syntax is ignored",
+			query:    "cup & !holder:*",
+			ids:      []int{nameId, notesId},
+		},
+	}
+	for _, tc := range testCase {
+		result, err := s.repo.Search(tc.query, 10)
+
+		s.Nil(err, tc.testcase)
+		ids := make([]int, 0)
+		for _, item := range result {
+			ids = append(ids, item.Id)
+		}
+		s.Equal(tc.ids, ids, tc.testcase)
+	}
+
+	result, _ := s.repo.Search("holder", 1)
+	s.Require().Len(result, 1)
+	s.Equal(nameId, result[0].Id)
+	s.Equal("Cup <mark>Holder</mark> v2", result[0].Highlights.ItemName)
+	s.Greater(result[0].Rank, float32(0))
+}
+
+func (s *PrintRequestSuite) TestSearchEscapesHighlights() {
+	model := newPrintRequest(`Mug "Holder" & <3`, "andi")
+	model.Notes = "holder for 2 > 1 cups & 'mugs'"
+	id, _ := s.repo.Insert(model)
+
+	result, err := s.repo.Search("holder", 10)
+
+	s.Nil(err)
+	s.Require().Len(result, 1)
+	s.Equal(id, result[0].Id)
+	s.Equal(`Mug &#34;<mark>Holder</mark>&#34; &amp; &lt;3`, result[0].Highlights.ItemName)
+	s.Equal("<mark>holder</mark> for 2 &gt; 1 cups &amp; &#39;mugs&#39;", result[0].Highlights.Notes)
+	s.Equal(`Mug "Holder" & <3`, result[0].ItemName, "only the highlights are escaped")
+}
+
+func (s *PrintRequestSuite) TestWithTransaction() {
+	first, _ := s.repo.Insert(newPrintRequest("Cup Holder", "andi"))
+
+	err := s.repo.WithTransaction(func(repo print_request.PrintRequestRepositoryInterface) error {
+		id, err := repo.Insert(newPrintRequest("Phone Holder", "budi"))
+		if err != nil {
+			return err
+		}
+		inside, err := repo.GetById(id)
+		if err != nil {
+			return err
+		}
+		s.Equal("Phone Holder", inside.ItemName)
+
+		_, err = repo.Delete(first)
+		if err != nil {
+			return err
+		}
+		return errors.New("[TEST] roll back")
+	})
+
+	s.NotNil(err)
+	all, _ := s.repo.GetAll(nil)
+	s.Require().Len(all, 1)
+	s.Equal(first, all[0].Id)
+	s.Equal([]string{entity.EventCreated}, s.events())
+
+	var second int
+	err = s.repo.WithTransaction(func(repo print_request.PrintRequestRepositoryInterface) error {
+		var err error
+		second, err = repo.Insert(newPrintRequest("Phone Holder", "budi"))
+		return err
+	})
+
+	s.Nil(err)
+	committed, _ := s.repo.GetById(second)
+	s.Equal("Phone Holder", committed.ItemName)
+	s.Equal([]string{entity.EventCreated, entity.EventCreated}, s.events())
+}
+
+func (s *PrintRequestSuite) TestWithTransactionRollsBackEvents() {
+	id, _ := s.repo.Insert(newPrintRequest("Cup Holder", "andi"))
+
+	err := s.repo.WithTransaction(func(repo print_request.PrintRequestRepositoryInterface) error {
+		model, err := repo.GetById(id)
+		if err != nil {
+			return err
+		}
+		model.ItemName = "Cup Holder v2"
+		model.Status = entity.StatusApproved
+		_, err = repo.Update(model)
+		if err != nil {
+			return err
+		}
+		_, err = repo.Insert(newPrintRequest("Phone Holder", "budi"))
+		if err != nil {
+			return err
+		}
+		return errors.New("[TEST] roll back")
+	})
+
+	s.NotNil(err)
+	stored, _ := s.repo.GetById(id)
+	s.Equal("Cup Holder", stored.ItemName)
+	s.Equal(entity.StatusPendingReview, stored.Status)
+	all, _ := s.repo.GetAll(nil)
+	s.Len(all, 1)
+	s.Equal([]string{entity.EventCreated}, s.events(), "the events of the rolled back changes are gone too")
+}
+
+func (s *PrintRequestSuite) TestReviewThread() {
+	id, _ := s.repo.Insert(newPrintRequest("Cup Holder", "andi"))
+	other, _ := s.repo.Insert(newPrintRequest("Phone Holder", "budi"))
+
+	entries := []*entity.ReviewEntry{
+		{PrintRequestId: id, Author: "sari", Action: entity.ReviewRequestChanges, Comment: "Please use 20% infill"},
+		{PrintRequestId: other, Author: "sari", Action: entity.ReviewApprove},
+		{PrintRequestId: id, Author: "andi", Action: entity.ReviewResubmit, Comment: "Done"},
+	}
+	for _, entry := range entries {
+		entryId, err := s.repo.AddReviewEntry(entry)
+		s.Nil(err)
+		s.Greater(entryId, 0)
+	}
+
+	thread, err := s.repo.GetReviewThread(id)
+
+	s.Nil(err)
+	s.Require().Len(thread, 2)
+	s.Less(thread[0].Id, thread[1].Id)
+	s.Equal(id, thread[0].PrintRequestId)
+	s.Equal("sari", thread[0].Author)
+	s.Equal(entity.ReviewRequestChanges, thread[0].Action)
+	s.Equal("Please use 20% infill", thread[0].Comment)
+	s.WithinDuration(time.Now(), thread[0].CreatedOn, time.Minute)
+	s.Equal(entity.ReviewResubmit, thread[1].Action)
+
+	empty, err := s.repo.GetReviewThread(100)
+	s.Nil(err)
+	s.Len(empty, 0)
+
+	s.repo.Delete(other)
+	_, err = s.repo.AddReviewEntry(&entity.ReviewEntry{PrintRequestId: other, Author: "sari", Action: entity.ReviewReject})
+	var notFound *print_request.NotFoundError
+	s.True(errors.As(err, &notFound))
+	_, err = s.repo.AddReviewEntry(&entity.ReviewEntry{PrintRequestId: 100, Author: "sari", Action: entity.ReviewReject})
+	s.True(errors.As(err, &notFound))
+}
@@ -0,0 +1,197 @@
+package contract
+
+import (
+	"errors"
+	"testing"
+	"threedee/entity"
+	print_request "threedee/interfaces/print-request"
+	"threedee/interfaces/project"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+/*
+ * ProjectSuite is the contract of ProjectRepositoryInterface:
+ *
+ * - projects are listed ordered by id, GetById returns an empty project when there is no
+ *   active one
+ * - the totals sum the estimates of the active parts that are not rejected, the parts are
+ *   counted by status and the status is derived from them
+ * - Update and Delete of a missing or deleted project give a project.NotFoundError
+ * - Delete of a project with active parts, whatever their status, gives a
+ *   project.HasPartsError and keeps the project
+ */
+
+type ProjectSuite struct {
+	suite.Suite
+
+	// New returns an empty print request repository and the project repository on it
+	New func(t *testing.T) (print_request.PrintRequestRepositoryInterface, project.ProjectRepositoryInterface)
+
+	requests print_request.PrintRequestRepositoryInterface
+	repo     project.ProjectRepositoryInterface
+}
+
+func (s *ProjectSuite) SetupTest() {
+	s.requests, s.repo = s.New(s.T())
+}
+
+// addPart inserts a part of projectId and moves it to status
+func (s *ProjectSuite) addPart(projectId int, itemName string, status string) int {
+	model := newPrintRequest(itemName, "andi")
+	model.ProjectId = projectId
+	model.EstimatedCost = 0.75
+	id, err := s.requests.Insert(model)
+	s.Require().Nil(err)
+	if status != entity.StatusPendingReview {
+		model.Id = id
+		model.Status = status
+		_, err = s.requests.Update(model)
+		s.Require().Nil(err)
+	}
+	return id
+}
+
+func (s *ProjectSuite) TestInsert() {
+	id, err := s.repo.Insert(&entity.Project{Name: "Drone Frame", Owner: "andi", Description: "250 mm quad"})
+
+	s.Nil(err)
+	s.Greater(id, 0)
+	stored, err := s.repo.GetById(id)
+	s.Nil(err)
+	s.Equal(id, stored.Id)
+	s.Equal("Drone Frame", stored.Name)
+	s.Equal("andi", stored.Owner)
+	s.Equal("250 mm quad", stored.Description)
+	s.Equal(entity.ProjectEmpty, stored.Status)
+	s.Equal(map[string]int{}, stored.Parts)
+	s.WithinDuration(time.Now(), stored.CreatedOn, time.Minute)
+
+	missing, err := s.repo.GetById(id + 1)
+	s.Nil(err)
+	s.Equal(0, missing.Id)
+}
+
+func (s *ProjectSuite) TestTotals() {
+	frame, _ := s.repo.Insert(&entity.Project{Name: "Drone Frame", Owner: "andi"})
+	other, _ := s.repo.Insert(&entity.Project{Name: "Desk Organizer", Owner: "budi"})
+
+	s.addPart(frame, "Arm", entity.StatusFinished)
+	s.addPart(frame, "Arm", entity.StatusApproved)
+	s.addPart(frame, "Canopy", entity.StatusRejected)
+	deleted := s.addPart(frame, "Battery Strap", entity.StatusApproved)
+	s.requests.Delete(deleted)
+	s.addPart(other, "Tray", entity.StatusPendingReview)
+	s.addPart(0, "Cup Holder", entity.StatusApproved)
+
+	all, err := s.repo.GetAll()
+
+	s.Nil(err)
+	s.Require().Len(all, 2)
+	s.Equal(frame, all[0].Id)
+	s.Equal(map[string]int{entity.StatusFinished: 1, entity.StatusApproved: 1, entity.StatusRejected: 1}, all[0].Parts)
+	s.Equal(float32(75), all[0].EstimatedWeight, "rejected and deleted parts are not summed")
+	s.Equal(float32(2501), all[0].EstimatedFilamentLength)
+	s.Equal(18000, all[0].EstimatedDuration)
+	s.Equal(float32(1.5), all[0].EstimatedCost)
+	s.Equal(entity.ProjectInProgress, all[0].Status)
+	s.Equal(other, all[1].Id)
+	s.Equal(entity.ProjectInReview, all[1].Status)
+
+	stored, err := s.repo.GetById(frame)
+	s.Nil(err)
+	s.Equal(all[0], stored)
+
+	parts, err := s.requests.GetAll(&entity.PrintRequestFilter{ProjectId: frame})
+	s.Nil(err)
+	s.Len(parts, 3)
+}
+
+func (s *ProjectSuite) TestStatus() {
+	id, _ := s.repo.Insert(&entity.Project{Name: "Drone Frame", Owner: "andi"})
+	status := func() string {
+		stored, err := s.repo.GetById(id)
+		s.Require().Nil(err)
+		return stored.Status
+	}
+
+	s.Equal(entity.ProjectEmpty, status())
+	canopy := s.addPart(id, "Canopy", entity.StatusRejected)
+	s.Equal(entity.ProjectRejected, status(), "all parts rejected")
+	arm := s.addPart(id, "Arm", entity.StatusPendingReview)
+	s.Equal(entity.ProjectInReview, status())
+	s.addPart(id, "Arm", entity.StatusFinished)
+	s.Equal(entity.ProjectInReview, status(), "a part is not approved yet")
+
+	part, _ := s.requests.GetById(arm)
+	part.Status = entity.StatusApproved
+	s.requests.Update(part)
+	s.Equal(entity.ProjectInProgress, status())
+	part.Status = entity.StatusFailed
+	s.requests.Update(part)
+	s.Equal(entity.ProjectFailed, status())
+	part.Status = entity.StatusFinished
+	s.requests.Update(part)
+	s.Equal(entity.ProjectFinished, status(), "rejected parts are not waited for")
+
+	s.requests.Delete(canopy)
+	s.Equal(entity.ProjectFinished, status())
+}
+
+func (s *ProjectSuite) TestUpdate() {
+	id, _ := s.repo.Insert(&entity.Project{Name: "Drone Frame", Owner: "andi"})
+
+	updated, err := s.repo.Update(&entity.Project{Id: id, Name: "Drone Frame v2", Owner: "budi", Description: "5 inch"})
+
+	s.True(updated)
+	s.Nil(err)
+	stored, _ := s.repo.GetById(id)
+	s.Equal("Drone Frame v2", stored.Name)
+	s.Equal("budi", stored.Owner)
+	s.Equal("5 inch", stored.Description)
+}
+
+func (s *ProjectSuite) TestDelete() {
+	id, _ := s.repo.Insert(&entity.Project{Name: "Drone Frame", Owner: "andi"})
+
+	deleted, err := s.repo.Delete(id)
+
+	s.True(deleted)
+	s.Nil(err)
+	stored, _ := s.repo.GetById(id)
+	s.Equal(0, stored.Id)
+	all, _ := s.repo.GetAll()
+	s.Len(all, 0)
+}
+
+func (s *ProjectSuite) TestDeleteWithParts() {
+	id, _ := s.repo.Insert(&entity.Project{Name: "Drone Frame", Owner: "andi"})
+	part := s.addPart(id, "Arm", entity.StatusRejected)
+
+	deleted, err := s.repo.Delete(id)
+
+	s.False(deleted)
+	var hasParts *project.HasPartsError
+	s.True(errors.As(err, &hasParts))
+	stored, _ := s.repo.GetById(id)
+	s.Equal(id, stored.Id)
+
+	s.requests.Delete(part)
+	deleted, err = s.repo.Delete(id)
+	s.True(deleted)
+	s.Nil(err)
+}
+
+func (s *ProjectSuite) TestNotFound() {
+	id, _ := s.repo.Insert(&entity.Project{Name: "Drone Frame", Owner: "andi"})
+	s.repo.Delete(id)
+
+	var notFound *project.NotFoundError
+	_, err := s.repo.Update(&entity.Project{Id: id, Name: "Drone Frame v2", Owner: "andi"})
+	s.True(errors.As(err, &notFound))
+	_, err = s.repo.Delete(id)
+	s.True(errors.As(err, &notFound))
+	_, err = s.repo.Update(&entity.Project{Id: 100, Name: "Desk Organizer", Owner: "andi"})
+	s.True(errors.As(err, &notFound))
+}
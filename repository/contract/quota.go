@@ -0,0 +1,125 @@
+package contract
+
+import (
+	"sync"
+	"testing"
+	"threedee/entity"
+	print_request "threedee/interfaces/print-request"
+	requestor_quota "threedee/interfaces/requestor-quota"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+/*
+ * QuotaSuite is the contract of QuotaRepositoryInterface:
+ *
+ * - GetByRequestor returns an empty quota until one is upserted
+ * - GetUsage counts the requests in review, received, processed and approved as active and sums the
+ *   estimates of the requests created since the month and week start, leaving out
+ *   rejected and deleted requests
+ * - GetQuotaUsage of the print request repository counts the same, inside a transaction
+ *   including its own inserts, and concurrent transactions checking the same requestor
+ *   run one after the other
+ */
+
+type QuotaSuite struct {
+	suite.Suite
+
+	// New returns an empty print request repository and the quota repository reading it
+	New func(t *testing.T) (print_request.PrintRequestRepositoryInterface, requestor_quota.QuotaRepositoryInterface)
+
+	requests print_request.PrintRequestRepositoryInterface
+	repo     requestor_quota.QuotaRepositoryInterface
+}
+
+func (s *QuotaSuite) SetupTest() {
+	s.requests, s.repo = s.New(s.T())
+}
+
+func (s *QuotaSuite) TestUpsert() {
+	empty, err := s.repo.GetByRequestor("andi")
+	s.Nil(err)
+	s.Equal(entity.NewQuota(), empty)
+
+	s.Nil(s.repo.Upsert(&entity.Quota{Requestor: "andi", MaxActive: 3, MaxGramsPerMonth: 500}))
+	s.Nil(s.repo.Upsert(&entity.Quota{Requestor: "andi", MaxActive: 5, MaxHoursPerWeek: 12.5}))
+
+	stored, err := s.repo.GetByRequestor("andi")
+	s.Nil(err)
+	s.Equal(&entity.Quota{Requestor: "andi", MaxActive: 5, MaxHoursPerWeek: 12.5}, stored)
+}
+
+func (s *QuotaSuite) TestGetUsage() {
+	for _, status := range []string{entity.StatusReceived, entity.StatusApproved, entity.StatusFinished, entity.StatusRejected, entity.StatusReceived} {
+		id, err := s.requests.Insert(newPrintRequest("Cup Holder", "andi"))
+		s.Require().Nil(err)
+		model, _ := s.requests.GetById(id)
+		model.Status = status
+		_, err = s.requests.Update(model)
+		s.Require().Nil(err)
+	}
+	s.requests.Delete(5)
+	s.requests.Insert(newPrintRequest("Cup Holder", "budi"))
+
+	now := time.Now().UTC()
+	usage, err := s.repo.GetUsage("andi", now.Add(-time.Hour), now.Add(-time.Hour))
+
+	s.Nil(err)
+	s.Equal(&entity.QuotaUsage{Active: 2, GramsThisMonth: 3 * 37.5, HoursThisWeek: 3 * 2.5}, usage)
+
+	// requests created before the period start are only counted as active
+	later, err := s.repo.GetUsage("andi", now.Add(time.Hour), now.Add(time.Hour))
+
+	s.Nil(err)
+	s.Equal(&entity.QuotaUsage{Active: 2}, later)
+}
+
+func (s *QuotaSuite) TestGetQuotaUsage() {
+	s.requests.Insert(newPrintRequest("Cup Holder", "andi"))
+	now := time.Now().UTC()
+	start := now.Add(-time.Hour)
+
+	usage, err := s.repo.GetUsage("andi", start, start)
+	s.Require().Nil(err)
+	same, err := s.requests.GetQuotaUsage("andi", start, start)
+	s.Nil(err)
+	s.Equal(usage, same)
+
+	err = s.requests.WithTransaction(func(repo print_request.PrintRequestRepositoryInterface) error {
+		_, err := repo.Insert(newPrintRequest("Phone Holder", "andi"))
+		s.Require().Nil(err)
+		usage, err := repo.GetQuotaUsage("andi", start, start)
+		s.Nil(err)
+		s.Equal(2, usage.Active, "the insert of the transaction counts")
+		return nil
+	})
+	s.Nil(err)
+}
+
+func (s *QuotaSuite) TestGetQuotaUsageConcurrent() {
+	start := time.Now().UTC().Add(-time.Hour)
+
+	// every transaction only inserts while andi has no active request
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.requests.WithTransaction(func(repo print_request.PrintRequestRepositoryInterface) error {
+				usage, err := repo.GetQuotaUsage("andi", start, start)
+				if err != nil || usage.Active > 0 {
+					return err
+				}
+				_, err = repo.Insert(newPrintRequest("Cup Holder", "andi"))
+				return err
+			})
+			s.Nil(err)
+		}()
+	}
+	wg.Wait()
+
+	usage, err := s.repo.GetUsage("andi", start, start)
+	s.Nil(err)
+	s.Equal(1, usage.Active)
+}
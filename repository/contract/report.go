@@ -0,0 +1,104 @@
+package contract
+
+import (
+	"testing"
+	"threedee/entity"
+	print_request "threedee/interfaces/print-request"
+	"threedee/interfaces/report"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+/*
+ * ReportSuite is the contract of ReportRepositoryInterface. The requests are inserted
+ * through the print request repository, so they are all created today:
+ *
+ * - only active requests created in [From, To) are counted
+ * - periods are the first day of the interval, weeks start on Monday
+ * - requestor and status are empty unless the report is grouped by them
+ * - rows are ordered by period, requestor and status
+ */
+
+type ReportSuite struct {
+	suite.Suite
+
+	// New returns an empty print request repository and the report repository reading it
+	New func(t *testing.T) (print_request.PrintRequestRepositoryInterface, report.ReportRepositoryInterface)
+
+	requests print_request.PrintRequestRepositoryInterface
+	repo     report.ReportRepositoryInterface
+}
+
+func (s *ReportSuite) SetupTest() {
+	s.requests, s.repo = s.New(s.T())
+
+	for _, requestor := range []string{"budi", "andi", "andi", "citra"} {
+		_, err := s.requests.Insert(newPrintRequest("Cup Holder", requestor))
+		s.Require().Nil(err)
+	}
+	approved, err := s.requests.GetById(2)
+	s.Require().Nil(err)
+	approved.Status = entity.StatusApproved
+	_, err = s.requests.Update(approved)
+	s.Require().Nil(err)
+	_, err = s.requests.Delete(4)
+	s.Require().Nil(err)
+}
+
+// today returns the range of the last and next day, so the requests are in it whatever
+// the time zone of the database
+func today() (time.Time, time.Time) {
+	now := time.Now().UTC()
+	return now.AddDate(0, 0, -1), now.AddDate(0, 0, 1)
+}
+
+func (s *ReportSuite) TestGetUsageByRequestor() {
+	from, to := today()
+
+	rows, err := s.repo.GetUsage(&entity.UsageQuery{From: from, To: to, Interval: entity.IntervalDay, ByRequestor: true})
+
+	s.Nil(err)
+	period := time.Now().UTC().Format("2006-01-02")
+	s.Equal([]*entity.UsageRow{
+		{Period: period, Requestor: "andi", Requests: 2, Weight: 75, FilamentLength: 2501, Duration: 18000},
+		{Period: period, Requestor: "budi", Requests: 1, Weight: 37.5, FilamentLength: 1250.5, Duration: 9000},
+	}, rows)
+}
+
+func (s *ReportSuite) TestGetUsageByStatus() {
+	from, to := today()
+
+	rows, err := s.repo.GetUsage(&entity.UsageQuery{From: from, To: to, Interval: entity.IntervalMonth, ByStatus: true})
+
+	s.Nil(err)
+	now := time.Now().UTC()
+	period := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+	s.Equal([]*entity.UsageRow{
+		{Period: period, Status: entity.StatusApproved, Requests: 1, Weight: 37.5, FilamentLength: 1250.5, Duration: 9000},
+		{Period: period, Status: entity.StatusPendingReview, Requests: 2, Weight: 75, FilamentLength: 2501, Duration: 18000},
+	}, rows)
+}
+
+func (s *ReportSuite) TestGetUsageByWeek() {
+	from, to := today()
+
+	rows, err := s.repo.GetUsage(&entity.UsageQuery{From: from, To: to, Interval: entity.IntervalWeek})
+
+	s.Nil(err)
+	s.Require().Len(rows, 1)
+	monday, err := time.Parse("2006-01-02", rows[0].Period)
+	s.Nil(err)
+	s.Equal(time.Monday, monday.Weekday())
+	s.True(time.Since(monday) < 7*24*time.Hour)
+	s.Equal(3, rows[0].Requests)
+}
+
+func (s *ReportSuite) TestGetUsageOutOfRange() {
+	_, to := today()
+
+	rows, err := s.repo.GetUsage(&entity.UsageQuery{From: to, To: to.AddDate(0, 1, 0), Interval: entity.IntervalDay})
+
+	s.Nil(err)
+	s.Empty(rows)
+}
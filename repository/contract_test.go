@@ -0,0 +1,312 @@
+package repository_test
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"threedee/database"
+	outbox_event "threedee/interfaces/outbox-event"
+	print_job "threedee/interfaces/print-job"
+	print_request "threedee/interfaces/print-request"
+	print_request_comment "threedee/interfaces/print-request-comment"
+	"threedee/interfaces/project"
+	"threedee/interfaces/report"
+	requestor_quota "threedee/interfaces/requestor-quota"
+	"threedee/repository"
+	"threedee/repository/contract"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestMemoryPrintRequestContract(t *testing.T) {
+	suite.Run(t, &contract.PrintRequestSuite{
+		New: func(t *testing.T) (print_request.PrintRequestRepositoryInterface, outbox_event.OutboxRepositoryInterface) {
+			events := repository.NewMemoryOutboxRepository()
+			return repository.NewMemoryPrintRequestRepository(events), events
+		},
+	})
+}
+
+func TestSqlitePrintRequestContract(t *testing.T) {
+	suite.Run(t, &contract.PrintRequestSuite{
+		New: func(t *testing.T) (print_request.PrintRequestRepositoryInterface, outbox_event.OutboxRepositoryInterface) {
+			db := sqliteDB(t)
+			return repository.NewSqlitePrintRequestRepository(db), repository.NewSqliteOutboxRepository(db)
+		},
+	})
+}
+
+func TestMemoryOutboxContract(t *testing.T) {
+	suite.Run(t, &contract.OutboxSuite{
+		New: func(t *testing.T) (print_request.PrintRequestRepositoryInterface, outbox_event.OutboxRepositoryInterface) {
+			events := repository.NewMemoryOutboxRepository()
+			return repository.NewMemoryPrintRequestRepository(events), events
+		},
+	})
+}
+
+func TestSqliteOutboxContract(t *testing.T) {
+	suite.Run(t, &contract.OutboxSuite{
+		New: func(t *testing.T) (print_request.PrintRequestRepositoryInterface, outbox_event.OutboxRepositoryInterface) {
+			db := sqliteDB(t)
+			return repository.NewSqlitePrintRequestRepository(db), repository.NewSqliteOutboxRepository(db)
+		},
+	})
+}
+
+func TestMemoryReportContract(t *testing.T) {
+	suite.Run(t, &contract.ReportSuite{
+		New: func(t *testing.T) (print_request.PrintRequestRepositoryInterface, report.ReportRepositoryInterface) {
+			requests := repository.NewMemoryPrintRequestRepository(repository.NewMemoryOutboxRepository())
+			return requests, repository.NewMemoryReportRepository(requests)
+		},
+	})
+}
+
+func TestSqliteReportContract(t *testing.T) {
+	suite.Run(t, &contract.ReportSuite{
+		New: func(t *testing.T) (print_request.PrintRequestRepositoryInterface, report.ReportRepositoryInterface) {
+			db := sqliteDB(t)
+			return repository.NewSqlitePrintRequestRepository(db), repository.NewSqliteReportRepository(db)
+		},
+	})
+}
+
+func TestMemoryQuotaContract(t *testing.T) {
+	suite.Run(t, &contract.QuotaSuite{
+		New: func(t *testing.T) (print_request.PrintRequestRepositoryInterface, requestor_quota.QuotaRepositoryInterface) {
+			requests := repository.NewMemoryPrintRequestRepository(repository.NewMemoryOutboxRepository())
+			return requests, repository.NewMemoryQuotaRepository(requests)
+		},
+	})
+}
+
+func TestSqliteQuotaContract(t *testing.T) {
+	suite.Run(t, &contract.QuotaSuite{
+		New: func(t *testing.T) (print_request.PrintRequestRepositoryInterface, requestor_quota.QuotaRepositoryInterface) {
+			db := sqliteDB(t)
+			return repository.NewSqlitePrintRequestRepository(db), repository.NewSqliteQuotaRepository(db)
+		},
+	})
+}
+
+func TestMemoryCommentContract(t *testing.T) {
+	suite.Run(t, &contract.CommentSuite{
+		New: func(t *testing.T) (print_request.PrintRequestRepositoryInterface, print_request_comment.CommentRepositoryInterface) {
+			requests := repository.NewMemoryPrintRequestRepository(repository.NewMemoryOutboxRepository())
+			return requests, repository.NewMemoryCommentRepository(requests)
+		},
+	})
+}
+
+func TestSqliteCommentContract(t *testing.T) {
+	suite.Run(t, &contract.CommentSuite{
+		New: func(t *testing.T) (print_request.PrintRequestRepositoryInterface, print_request_comment.CommentRepositoryInterface) {
+			db := sqliteDB(t)
+			return repository.NewSqlitePrintRequestRepository(db), repository.NewSqliteCommentRepository(db)
+		},
+	})
+}
+
+func TestMemoryProjectContract(t *testing.T) {
+	suite.Run(t, &contract.ProjectSuite{
+		New: func(t *testing.T) (print_request.PrintRequestRepositoryInterface, project.ProjectRepositoryInterface) {
+			requests := repository.NewMemoryPrintRequestRepository(repository.NewMemoryOutboxRepository())
+			return requests, repository.NewMemoryProjectRepository(requests)
+		},
+	})
+}
+
+func TestSqliteProjectContract(t *testing.T) {
+	suite.Run(t, &contract.ProjectSuite{
+		New: func(t *testing.T) (print_request.PrintRequestRepositoryInterface, project.ProjectRepositoryInterface) {
+			db := sqliteDB(t)
+			return repository.NewSqlitePrintRequestRepository(db), repository.NewSqliteProjectRepository(db)
+		},
+	})
+}
+
+func TestMemoryPrintJobContract(t *testing.T) {
+	suite.Run(t, &contract.PrintJobSuite{
+		New: func(t *testing.T) (print_request.PrintRequestRepositoryInterface, print_job.PrintJobRepositoryInterface) {
+			requests := repository.NewMemoryPrintRequestRepository(repository.NewMemoryOutboxRepository())
+			return requests, repository.NewMemoryPrintJobRepository(requests)
+		},
+	})
+}
+
+func TestSqlitePrintJobContract(t *testing.T) {
+	suite.Run(t, &contract.PrintJobSuite{
+		New: func(t *testing.T) (print_request.PrintRequestRepositoryInterface, print_job.PrintJobRepositoryInterface) {
+			db := sqliteDB(t)
+			return repository.NewSqlitePrintRequestRepository(db), repository.NewSqlitePrintJobRepository(db)
+		},
+	})
+}
+
+// The Postgres suites only run with TEST_POSTGRES, see postgresDB
+func TestPostgresPrintRequestContract(t *testing.T) {
+	suite.Run(t, &contract.PrintRequestSuite{
+		New: func(t *testing.T) (print_request.PrintRequestRepositoryInterface, outbox_event.OutboxRepositoryInterface) {
+			postgresDB(t)
+			return repository.NewPrintRequestRepository(), repository.NewOutboxRepository()
+		},
+	})
+}
+
+func TestPostgresOutboxContract(t *testing.T) {
+	suite.Run(t, &contract.OutboxSuite{
+		New: func(t *testing.T) (print_request.PrintRequestRepositoryInterface, outbox_event.OutboxRepositoryInterface) {
+			postgresDB(t)
+			return repository.NewPrintRequestRepository(), repository.NewOutboxRepository()
+		},
+	})
+}
+
+func TestPostgresReportContract(t *testing.T) {
+	suite.Run(t, &contract.ReportSuite{
+		New: func(t *testing.T) (print_request.PrintRequestRepositoryInterface, report.ReportRepositoryInterface) {
+			postgresDB(t)
+			return repository.NewPrintRequestRepository(), repository.NewReportRepository()
+		},
+	})
+}
+
+func TestPostgresQuotaContract(t *testing.T) {
+	suite.Run(t, &contract.QuotaSuite{
+		New: func(t *testing.T) (print_request.PrintRequestRepositoryInterface, requestor_quota.QuotaRepositoryInterface) {
+			postgresDB(t, "tbl_m_quota")
+			return repository.NewPrintRequestRepository(), repository.NewQuotaRepository()
+		},
+	})
+}
+
+func TestPostgresCommentContract(t *testing.T) {
+	suite.Run(t, &contract.CommentSuite{
+		New: func(t *testing.T) (print_request.PrintRequestRepositoryInterface, print_request_comment.CommentRepositoryInterface) {
+			postgresDB(t)
+			return repository.NewPrintRequestRepository(), repository.NewCommentRepository()
+		},
+	})
+}
+
+func TestPostgresProjectContract(t *testing.T) {
+	suite.Run(t, &contract.ProjectSuite{
+		New: func(t *testing.T) (print_request.PrintRequestRepositoryInterface, project.ProjectRepositoryInterface) {
+			postgresDB(t, "tbl_m_project")
+			return repository.NewPrintRequestRepository(), repository.NewProjectRepository()
+		},
+	})
+}
+
+func TestPostgresPrintJobContract(t *testing.T) {
+	suite.Run(t, &contract.PrintJobSuite{
+		New: func(t *testing.T) (print_request.PrintRequestRepositoryInterface, print_job.PrintJobRepositoryInterface) {
+			postgresDB(t)
+			return repository.NewPrintRequestRepository(), repository.NewPrintJobRepository()
+		},
+	})
+}
+
+// postgresTables are emptied before every Postgres contract test, they hold the print
+// requests and what refers to them
+var postgresTables = []string{
+	"tbl_m_3d_print_request",
+	"tbl_t_review",
+	"tbl_t_comment",
+	"tbl_t_comment_attachment",
+	"tbl_t_print_job",
+	"tbl_t_outbox",
+	"tbl_t_outbox_sink",
+}
+
+// sqliteDB returns a new migrated SQLite database, closed when t ends
+func sqliteDB(t *testing.T) *sql.DB {
+	db, err := database.NewSqlite(filepath.Join(t.TempDir(), "threedee.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// postgresDB migrates the Postgres test database, which the repositories connect to
+// themselves, and empties postgresTables and extraTables, the other tables a suite writes to. It skips t unless TEST_POSTGRES is set,
+// so it only runs against a throwaway database: "make test-postgres" starts one in Docker,
+// or set TEST_POSTGRES=1 and point the DB_* variables at your own.
+func postgresDB(t *testing.T, extraTables ...string) {
+	if os.Getenv("TEST_POSTGRES") == "" {
+		t.Skip("TEST_POSTGRES is not set")
+	}
+
+	db, err := database.NewPostgresql()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	migratePostgresql(t, db)
+
+	tables := append(append([]string{}, postgresTables...), extraTables...)
+	_, err = db.Exec("TRUNCATE " + strings.Join(tables, ", ") + " RESTART IDENTITY")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// migratePostgresql runs the files in database/migrations that were not run yet, in name
+// order, and records them in schema_migrations like the SQLite migrations
+func migratePostgresql(t *testing.T, db *sql.DB) {
+	_, err := db.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (version varchar(200) primary key not null)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := filepath.Glob("../database/migrations/*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		version := filepath.Base(file)
+		var applied int
+		err := db.QueryRow("SELECT count(*) FROM schema_migrations WHERE version = $1", version).Scan(&applied)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if applied > 0 {
+			continue
+		}
+
+		script, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = runMigration(db, version, string(script))
+		if err != nil {
+			t.Fatalf("%s: %s", file, err)
+		}
+	}
+}
+
+// runMigration runs script and records version in one transaction
+func runMigration(db *sql.DB, version string, script string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(script)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("INSERT INTO schema_migrations (version) VALUES ($1)", version)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"sync"
+	"threedee/entity"
+	print_request "threedee/interfaces/print-request"
+	"time"
+)
+
+// MemoryCommentRepository keeps comments in memory, on the requests of a
+// MemoryPrintRequestRepository
+type MemoryCommentRepository struct {
+	mu       sync.Mutex
+	comments []entity.Comment
+	requests *MemoryPrintRequestRepository
+}
+
+func NewMemoryCommentRepository(requests *MemoryPrintRequestRepository) *MemoryCommentRepository {
+	return &MemoryCommentRepository{requests: requests}
+}
+
+func (r *MemoryCommentRepository) GetByPrintRequest(printRequestId int) ([]*entity.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]*entity.Comment, 0)
+	for _, comment := range r.comments {
+		if comment.PrintRequestId == printRequestId {
+			result = append(result, copyComment(&comment))
+		}
+	}
+	return result, nil
+}
+
+func (r *MemoryCommentRepository) Insert(model *entity.Comment) (int, error) {
+	active := false
+	r.requests.read(func(data *memoryPrintRequests) {
+		_, ok := data.rows[model.PrintRequestId]
+		active = ok && !data.deleted[model.PrintRequestId]
+	})
+	if !active {
+		return 0, &print_request.NotFoundError{Id: model.PrintRequestId}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	added := copyComment(model)
+	added.Id = len(r.comments) + 1
+	added.CreatedOn = time.Now().UTC()
+	for _, attachment := range added.Attachments {
+		attachment.Url = ""
+	}
+	r.comments = append(r.comments, *added)
+	return added.Id, nil
+}
+
+// copyComment copies a comment with its attachments, so callers can not change the stored one
+func copyComment(model *entity.Comment) *entity.Comment {
+	c := *model
+	c.Attachments = make([]*entity.Attachment, 0, len(model.Attachments))
+	for _, attachment := range model.Attachments {
+		a := *attachment
+		c.Attachments = append(c.Attachments, &a)
+	}
+	return &c
+}
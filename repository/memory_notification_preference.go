@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"sync"
+	"threedee/entity"
+)
+
+type MemoryNotificationPreferenceRepository struct {
+	mu    sync.Mutex
+	prefs map[string]entity.NotificationPreference
+}
+
+func NewMemoryNotificationPreferenceRepository() *MemoryNotificationPreferenceRepository {
+	return &MemoryNotificationPreferenceRepository{prefs: make(map[string]entity.NotificationPreference)}
+}
+
+func (r *MemoryNotificationPreferenceRepository) GetByRequestor(requestor string) (*entity.NotificationPreference, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	item := r.prefs[requestor]
+	return &item, nil
+}
+
+func (r *MemoryNotificationPreferenceRepository) Upsert(model *entity.NotificationPreference) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.prefs[model.Requestor] = *model
+	return nil
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"encoding/json"
+	"sync"
+	"threedee/entity"
+	"time"
+)
+
+// pendingOutboxEvent is an event of a memory transaction that is not committed yet
+type pendingOutboxEvent struct {
+	event       string
+	aggregateId int
+	data        interface{}
+}
+
+// outboxSinkKey is an event of a sink
+type outboxSinkKey struct {
+	id   int
+	sink string
+}
+
+// outboxSinkState is what a sink did with an event, like tbl_t_outbox_sink
+type outboxSinkState struct {
+	attempts      int
+	lastError     string
+	nextAttemptOn time.Time
+	delivered     bool
+}
+
+// MemoryOutboxRepository is the outbox of the memory repositories
+type MemoryOutboxRepository struct {
+	mu        sync.Mutex
+	events    []*entity.OutboxEvent
+	delivered map[int]bool
+	sinks     map[outboxSinkKey]*outboxSinkState
+}
+
+func NewMemoryOutboxRepository() *MemoryOutboxRepository {
+	return &MemoryOutboxRepository{delivered: make(map[int]bool), sinks: make(map[outboxSinkKey]*outboxSinkState)}
+}
+
+func (r *MemoryOutboxRepository) add(events []pendingOutboxEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, e := range events {
+		payload, err := json.Marshal(e.data)
+		if err != nil {
+			return err
+		}
+		r.events = append(r.events, &entity.OutboxEvent{
+			Id:          len(r.events) + 1,
+			Event:       e.event,
+			AggregateId: e.aggregateId,
+			Payload:     payload,
+			CreatedOn:   time.Now(),
+		})
+	}
+	return nil
+}
+
+func (r *MemoryOutboxRepository) GetPending(sink string, limit int, maxAttempts int, now time.Time) ([]*entity.OutboxEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]*entity.OutboxEvent, 0)
+	for _, e := range r.events {
+		if len(result) == limit {
+			break
+		}
+		state, ok := r.sinks[outboxSinkKey{e.Id, sink}]
+		if !ok {
+			state = &outboxSinkState{}
+		}
+		if r.delivered[e.Id] || state.delivered || state.attempts >= maxAttempts || state.nextAttemptOn.After(now) {
+			continue
+		}
+		item := *e
+		item.Attempts = state.attempts
+		item.LastError = state.lastError
+		result = append(result, &item)
+	}
+	return result, nil
+}
+
+func (r *MemoryOutboxRepository) MarkDelivered(id int, sink string, sinks int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id < 1 || id > len(r.events) {
+		return nil
+	}
+	state := r.state(id, sink)
+	state.attempts++
+	state.nextAttemptOn = time.Time{}
+	state.delivered = true
+	r.events[id-1].Attempts++
+
+	count := 0
+	for key, s := range r.sinks {
+		if key.id == id && s.delivered {
+			count++
+		}
+	}
+	if count >= sinks {
+		r.delivered[id] = true
+	}
+	return nil
+}
+
+func (r *MemoryOutboxRepository) MarkFailed(id int, sink string, reason string, retryAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id < 1 || id > len(r.events) {
+		return nil
+	}
+	state := r.state(id, sink)
+	state.attempts++
+	state.lastError = reason
+	state.nextAttemptOn = retryAt
+	r.events[id-1].Attempts++
+	r.events[id-1].LastError = sink + ": " + reason
+	return nil
+}
+
+// state returns what sink did with the event id, adding it when the sink did nothing yet
+func (r *MemoryOutboxRepository) state(id int, sink string) *outboxSinkState {
+	key := outboxSinkKey{id, sink}
+	if _, ok := r.sinks[key]; !ok {
+		r.sinks[key] = &outboxSinkState{}
+	}
+	return r.sinks[key]
+}
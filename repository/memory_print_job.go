@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"sync"
+	"threedee/entity"
+	print_job "threedee/interfaces/print-job"
+	print_request "threedee/interfaces/print-request"
+	"time"
+)
+
+// MemoryPrintJobRepository keeps print jobs in memory, on the requests of a
+// MemoryPrintRequestRepository
+type MemoryPrintJobRepository struct {
+	mu       sync.Mutex
+	jobs     []entity.PrintJob
+	requests *MemoryPrintRequestRepository
+}
+
+func NewMemoryPrintJobRepository(requests *MemoryPrintRequestRepository) *MemoryPrintJobRepository {
+	return &MemoryPrintJobRepository{requests: requests}
+}
+
+func (r *MemoryPrintJobRepository) GetByPrintRequest(printRequestId int) ([]*entity.PrintJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]*entity.PrintJob, 0)
+	for _, job := range r.jobs {
+		if job.PrintRequestId == printRequestId {
+			result = append(result, copyPrintJob(&job))
+		}
+	}
+	return result, nil
+}
+
+func (r *MemoryPrintJobRepository) GetById(id int) (*entity.PrintJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id < 1 || id > len(r.jobs) {
+		return &entity.PrintJob{}, nil
+	}
+	return copyPrintJob(&r.jobs[id-1]), nil
+}
+
+func (r *MemoryPrintJobRepository) Insert(model *entity.PrintJob) (int, error) {
+	active := false
+	r.requests.read(func(data *memoryPrintRequests) {
+		_, ok := data.rows[model.PrintRequestId]
+		active = ok && !data.deleted[model.PrintRequestId]
+	})
+	if !active {
+		return 0, &print_request.NotFoundError{Id: model.PrintRequestId}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	added := copyPrintJob(model)
+	added.Id = len(r.jobs) + 1
+	added.CreatedOn = time.Now().UTC()
+	r.jobs = append(r.jobs, *added)
+	return added.Id, nil
+}
+
+func (r *MemoryPrintJobRepository) Update(model *entity.PrintJob) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if model.Id < 1 || model.Id > len(r.jobs) {
+		return false, &print_job.NotFoundError{Id: model.Id}
+	}
+	stored := &r.jobs[model.Id-1]
+	updated := copyPrintJob(model)
+	updated.PrintRequestId = stored.PrintRequestId
+	updated.CreatedOn = stored.CreatedOn
+	*stored = *updated
+	return true, nil
+}
+
+func (r *MemoryPrintJobRepository) GetEstimates(query *entity.EstimateQuery) ([]*entity.EstimateRow, error) {
+	r.mu.Lock()
+	byRequest := make(map[int]*entity.EstimateRow)
+	for _, job := range r.jobs {
+		if job.Outcome != entity.JobSucceeded || job.EndedOn == nil || job.EndedOn.Before(query.From) || !job.EndedOn.Before(query.To) {
+			continue
+		}
+		row, ok := byRequest[job.PrintRequestId]
+		if !ok {
+			row = &entity.EstimateRow{PrintRequestId: job.PrintRequestId}
+			byRequest[job.PrintRequestId] = row
+		}
+		row.Jobs++
+		row.ActualDuration += job.Duration
+		row.ActualWeight += job.FilamentUsed
+	}
+	r.mu.Unlock()
+
+	result := make([]*entity.EstimateRow, 0)
+	r.requests.read(func(data *memoryPrintRequests) {
+		for _, request := range data.active(func(row *entity.PrintRequest) bool { return byRequest[row.Id] != nil }) {
+			row := byRequest[request.Id]
+			row.ItemName = request.ItemName
+			row.EstimatedDuration = request.EstimatedDuration
+			row.EstimatedWeight = request.EstimatedWeight
+			result = append(result, row)
+		}
+	})
+	return result, nil
+}
+
+// copyPrintJob copies a job with its end, so callers can not change the stored one
+func copyPrintJob(model *entity.PrintJob) *entity.PrintJob {
+	c := *model
+	if model.EndedOn != nil {
+		endedOn := model.EndedOn.UTC()
+		c.EndedOn = &endedOn
+	}
+	c.StartedOn = model.StartedOn.UTC()
+	return &c
+}
@@ -0,0 +1,331 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"threedee/entity"
+	print_request "threedee/interfaces/print-request"
+	"threedee/utility/search"
+	"time"
+)
+
+/*
+ * MemoryPrintRequestRepository keeps print requests in memory, for local development and
+ * end-to-end tests without a database. It behaves like PrintRequestRepository: ids count
+ * up from 1, new requests are StatusPendingReview, Delete is a soft delete and every change
+ * writes its outbox event, committed together with the change.
+ *
+ * A transaction holds the lock until it ends, so calls inside WithTransaction must go
+ * through the repository passed to fn.
+ */
+
+type memoryPrintRequests struct {
+	lastId  int
+	rows    map[int]entity.PrintRequest
+	deleted map[int]bool
+	created map[int]time.Time
+	reviews []entity.ReviewEntry
+}
+
+func (d *memoryPrintRequests) clone() *memoryPrintRequests {
+	c := &memoryPrintRequests{
+		lastId:  d.lastId,
+		rows:    make(map[int]entity.PrintRequest, len(d.rows)),
+		deleted: make(map[int]bool, len(d.deleted)),
+		created: make(map[int]time.Time, len(d.created)),
+		reviews: append([]entity.ReviewEntry(nil), d.reviews...),
+	}
+	for id, row := range d.rows {
+		c.rows[id] = row
+		c.created[id] = d.created[id]
+	}
+	for id := range d.deleted {
+		c.deleted[id] = true
+	}
+	return c
+}
+
+// active returns copies of the rows that are not deleted, ordered by id
+func (d *memoryPrintRequests) active(match func(row *entity.PrintRequest) bool) []*entity.PrintRequest {
+	result := make([]*entity.PrintRequest, 0)
+	for id, row := range d.rows {
+		row := row
+		if !d.deleted[id] && match(&row) {
+			result = append(result, &row)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Id < result[j].Id })
+	return result
+}
+
+// quotaUsage counts the usage of requestor like quotaUsageQuery
+func (d *memoryPrintRequests) quotaUsage(requestor string, monthStart time.Time, weekStart time.Time) *entity.QuotaUsage {
+	item := &entity.QuotaUsage{}
+	var seconds int
+	for id, row := range d.rows {
+		if d.deleted[id] || row.Requestor != requestor || row.Status == entity.StatusRejected {
+			continue
+		}
+		if !entity.IsTerminalStatus(row.Status) {
+			item.Active++
+		}
+		if !d.created[id].Before(monthStart) {
+			item.GramsThisMonth += float64(row.EstimatedWeight)
+		}
+		if !d.created[id].Before(weekStart) {
+			seconds += row.EstimatedDuration
+		}
+	}
+	item.HoursThisWeek = float64(seconds) / 3600
+	return item
+}
+
+type memoryTransaction struct {
+	data   *memoryPrintRequests
+	events []pendingOutboxEvent
+}
+
+type MemoryPrintRequestRepository struct {
+	mu     *sync.Mutex
+	data   *memoryPrintRequests
+	outbox *MemoryOutboxRepository
+	tx     *memoryTransaction // set on the repository passed to WithTransaction
+}
+
+func NewMemoryPrintRequestRepository(outbox *MemoryOutboxRepository) *MemoryPrintRequestRepository {
+	return &MemoryPrintRequestRepository{
+		mu:     &sync.Mutex{},
+		data:   &memoryPrintRequests{rows: make(map[int]entity.PrintRequest), deleted: make(map[int]bool), created: make(map[int]time.Time)},
+		outbox: outbox,
+	}
+}
+
+// read runs fn on the data of the current transaction, or on the committed data
+func (r *MemoryPrintRequestRepository) read(fn func(data *memoryPrintRequests)) {
+	if r.tx != nil {
+		fn(r.tx.data)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(r.data)
+}
+
+// inTransaction runs fn in the current transaction, or in a new one on a copy of the data
+// that replaces the committed data, and publishes its events, when fn succeeds.
+func (r *MemoryPrintRequestRepository) inTransaction(fn func(tx *memoryTransaction) error) error {
+	if r.tx != nil {
+		return fn(r.tx)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tx := &memoryTransaction{data: r.data.clone()}
+	err := fn(tx)
+	if err != nil {
+		return err
+	}
+	*r.data = *tx.data
+	return r.outbox.add(tx.events)
+}
+
+func (r *MemoryPrintRequestRepository) GetAll(filter *entity.PrintRequestFilter) ([]*entity.PrintRequest, error) {
+	var result []*entity.PrintRequest
+	r.read(func(data *memoryPrintRequests) {
+		result = data.active(filter.Matches)
+	})
+	return result, nil
+}
+
+// Each calls fn outside of the lock, so fn may use the repository
+func (r *MemoryPrintRequestRepository) Each(filter *entity.PrintRequestFilter, fn func(item *entity.PrintRequest) error) error {
+	items, _ := r.GetAll(filter)
+	for _, item := range items {
+		err := fn(item)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *MemoryPrintRequestRepository) GetById(id int) (*entity.PrintRequest, error) {
+	item := entity.NewPrintRequest()
+	r.read(func(data *memoryPrintRequests) {
+		if row, ok := data.rows[id]; ok && !data.deleted[id] {
+			*item = row
+		}
+	})
+	return item, nil
+}
+
+func (r *MemoryPrintRequestRepository) GetActiveByFileHash(hash string, requestor string) ([]*entity.PrintRequest, error) {
+	var result []*entity.PrintRequest
+	r.read(func(data *memoryPrintRequests) {
+		result = data.active(func(row *entity.PrintRequest) bool {
+			return row.FileHash == hash && row.Requestor == requestor && !entity.IsTerminalStatus(row.Status)
+		})
+	})
+	return result, nil
+}
+
+// GetQuotaUsage needs no lock of its own, a transaction holds the lock of all requests
+func (r *MemoryPrintRequestRepository) GetQuotaUsage(requestor string, monthStart time.Time, weekStart time.Time) (*entity.QuotaUsage, error) {
+	var item *entity.QuotaUsage
+	r.read(func(data *memoryPrintRequests) {
+		item = data.quotaUsage(requestor, monthStart, weekStart)
+	})
+	return item, nil
+}
+
+// Search weighs matches like the Postgres search vector: item name 1, requestor 0.4 and
+// notes 0.2 for every term, newest first on a tie
+func (r *MemoryPrintRequestRepository) Search(query string, limit int) ([]*entity.SearchResult, error) {
+	terms := search.Terms(query)
+	result := make([]*entity.SearchResult, 0)
+	if len(terms) == 0 {
+		return result, nil
+	}
+
+	var rows []*entity.PrintRequest
+	r.read(func(data *memoryPrintRequests) {
+		rows = data.active(func(*entity.PrintRequest) bool { return true })
+	})
+
+	for _, row := range rows {
+		var rank float32
+		matched := true
+		for _, t := range terms {
+			var weight float32
+			if search.Matches(row.Notes, t) {
+				weight = 0.2
+			}
+			if search.Matches(row.Requestor, t) {
+				weight = 0.4
+			}
+			if search.Matches(row.ItemName, t) {
+				weight = 1
+			}
+			if weight == 0 {
+				matched = false
+				break
+			}
+			rank += weight
+		}
+		if !matched {
+			continue
+		}
+		result = append(result, &entity.SearchResult{
+			PrintRequest: row,
+			Rank:         rank,
+			Highlights: entity.Highlight{
+				ItemName:  search.Highlight(row.ItemName, terms),
+				Requestor: search.Highlight(row.Requestor, terms),
+				Notes:     search.Highlight(row.Notes, terms),
+			},
+		})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Rank != result[j].Rank {
+			return result[i].Rank > result[j].Rank
+		}
+		return result[i].Id > result[j].Id
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (r *MemoryPrintRequestRepository) WithTransaction(fn func(repo print_request.PrintRequestRepositoryInterface) error) error {
+	return r.inTransaction(func(tx *memoryTransaction) error {
+		return fn(&MemoryPrintRequestRepository{mu: r.mu, data: r.data, outbox: r.outbox, tx: tx})
+	})
+}
+
+func (r *MemoryPrintRequestRepository) Insert(model *entity.PrintRequest) (int, error) {
+	created := *model
+	err := r.inTransaction(func(tx *memoryTransaction) error {
+		tx.data.lastId++
+		created.Id = tx.data.lastId
+		created.Status = entity.StatusPendingReview
+		tx.data.rows[created.Id] = created
+		tx.data.created[created.Id] = time.Now().UTC()
+		tx.events = append(tx.events, pendingOutboxEvent{entity.EventCreated, created.Id, created})
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return created.Id, nil
+}
+
+func (r *MemoryPrintRequestRepository) Update(model *entity.PrintRequest) (bool, error) {
+	err := r.inTransaction(func(tx *memoryTransaction) error {
+		previous, ok := tx.data.rows[model.Id]
+		if !ok || tx.data.deleted[model.Id] {
+			return &print_request.NotFoundError{Id: model.Id}
+		}
+		tx.data.rows[model.Id] = *model
+
+		if previous.Status != model.Status {
+			changed := *model
+			tx.events = append(tx.events, pendingOutboxEvent{entity.EventStatusChanged, model.Id, entity.StatusChangedEvent{PrintRequest: &changed, PreviousStatus: previous.Status}})
+		} else {
+			tx.events = append(tx.events, pendingOutboxEvent{entity.EventUpdated, model.Id, *model})
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *MemoryPrintRequestRepository) Delete(id int) (bool, error) {
+	err := r.inTransaction(func(tx *memoryTransaction) error {
+		row, ok := tx.data.rows[id]
+		if !ok || tx.data.deleted[id] {
+			return &print_request.NotFoundError{Id: id}
+		}
+		tx.data.deleted[id] = true
+		tx.events = append(tx.events, pendingOutboxEvent{entity.EventDeleted, id, entity.DeletedEvent{Id: id, Requestor: row.Requestor, Status: row.Status}})
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *MemoryPrintRequestRepository) GetReviewThread(printRequestId int) ([]*entity.ReviewEntry, error) {
+	result := make([]*entity.ReviewEntry, 0)
+	r.read(func(data *memoryPrintRequests) {
+		for _, entry := range data.reviews {
+			entry := entry
+			if entry.PrintRequestId == printRequestId {
+				result = append(result, &entry)
+			}
+		}
+	})
+	return result, nil
+}
+
+func (r *MemoryPrintRequestRepository) AddReviewEntry(entry *entity.ReviewEntry) (int, error) {
+	added := *entry
+	err := r.inTransaction(func(tx *memoryTransaction) error {
+		if _, ok := tx.data.rows[entry.PrintRequestId]; !ok || tx.data.deleted[entry.PrintRequestId] {
+			return &print_request.NotFoundError{Id: entry.PrintRequestId}
+		}
+		added.Id = len(tx.data.reviews) + 1
+		added.CreatedOn = time.Now().UTC()
+		tx.data.reviews = append(tx.data.reviews, added)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return added.Id, nil
+}
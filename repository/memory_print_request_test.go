@@ -0,0 +1,118 @@
+package repository_test
+
+import (
+	"errors"
+	"testing"
+	"threedee/entity"
+	print_request "threedee/interfaces/print-request"
+	"threedee/repository"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MemoryPrintRequestRepositoryTestSuite struct {
+	suite.Suite
+	outbox *repository.MemoryOutboxRepository
+	repo   *repository.MemoryPrintRequestRepository
+}
+
+func (suite *MemoryPrintRequestRepositoryTestSuite) SetupTest() {
+	suite.outbox = repository.NewMemoryOutboxRepository()
+	suite.repo = repository.NewMemoryPrintRequestRepository(suite.outbox)
+}
+
+func (suite *MemoryPrintRequestRepositoryTestSuite) pendingEvents() []string {
+	pending, _ := suite.outbox.GetPending("log", 100, 10, time.Now())
+	events := make([]string, 0)
+	for _, e := range pending {
+		events = append(events, e.Event)
+	}
+	return events
+}
+
+func (suite *MemoryPrintRequestRepositoryTestSuite) TestLifecycle() {
+	id, err := suite.repo.Insert(&entity.PrintRequest{ItemName: "Cup Holder", Requestor: "andi", Status: entity.StatusFinished})
+	suite.Nil(err)
+	suite.Equal(1, id)
+
+	created, _ := suite.repo.GetById(id)
+	suite.Equal("Cup Holder", created.ItemName)
+	suite.Equal(entity.StatusPendingReview, created.Status)
+
+	created.Status = entity.StatusProcessed
+	updated, err := suite.repo.Update(created)
+	suite.True(updated)
+	suite.Nil(err)
+
+	deleted, err := suite.repo.Delete(id)
+	suite.True(deleted)
+	suite.Nil(err)
+
+	gone, _ := suite.repo.GetById(id)
+	suite.Equal(0, gone.Id)
+	all, _ := suite.repo.GetAll(nil)
+	suite.Len(all, 0)
+
+	_, err = suite.repo.Delete(id)
+	var notFound *print_request.NotFoundError
+	suite.True(errors.As(err, &notFound))
+	_, err = suite.repo.Update(created)
+	suite.True(errors.As(err, &notFound))
+
+	suite.Equal([]string{entity.EventCreated, entity.EventStatusChanged, entity.EventDeleted}, suite.pendingEvents())
+}
+
+func (suite *MemoryPrintRequestRepositoryTestSuite) TestWithTransaction() {
+	first, _ := suite.repo.Insert(&entity.PrintRequest{ItemName: "Cup Holder"})
+
+	err := suite.repo.WithTransaction(func(repo print_request.PrintRequestRepositoryInterface) error {
+		id, err := repo.Insert(&entity.PrintRequest{ItemName: "Phone Holder"})
+		if err != nil {
+			return err
+		}
+		inside, _ := repo.GetById(id)
+		suite.Equal("Phone Holder", inside.ItemName)
+
+		_, err = repo.Delete(first)
+		if err != nil {
+			return err
+		}
+		return errors.New("[TEST] rolled back")
+	})
+
+	suite.NotNil(err)
+	all, _ := suite.repo.GetAll(nil)
+	suite.Len(all, 1)
+	suite.Equal(first, all[0].Id)
+	suite.Equal([]string{entity.EventCreated}, suite.pendingEvents())
+
+	err = suite.repo.WithTransaction(func(repo print_request.PrintRequestRepositoryInterface) error {
+		_, err := repo.Insert(&entity.PrintRequest{ItemName: "Phone Holder"})
+		return err
+	})
+
+	suite.Nil(err)
+	all, _ = suite.repo.GetAll(nil)
+	suite.Len(all, 2)
+	suite.Equal([]string{entity.EventCreated, entity.EventCreated}, suite.pendingEvents())
+}
+
+func (suite *MemoryPrintRequestRepositoryTestSuite) TestGetActiveByFileHash() {
+	suite.repo.Insert(&entity.PrintRequest{FileHash: "abc", Requestor: "andi"})
+	finished, _ := suite.repo.Insert(&entity.PrintRequest{FileHash: "abc", Requestor: "andi"})
+	suite.repo.Insert(&entity.PrintRequest{FileHash: "abc", Requestor: "budi"})
+	model, _ := suite.repo.GetById(finished)
+	model.Status = entity.StatusFinished
+	suite.repo.Update(model)
+
+	result, err := suite.repo.GetActiveByFileHash("abc", "andi")
+
+	suite.Nil(err)
+	suite.Len(result, 1)
+	suite.Equal(1, result[0].Id)
+}
+
+func TestMemoryPrintRequestRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(MemoryPrintRequestRepositoryTestSuite))
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"sync"
+	"threedee/entity"
+)
+
+// MemoryPrinterRepository is a fixed printer registry, empty unless printers are added
+type MemoryPrinterRepository struct {
+	mu       sync.Mutex
+	printers []entity.Printer
+}
+
+func NewMemoryPrinterRepository(printers ...entity.Printer) *MemoryPrinterRepository {
+	return &MemoryPrinterRepository{printers: printers}
+}
+
+func (r *MemoryPrinterRepository) GetAll() ([]*entity.Printer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]*entity.Printer, 0, len(r.printers))
+	for _, p := range r.printers {
+		p := p
+		result = append(result, &p)
+	}
+	return result, nil
+}
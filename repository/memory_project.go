@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"sync"
+	"threedee/entity"
+	"threedee/interfaces/project"
+	"time"
+)
+
+// MemoryProjectRepository keeps projects in memory and sums the parts in the requests of a
+// MemoryPrintRequestRepository
+type MemoryProjectRepository struct {
+	mu       sync.Mutex
+	projects []entity.Project
+	deleted  map[int]bool
+	requests *MemoryPrintRequestRepository
+}
+
+func NewMemoryProjectRepository(requests *MemoryPrintRequestRepository) *MemoryProjectRepository {
+	return &MemoryProjectRepository{deleted: make(map[int]bool), requests: requests}
+}
+
+func (r *MemoryProjectRepository) GetAll() ([]*entity.Project, error) {
+	return r.query(0), nil
+}
+
+func (r *MemoryProjectRepository) GetById(id int) (*entity.Project, error) {
+	result := r.query(id)
+	if id == 0 || len(result) == 0 {
+		return entity.NewProject(), nil
+	}
+	return result[0], nil
+}
+
+func (r *MemoryProjectRepository) Insert(model *entity.Project) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	added := entity.Project{
+		Id:          len(r.projects) + 1,
+		Name:        model.Name,
+		Owner:       model.Owner,
+		Description: model.Description,
+		CreatedOn:   time.Now().UTC(),
+	}
+	r.projects = append(r.projects, added)
+	return added.Id, nil
+}
+
+func (r *MemoryProjectRepository) Update(model *entity.Project) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.active(model.Id) {
+		return false, &project.NotFoundError{Id: model.Id}
+	}
+	stored := &r.projects[model.Id-1]
+	stored.Name = model.Name
+	stored.Owner = model.Owner
+	stored.Description = model.Description
+	return true, nil
+}
+
+func (r *MemoryProjectRepository) Delete(id int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.active(id) {
+		return false, &project.NotFoundError{Id: id}
+	}
+	var parts []*entity.PrintRequest
+	r.requests.read(func(data *memoryPrintRequests) {
+		parts = data.active(func(row *entity.PrintRequest) bool { return row.ProjectId == id })
+	})
+	if len(parts) > 0 {
+		return false, &project.HasPartsError{Id: id}
+	}
+	r.deleted[id] = true
+	return true, nil
+}
+
+func (r *MemoryProjectRepository) active(id int) bool {
+	return id > 0 && id <= len(r.projects) && !r.deleted[id]
+}
+
+// query returns the active projects, or the one with id when it is not 0, with the totals
+// of their parts
+func (r *MemoryProjectRepository) query(id int) []*entity.Project {
+	r.mu.Lock()
+	result := make([]*entity.Project, 0)
+	byId := make(map[int]*entity.Project)
+	for _, stored := range r.projects {
+		if r.deleted[stored.Id] || (id != 0 && stored.Id != id) {
+			continue
+		}
+		item := entity.NewProject()
+		item.Id = stored.Id
+		item.Name = stored.Name
+		item.Owner = stored.Owner
+		item.Description = stored.Description
+		item.CreatedOn = stored.CreatedOn
+		result = append(result, item)
+		byId[item.Id] = item
+	}
+	r.mu.Unlock()
+
+	r.requests.read(func(data *memoryPrintRequests) {
+		for _, part := range data.active(func(row *entity.PrintRequest) bool { return byId[row.ProjectId] != nil }) {
+			byId[part.ProjectId].AddParts(part.Status, 1, part.EstimatedWeight, part.EstimatedFilamentLength, part.EstimatedDuration, part.EstimatedCost)
+		}
+	})
+	return result
+}
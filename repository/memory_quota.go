@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"sync"
+	"threedee/entity"
+	"time"
+)
+
+// MemoryQuotaRepository keeps quotas in memory and counts the usage in the requests of a
+// MemoryPrintRequestRepository
+type MemoryQuotaRepository struct {
+	mu       sync.Mutex
+	quotas   map[string]entity.Quota
+	requests *MemoryPrintRequestRepository
+}
+
+func NewMemoryQuotaRepository(requests *MemoryPrintRequestRepository) *MemoryQuotaRepository {
+	return &MemoryQuotaRepository{quotas: make(map[string]entity.Quota), requests: requests}
+}
+
+func (r *MemoryQuotaRepository) GetByRequestor(requestor string) (*entity.Quota, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	item := r.quotas[requestor]
+	return &item, nil
+}
+
+func (r *MemoryQuotaRepository) Upsert(model *entity.Quota) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.quotas[model.Requestor] = *model
+	return nil
+}
+
+func (r *MemoryQuotaRepository) GetUsage(requestor string, monthStart time.Time, weekStart time.Time) (*entity.QuotaUsage, error) {
+	var item *entity.QuotaUsage
+	r.requests.read(func(data *memoryPrintRequests) {
+		item = data.quotaUsage(requestor, monthStart, weekStart)
+	})
+	return item, nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"sort"
+	"threedee/entity"
+	"time"
+)
+
+// MemoryReportRepository aggregates the requests of a MemoryPrintRequestRepository like
+// the SQL reports do
+type MemoryReportRepository struct {
+	requests *MemoryPrintRequestRepository
+}
+
+func NewMemoryReportRepository(requests *MemoryPrintRequestRepository) *MemoryReportRepository {
+	return &MemoryReportRepository{requests}
+}
+
+func (r *MemoryReportRepository) GetUsage(query *entity.UsageQuery) ([]*entity.UsageRow, error) {
+	groups := make(map[entity.UsageRow]*entity.UsageRow)
+	r.requests.read(func(data *memoryPrintRequests) {
+		for id, row := range data.rows {
+			created := data.created[id]
+			if data.deleted[id] || created.Before(query.From) || !created.Before(query.To) {
+				continue
+			}
+
+			key := entity.UsageRow{Period: usagePeriod(created, query.Interval)}
+			if query.ByRequestor {
+				key.Requestor = row.Requestor
+			}
+			if query.ByStatus {
+				key.Status = row.Status
+			}
+			group, ok := groups[key]
+			if !ok {
+				group = &entity.UsageRow{Period: key.Period, Requestor: key.Requestor, Status: key.Status}
+				groups[key] = group
+			}
+			group.Requests++
+			group.Weight += float64(row.EstimatedWeight)
+			group.FilamentLength += float64(row.EstimatedFilamentLength)
+			group.Duration += row.EstimatedDuration
+		}
+	})
+
+	result := make([]*entity.UsageRow, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Period != b.Period {
+			return a.Period < b.Period
+		}
+		if a.Requestor != b.Requestor {
+			return a.Requestor < b.Requestor
+		}
+		return a.Status < b.Status
+	})
+	return result, nil
+}
+
+// usagePeriod returns the first day of the interval t is in, weeks start on Monday
+func usagePeriod(t time.Time, interval string) string {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case entity.IntervalDay:
+	case entity.IntervalWeek:
+		day = day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	default:
+		day = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day.Format("2006-01-02")
+}
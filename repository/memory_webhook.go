@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"threedee/entity"
+	"time"
+)
+
+type MemoryWebhookRepository struct {
+	mu            sync.Mutex
+	subscriptions map[int]entity.WebhookSubscription
+	lastId        int
+	deliveries    []entity.WebhookDelivery
+}
+
+func NewMemoryWebhookRepository() *MemoryWebhookRepository {
+	return &MemoryWebhookRepository{subscriptions: make(map[int]entity.WebhookSubscription)}
+}
+
+func (r *MemoryWebhookRepository) find(match func(sub *entity.WebhookSubscription) bool) []*entity.WebhookSubscription {
+	result := make([]*entity.WebhookSubscription, 0)
+	for _, sub := range r.subscriptions {
+		sub := sub
+		sub.Events = append([]string(nil), sub.Events...)
+		if match(&sub) {
+			result = append(result, &sub)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Id < result[j].Id })
+	return result
+}
+
+func (r *MemoryWebhookRepository) GetAll() ([]*entity.WebhookSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.find(func(*entity.WebhookSubscription) bool { return true }), nil
+}
+
+func (r *MemoryWebhookRepository) GetById(id int) (*entity.WebhookSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := r.find(func(sub *entity.WebhookSubscription) bool { return sub.Id == id })
+	if len(result) == 0 {
+		return entity.NewWebhookSubscription(), nil
+	}
+	return result[0], nil
+}
+
+func (r *MemoryWebhookRepository) GetByEvent(event string) ([]*entity.WebhookSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.find(func(sub *entity.WebhookSubscription) bool {
+		for _, e := range sub.Events {
+			if e == event {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func (r *MemoryWebhookRepository) Insert(model *entity.WebhookSubscription) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastId++
+	sub := *model
+	sub.Id = r.lastId
+	sub.Events = append([]string(nil), model.Events...)
+	r.subscriptions[sub.Id] = sub
+	return sub.Id, nil
+}
+
+func (r *MemoryWebhookRepository) Delete(id int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.subscriptions[id]
+	delete(r.subscriptions, id)
+	return ok, nil
+}
+
+func (r *MemoryWebhookRepository) InsertDelivery(model *entity.WebhookDelivery) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delivery := *model
+	delivery.Id = len(r.deliveries) + 1
+	delivery.CreatedOn = time.Now()
+	r.deliveries = append(r.deliveries, delivery)
+	return delivery.Id, nil
+}
+
+// GetDeliveries returns the latest 100 attempts, newest first
+func (r *MemoryWebhookRepository) GetDeliveries(subscriptionId int) ([]*entity.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]*entity.WebhookDelivery, 0)
+	for i := len(r.deliveries) - 1; i >= 0 && len(result) < 100; i-- {
+		if r.deliveries[i].SubscriptionId == subscriptionId {
+			delivery := r.deliveries[i]
+			result = append(result, &delivery)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"threedee/database"
+	"threedee/entity"
+)
+
+type NotificationPreferenceRepository struct {
+}
+
+func NewNotificationPreferenceRepository() *NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{}
+}
+
+// GetByRequestor returns an empty preference when the requestor never set one
+func (*NotificationPreferenceRepository) GetByRequestor(requestor string) (*entity.NotificationPreference, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("select "+
+		"a.requestor,"+
+		"a.email,"+
+		"a.opt_out "+
+		"from tbl_m_notification_preference a where a.requestor = $1", requestor)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	item := entity.NewNotificationPreference()
+	for rows.Next() {
+		err := rows.Scan(
+			&item.Requestor,
+			&item.Email,
+			&item.OptOut,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (*NotificationPreferenceRepository) Upsert(model *entity.NotificationPreference) error {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.Exec("insert into tbl_m_notification_preference "+
+		"(requestor, email, opt_out) values ($1, $2, $3) "+
+		"on conflict (requestor) do update set "+
+		"email = excluded.email,"+
+		"opt_out = excluded.opt_out,"+
+		"modified_on = now()",
+		model.Requestor,
+		model.Email,
+		model.OptOut,
+	)
+	return err
+}
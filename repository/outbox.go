@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"threedee/database"
+	"threedee/entity"
+	"time"
+)
+
+type OutboxRepository struct {
+}
+
+func NewOutboxRepository() *OutboxRepository {
+	return &OutboxRepository{}
+}
+
+// insertOutboxEvent is called by the other repositories inside their own transaction
+func insertOutboxEvent(tx *sql.Tx, event string, aggregateId int, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("INSERT INTO tbl_t_outbox("+
+		"event,"+
+		"aggregate_id,"+
+		"payload) "+
+		"VALUES "+
+		"($1,"+
+		"$2,"+
+		"$3);",
+		event,
+		aggregateId,
+		string(payload))
+	return err
+}
+
+// GetPending returns the undelivered events of sink that have not used up their attempts
+// and are due, oldest first
+func (*OutboxRepository) GetPending(sink string, limit int, maxAttempts int, now time.Time) ([]*entity.OutboxEvent, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return queryPendingOutboxEvents(db, sink, limit, maxAttempts, now.UTC())
+}
+
+func (*OutboxRepository) MarkDelivered(id int, sink string, sinks int) error {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return runTransaction(db, func(tx *sql.Tx) error {
+		return markOutboxDelivered(tx, id, sink, sinks)
+	})
+}
+
+func (*OutboxRepository) MarkFailed(id int, sink string, reason string, retryAt time.Time) error {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return runTransaction(db, func(tx *sql.Tx) error {
+		return markOutboxFailed(tx, id, sink, reason, retryAt.UTC())
+	})
+}
+
+// queryPendingOutboxEvents is GetPending for Postgres and SQLite, now is a time.Time or a
+// sqliteTimeFormat string
+func queryPendingOutboxEvents(db querier, sink string, limit int, maxAttempts int, now interface{}) ([]*entity.OutboxEvent, error) {
+	rows, err := db.Query("select "+
+		"a.id,"+
+		"a.event,"+
+		"a.aggregate_id,"+
+		"a.payload,"+
+		"coalesce(s.attempts, 0),"+
+		"coalesce(s.last_error, ''),"+
+		"a.created_on "+
+		"from tbl_t_outbox a "+
+		"left join tbl_t_outbox_sink s on s.outbox_id = a.id and s.sink = $1 "+
+		"where a.delivered_on is null and s.delivered_on is null "+
+		"and coalesce(s.attempts, 0) < $2 "+
+		"and (s.next_attempt_on is null or s.next_attempt_on <= $3) "+
+		"order by a.id limit $4", sink, maxAttempts, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*entity.OutboxEvent, 0)
+	for rows.Next() {
+		item := entity.NewOutboxEvent()
+		var payload string
+		err := rows.Scan(
+			&item.Id,
+			&item.Event,
+			&item.AggregateId,
+			&payload,
+			&item.Attempts,
+			&item.LastError,
+			&item.CreatedOn,
+		)
+		if err != nil {
+			return nil, err
+		}
+		item.Payload = json.RawMessage(payload)
+		result = append(result, item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// markOutboxDelivered is MarkDelivered for Postgres and SQLite. Counting the attempt locks
+// the event, so the sink that delivers last sees the others and marks the event delivered.
+func markOutboxDelivered(tx *sql.Tx, id int, sink string, sinks int) error {
+	_, err := tx.Exec("INSERT INTO tbl_t_outbox_sink (outbox_id, sink, attempts, delivered_on) "+
+		"VALUES ($1, $2, 1, current_timestamp) "+
+		"ON CONFLICT (outbox_id, sink) DO UPDATE SET "+
+		"attempts = tbl_t_outbox_sink.attempts + 1,"+
+		"next_attempt_on = null,"+
+		"delivered_on = excluded.delivered_on;",
+		id,
+		sink)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE tbl_t_outbox SET attempts = attempts + 1 WHERE id = $1;", id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE tbl_t_outbox SET "+
+		"delivered_on = current_timestamp "+
+		"WHERE id = $1 and delivered_on is null "+
+		"and (select count(*) from tbl_t_outbox_sink s where s.outbox_id = $1 and s.delivered_on is not null) >= $2;",
+		id,
+		sinks)
+	return err
+}
+
+// markOutboxFailed is MarkFailed for Postgres and SQLite, the last error of the event tells
+// which sink failed
+func markOutboxFailed(tx *sql.Tx, id int, sink string, reason string, retryAt interface{}) error {
+	_, err := tx.Exec("INSERT INTO tbl_t_outbox_sink (outbox_id, sink, attempts, last_error, next_attempt_on) "+
+		"VALUES ($1, $2, 1, $3, $4) "+
+		"ON CONFLICT (outbox_id, sink) DO UPDATE SET "+
+		"attempts = tbl_t_outbox_sink.attempts + 1,"+
+		"last_error = excluded.last_error,"+
+		"next_attempt_on = excluded.next_attempt_on;",
+		id,
+		sink,
+		reason,
+		retryAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE tbl_t_outbox SET "+
+		"attempts = attempts + 1,"+
+		"last_error = $1 "+
+		"WHERE id = $2;",
+		sink+": "+reason,
+		id)
+	return err
+}
@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"database/sql"
+	"threedee/database"
+	"threedee/entity"
+	print_job "threedee/interfaces/print-job"
+	print_request "threedee/interfaces/print-request"
+)
+
+type PrintJobRepository struct {
+}
+
+func NewPrintJobRepository() *PrintJobRepository {
+	return &PrintJobRepository{}
+}
+
+func (*PrintJobRepository) GetByPrintRequest(printRequestId int) ([]*entity.PrintJob, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return queryPrintJobs(db, printJobsQuery, printRequestId)
+}
+
+func (*PrintJobRepository) GetById(id int) (*entity.PrintJob, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return queryPrintJob(db, id)
+}
+
+func (*PrintJobRepository) Insert(model *entity.PrintJob) (int, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	var id int
+	err = runTransaction(db, func(tx *sql.Tx) error {
+		id, err = insertPrintJob(tx, model)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (*PrintJobRepository) Update(model *entity.PrintJob) (bool, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+
+	return updatePrintJob(db, model)
+}
+
+func (*PrintJobRepository) GetEstimates(query *entity.EstimateQuery) ([]*entity.EstimateRow, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return queryEstimates(db, query)
+}
+
+// The print job queries work for Postgres and SQLite. Times are passed in UTC, so SQLite
+// stores and compares them in the same format.
+const (
+	printJobColumns = "select " +
+		"a.id," +
+		"a.print_request_id," +
+		"a.printer_id," +
+		"a.started_on," +
+		"a.ended_on," +
+		"a.duration," +
+		"a.filament_used," +
+		"a.outcome," +
+		"a.failure_reason," +
+		"a.created_on " +
+		"from tbl_t_print_job a "
+
+	printJobsQuery = printJobColumns + "where a.print_request_id = $1 order by a.id"
+
+	printJobQuery = printJobColumns + "where a.id = $1"
+
+	activePrintRequestQuery = "select count(*) from tbl_m_3d_print_request a where a.id = $1 and a.is_active = true"
+
+	insertPrintJobQuery = "INSERT INTO tbl_t_print_job(" +
+		"print_request_id," +
+		"printer_id," +
+		"started_on," +
+		"ended_on," +
+		"duration," +
+		"filament_used," +
+		"outcome," +
+		"failure_reason) " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id;"
+
+	updatePrintJobQuery = "UPDATE tbl_t_print_job SET " +
+		"printer_id = $1," +
+		"started_on = $2," +
+		"ended_on = $3," +
+		"duration = $4," +
+		"filament_used = $5," +
+		"outcome = $6," +
+		"failure_reason = $7," +
+		"modified_on = current_timestamp " +
+		"WHERE id = $8;"
+
+	estimatesQuery = "select " +
+		"a.id," +
+		"a.item_name," +
+		"count(*)," +
+		"a.est_duration," +
+		"sum(b.duration)," +
+		"a.est_weight," +
+		"sum(b.filament_used) " +
+		"from tbl_t_print_job b join tbl_m_3d_print_request a on a.id = b.print_request_id " +
+		"where a.is_active = true and b.outcome = $1 and b.ended_on >= $2 and b.ended_on < $3 " +
+		"group by a.id, a.item_name, a.est_duration, a.est_weight order by a.id"
+)
+
+func queryPrintJobs(db querier, query string, id int) ([]*entity.PrintJob, error) {
+	rows, err := db.Query(query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*entity.PrintJob, 0)
+	for rows.Next() {
+		item := entity.NewPrintJob()
+		var endedOn sql.NullTime
+		err := rows.Scan(
+			&item.Id,
+			&item.PrintRequestId,
+			&item.PrinterId,
+			&item.StartedOn,
+			&endedOn,
+			&item.Duration,
+			&item.FilamentUsed,
+			&item.Outcome,
+			&item.FailureReason,
+			&item.CreatedOn,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if endedOn.Valid {
+			item.EndedOn = &endedOn.Time
+		}
+		result = append(result, item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func queryPrintJob(db querier, id int) (*entity.PrintJob, error) {
+	result, err := queryPrintJobs(db, printJobQuery, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return &entity.PrintJob{}, nil
+	}
+	return result[0], nil
+}
+
+// insertPrintJob adds model in tx when its request is active
+func insertPrintJob(tx *sql.Tx, model *entity.PrintJob) (int, error) {
+	var active int
+	err := tx.QueryRow(activePrintRequestQuery, model.PrintRequestId).Scan(&active)
+	if err != nil {
+		return 0, err
+	}
+	if active == 0 {
+		return 0, &print_request.NotFoundError{Id: model.PrintRequestId}
+	}
+
+	var id int
+	err = tx.QueryRow(insertPrintJobQuery,
+		model.PrintRequestId,
+		model.PrinterId,
+		model.StartedOn.UTC(),
+		endedOn(model),
+		model.Duration,
+		model.FilamentUsed,
+		model.Outcome,
+		model.FailureReason).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func updatePrintJob(db querier, model *entity.PrintJob) (bool, error) {
+	result, err := db.Exec(updatePrintJobQuery,
+		model.PrinterId,
+		model.StartedOn.UTC(),
+		endedOn(model),
+		model.Duration,
+		model.FilamentUsed,
+		model.Outcome,
+		model.FailureReason,
+		model.Id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected == 0 {
+		return false, &print_job.NotFoundError{Id: model.Id}
+	}
+	return true, nil
+}
+
+// endedOn is the end of a job in UTC, or NULL while it is printing
+func endedOn(model *entity.PrintJob) interface{} {
+	if model.EndedOn == nil {
+		return nil
+	}
+	return model.EndedOn.UTC()
+}
+
+func queryEstimates(db querier, query *entity.EstimateQuery) ([]*entity.EstimateRow, error) {
+	rows, err := db.Query(estimatesQuery, entity.JobSucceeded, query.From.UTC(), query.To.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*entity.EstimateRow, 0)
+	for rows.Next() {
+		item := &entity.EstimateRow{}
+		err := rows.Scan(
+			&item.PrintRequestId,
+			&item.ItemName,
+			&item.Jobs,
+			&item.EstimatedDuration,
+			&item.ActualDuration,
+			&item.EstimatedWeight,
+			&item.ActualWeight,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -1,36 +1,270 @@
 package repository
 
 import (
-	"threedee/database"
+	"database/sql"
+	"strconv"
+	"strings"
 	"threedee/entity"
+	print_request "threedee/interfaces/print-request"
+	"threedee/utility/search"
+	"time"
 )
 
 // This is the actual repository code that must follow the interface constraints.
 
 type PrintRequestRepository struct {
+	tx *sql.Tx // set on the repository passed to WithTransaction
 }
 
 func NewPrintRequestRepository() *PrintRequestRepository {
 	return &PrintRequestRepository{}
 }
 
-func (*PrintRequestRepository) GetAll() ([]*entity.PrintRequest, error) {
-	db, err := database.NewPostgresql()
+func (r *PrintRequestRepository) GetAll(filter *entity.PrintRequestFilter) ([]*entity.PrintRequest, error) {
+	result := make([]*entity.PrintRequest, 0)
+	err := r.Each(filter, func(item *entity.PrintRequest) error {
+		result = append(result, item)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer db.Close()
+	return result, nil
+}
 
-	rows, err := db.Query("select " +
-		"a.id," +
-		"a.item_name," +
-		"a.est_weight," +
-		"a.est_filament_length," +
-		"a.est_duration," +
-		"a.file_url," +
-		"a.requestor," +
-		"a.status " +
-		"from tbl_m_3d_print_request a")
+func (r *PrintRequestRepository) Each(filter *entity.PrintRequestFilter, fn func(item *entity.PrintRequest) error) error {
+	db, closeDb, err := connect(r.tx)
+	if err != nil {
+		return err
+	}
+	defer closeDb()
+
+	where, args := printRequestWhere(filter)
+	rows, err := db.Query("select "+
+		"a.id,"+
+		"a.item_name,"+
+		"a.est_weight,"+
+		"a.est_filament_length,"+
+		"a.est_duration,"+
+		"a.file_url,"+
+		"a.file_key,"+
+		"a.file_hash,"+
+		"a.duplicate_of,"+
+		"a.requestor,"+
+		"a.notes,"+
+		"a.reviewer,"+
+		"a.material,"+
+		"a.color,"+
+		"a.layer_height,"+
+		"a.infill,"+
+		"a.supports,"+
+		"a.nozzle_size,"+
+		"a.quantity,"+
+		"a.est_cost,"+
+		"a.project_id,"+
+		"a.status "+
+		"from tbl_m_3d_print_request a where "+where+" order by a.id", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := entity.NewPrintRequest()
+		err := rows.Scan(
+			&item.Id,
+			&item.ItemName,
+			&item.EstimatedWeight,
+			&item.EstimatedFilamentLength,
+			&item.EstimatedDuration,
+			&item.FileUrl,
+			&item.FileKey,
+			&item.FileHash,
+			&item.DuplicateOf,
+			&item.Requestor,
+			&item.Notes,
+			&item.Reviewer,
+			&item.Material,
+			&item.Color,
+			&item.LayerHeight,
+			&item.Infill,
+			&item.Supports,
+			&item.NozzleSize,
+			&item.Quantity,
+			&item.EstimatedCost,
+			&item.ProjectId,
+			&item.Status,
+		)
+		if err != nil {
+			return err
+		}
+		err = fn(item)
+		if err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// markedHighlight HTML escapes the fields highlighted by the database, see search.Marked
+func markedHighlight(h entity.Highlight) entity.Highlight {
+	return entity.Highlight{
+		ItemName:  search.Marked(h.ItemName),
+		Requestor: search.Marked(h.Requestor),
+		Notes:     search.Marked(h.Notes),
+	}
+}
+
+// terminalStatuses is entity.TerminalStatuses as a SQL list, for Postgres and SQLite
+var terminalStatuses = "('" + strings.Join(entity.TerminalStatuses, "', '") + "')"
+
+// printRequestWhere returns the condition of the active requests matching filter and its
+// $N arguments, for Postgres and SQLite
+func printRequestWhere(filter *entity.PrintRequestFilter) (string, []interface{}) {
+	where := "a.is_active = true"
+	args := make([]interface{}, 0)
+	if filter == nil {
+		return where, args
+	}
+	if filter.Requestor != "" {
+		args = append(args, filter.Requestor)
+		where += " and a.requestor = $" + strconv.Itoa(len(args))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where += " and a.status = $" + strconv.Itoa(len(args))
+	}
+	if filter.Material != "" {
+		args = append(args, filter.Material)
+		where += " and a.material = $" + strconv.Itoa(len(args))
+	}
+	if filter.Color != "" {
+		args = append(args, filter.Color)
+		where += " and lower(a.color) = lower($" + strconv.Itoa(len(args)) + ")"
+	}
+	if filter.Supports != nil {
+		args = append(args, *filter.Supports)
+		where += " and a.supports = $" + strconv.Itoa(len(args))
+	}
+	if filter.ProjectId != 0 {
+		args = append(args, filter.ProjectId)
+		where += " and a.project_id = $" + strconv.Itoa(len(args))
+	}
+	return where, args
+}
+
+// GetById locks the row in a transaction, so a change made from what it read can not
+// overwrite a concurrent one
+func (r *PrintRequestRepository) GetById(id int) (*entity.PrintRequest, error) {
+	db, closeDb, err := connect(r.tx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeDb()
+
+	rows, err := db.Query("select "+
+		"a.id,"+
+		"a.item_name,"+
+		"a.est_weight,"+
+		"a.est_filament_length,"+
+		"a.est_duration,"+
+		"a.file_url,"+
+		"a.file_key,"+
+		"a.file_hash,"+
+		"a.duplicate_of,"+
+		"a.requestor,"+
+		"a.notes,"+
+		"a.reviewer,"+
+		"a.material,"+
+		"a.color,"+
+		"a.layer_height,"+
+		"a.infill,"+
+		"a.supports,"+
+		"a.nozzle_size,"+
+		"a.quantity,"+
+		"a.est_cost,"+
+		"a.project_id,"+
+		"a.status "+
+		"from tbl_m_3d_print_request a where a.id = $1 and a.is_active = true"+lockInTransaction(r.tx), id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	item := entity.NewPrintRequest()
+	for rows.Next() {
+		err := rows.Scan(
+			&item.Id,
+			&item.ItemName,
+			&item.EstimatedWeight,
+			&item.EstimatedFilamentLength,
+			&item.EstimatedDuration,
+			&item.FileUrl,
+			&item.FileKey,
+			&item.FileHash,
+			&item.DuplicateOf,
+			&item.Requestor,
+			&item.Notes,
+			&item.Reviewer,
+			&item.Material,
+			&item.Color,
+			&item.LayerHeight,
+			&item.Infill,
+			&item.Supports,
+			&item.NozzleSize,
+			&item.Quantity,
+			&item.EstimatedCost,
+			&item.ProjectId,
+			&item.Status,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// GetActiveByFileHash returns the requests of requestor for the same file that are still
+// in the queue, oldest first.
+func (r *PrintRequestRepository) GetActiveByFileHash(hash string, requestor string) ([]*entity.PrintRequest, error) {
+	db, closeDb, err := connect(r.tx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeDb()
+
+	rows, err := db.Query("select "+
+		"a.id,"+
+		"a.item_name,"+
+		"a.est_weight,"+
+		"a.est_filament_length,"+
+		"a.est_duration,"+
+		"a.file_url,"+
+		"a.file_key,"+
+		"a.file_hash,"+
+		"a.duplicate_of,"+
+		"a.requestor,"+
+		"a.notes,"+
+		"a.reviewer,"+
+		"a.material,"+
+		"a.color,"+
+		"a.layer_height,"+
+		"a.infill,"+
+		"a.supports,"+
+		"a.nozzle_size,"+
+		"a.quantity,"+
+		"a.est_cost,"+
+		"a.project_id,"+
+		"a.status "+
+		"from tbl_m_3d_print_request a "+
+		"where a.file_hash = $1 and a.requestor = $2 "+
+		"and a.is_active = true and a.status not in "+terminalStatuses+" "+
+		"order by a.id", hash, requestor)
 	if err != nil {
 		return nil, err
 	}
@@ -46,7 +280,21 @@ func (*PrintRequestRepository) GetAll() ([]*entity.PrintRequest, error) {
 			&item.EstimatedFilamentLength,
 			&item.EstimatedDuration,
 			&item.FileUrl,
+			&item.FileKey,
+			&item.FileHash,
+			&item.DuplicateOf,
 			&item.Requestor,
+			&item.Notes,
+			&item.Reviewer,
+			&item.Material,
+			&item.Color,
+			&item.LayerHeight,
+			&item.Infill,
+			&item.Supports,
+			&item.NozzleSize,
+			&item.Quantity,
+			&item.EstimatedCost,
+			&item.ProjectId,
 			&item.Status,
 		)
 		if err != nil {
@@ -62,12 +310,40 @@ func (*PrintRequestRepository) GetAll() ([]*entity.PrintRequest, error) {
 	return result, nil
 }
 
-func (*PrintRequestRepository) GetById(id int) (*entity.PrintRequest, error) {
-	db, err := database.NewPostgresql()
+// quotaLockClass is the first key of the advisory locks GetQuotaUsage takes, the second is
+// the hash of the requestor
+const quotaLockClass = 1
+
+// GetQuotaUsage locks the requestor with a transaction level advisory lock before counting,
+// a lock on their rows would not stop a concurrent insert.
+func (r *PrintRequestRepository) GetQuotaUsage(requestor string, monthStart time.Time, weekStart time.Time) (*entity.QuotaUsage, error) {
+	db, closeDb, err := connect(r.tx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeDb()
+
+	if r.tx != nil {
+		_, err = db.Exec("select pg_advisory_xact_lock($1, hashtext($2))", quotaLockClass, requestor)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return queryQuotaUsage(db, requestor, monthStart.UTC(), weekStart.UTC())
+}
+
+// Search ranks matches in item_name above requestor above notes, newest first on a tie
+func (r *PrintRequestRepository) Search(query string, limit int) ([]*entity.SearchResult, error) {
+	terms := search.Terms(query)
+	if len(terms) == 0 {
+		return make([]*entity.SearchResult, 0), nil
+	}
+
+	db, closeDb, err := connect(r.tx)
 	if err != nil {
 		return nil, err
 	}
-	defer db.Close()
+	defer closeDb()
 
 	rows, err := db.Query("select "+
 		"a.id,"+
@@ -76,16 +352,40 @@ func (*PrintRequestRepository) GetById(id int) (*entity.PrintRequest, error) {
 		"a.est_filament_length,"+
 		"a.est_duration,"+
 		"a.file_url,"+
+		"a.file_key,"+
+		"a.file_hash,"+
+		"a.duplicate_of,"+
 		"a.requestor,"+
-		"a.status "+
-		"from tbl_m_3d_print_request a where a.id = $1", id)
+		"a.notes,"+
+		"a.reviewer,"+
+		"a.material,"+
+		"a.color,"+
+		"a.layer_height,"+
+		"a.infill,"+
+		"a.supports,"+
+		"a.nozzle_size,"+
+		"a.quantity,"+
+		"a.est_cost,"+
+		"a.project_id,"+
+		"a.status,"+
+		"ts_rank(a.search_vector, q) as rank,"+
+		"ts_headline('simple', a.item_name, q, $2),"+
+		"ts_headline('simple', a.requestor, q, $2),"+
+		"ts_headline('simple', a.notes, q, $2) "+
+		"from tbl_m_3d_print_request a, to_tsquery('simple', $1) q "+
+		"where a.is_active = true and a.search_vector @@ q "+
+		"order by rank desc, a.id desc limit $3",
+		search.TsQuery(terms),
+		`StartSel="`+search.StartMark+`", StopSel="`+search.StopMark+`", HighlightAll=true`,
+		limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	item := entity.NewPrintRequest()
+	result := make([]*entity.SearchResult, 0)
 	for rows.Next() {
+		item := &entity.SearchResult{PrintRequest: entity.NewPrintRequest()}
 		err := rows.Scan(
 			&item.Id,
 			&item.ItemName,
@@ -93,99 +393,321 @@ func (*PrintRequestRepository) GetById(id int) (*entity.PrintRequest, error) {
 			&item.EstimatedFilamentLength,
 			&item.EstimatedDuration,
 			&item.FileUrl,
+			&item.FileKey,
+			&item.FileHash,
+			&item.DuplicateOf,
 			&item.Requestor,
+			&item.Notes,
+			&item.Reviewer,
+			&item.Material,
+			&item.Color,
+			&item.LayerHeight,
+			&item.Infill,
+			&item.Supports,
+			&item.NozzleSize,
+			&item.Quantity,
+			&item.EstimatedCost,
+			&item.ProjectId,
 			&item.Status,
+			&item.Rank,
+			&item.Highlights.ItemName,
+			&item.Highlights.Requestor,
+			&item.Highlights.Notes,
 		)
 		if err != nil {
 			return nil, err
 		}
+		item.Highlights = markedHighlight(item.Highlights)
+		result = append(result, item)
 	}
 	err = rows.Err()
 	if err != nil {
 		return nil, err
 	}
 
-	return item, nil
+	return result, nil
 }
 
-func (*PrintRequestRepository) Insert(model *entity.PrintRequest) (int, error) {
-	db, err := database.NewPostgresql()
-	if err != nil {
-		return 0, err
-	}
-	defer db.Close()
-
-	var lastInsertId *int
-	err = db.QueryRow("INSERT INTO tbl_m_3d_print_request("+
-		"item_name,"+
-		"est_weight,"+
-		"est_filament_length,"+
-		"est_duration,"+
-		"file_url,"+
-		"requestor) "+
-		"VALUES "+
-		"($1,"+
-		"$2,"+
-		"$3,"+
-		"$4,"+
-		"$5,"+
-		"$6) "+
-		"RETURNING id;",
-		model.ItemName,
-		model.EstimatedWeight,
-		model.EstimatedFilamentLength,
-		model.EstimatedDuration,
-		model.FileUrl,
-		model.Requestor).Scan(&lastInsertId)
+// WithTransaction runs fn with a repository whose calls all share one transaction. It is
+// committed when fn returns nil and rolled back on any error. Calling WithTransaction on
+// that repository again joins the same transaction.
+func (r *PrintRequestRepository) WithTransaction(fn func(repo print_request.PrintRequestRepositoryInterface) error) error {
+	return inTransaction(r.tx, func(tx *sql.Tx) error {
+		return fn(&PrintRequestRepository{tx: tx})
+	})
+}
+
+// Insert, Update and Delete write the matching outbox event in the same transaction as the
+// change itself, so an event is never lost and never announced for a rolled back change.
+
+func (r *PrintRequestRepository) Insert(model *entity.PrintRequest) (int, error) {
+	created := *model
+	err := inTransaction(r.tx, func(tx *sql.Tx) error {
+		err := tx.QueryRow("INSERT INTO tbl_m_3d_print_request("+
+			"item_name,"+
+			"est_weight,"+
+			"est_filament_length,"+
+			"est_duration,"+
+			"file_url,"+
+			"file_key,"+
+			"file_hash,"+
+			"duplicate_of,"+
+			"requestor,"+
+			"notes,"+
+			"status,"+
+			"reviewer,"+
+			"material,"+
+			"color,"+
+			"layer_height,"+
+			"infill,"+
+			"supports,"+
+			"nozzle_size,"+
+			"quantity,"+
+			"est_cost,"+
+			"project_id) "+
+			"VALUES "+
+			"($1,"+
+			"$2,"+
+			"$3,"+
+			"$4,"+
+			"$5,"+
+			"$6,"+
+			"$7,"+
+			"$8,"+
+			"$9,"+
+			"$10,"+
+			"$11,"+
+			"$12,"+
+			"$13,"+
+			"$14,"+
+			"$15,"+
+			"$16,"+
+			"$17,"+
+			"$18,"+
+			"$19,"+
+			"$20,"+
+			"$21) "+
+			"RETURNING id, status;",
+			model.ItemName,
+			model.EstimatedWeight,
+			model.EstimatedFilamentLength,
+			model.EstimatedDuration,
+			model.FileUrl,
+			model.FileKey,
+			model.FileHash,
+			model.DuplicateOf,
+			model.Requestor,
+			model.Notes,
+			entity.StatusPendingReview,
+			model.Reviewer,
+			model.Material,
+			model.Color,
+			model.LayerHeight,
+			model.Infill,
+			model.Supports,
+			model.NozzleSize,
+			model.Quantity,
+			model.EstimatedCost,
+			model.ProjectId).Scan(&created.Id, &created.Status)
+		if err != nil {
+			return err
+		}
+
+		return insertOutboxEvent(tx, entity.EventCreated, created.Id, &created)
+	})
 	if err != nil {
 		return 0, err
 	}
-	return *lastInsertId, nil
+	return created.Id, nil
 }
 
-func (*PrintRequestRepository) Update(model *entity.PrintRequest) (bool, error) {
-	db, err := database.NewPostgresql()
+func (r *PrintRequestRepository) Update(model *entity.PrintRequest) (bool, error) {
+	err := inTransaction(r.tx, func(tx *sql.Tx) error {
+		// lock the row so the previous status is still true when the event is written
+		var previousStatus string
+		err := tx.QueryRow("SELECT status FROM tbl_m_3d_print_request WHERE id = $1 AND is_active = true FOR UPDATE;", model.Id).Scan(&previousStatus)
+		if err == sql.ErrNoRows {
+			return &print_request.NotFoundError{Id: model.Id}
+		}
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.Exec("UPDATE tbl_m_3d_print_request SET "+
+			"item_name = $1,"+
+			"est_weight = $2,"+
+			"est_filament_length = $3,"+
+			"est_duration = $4,"+
+			"file_url = $5,"+
+			"file_key = $6,"+
+			"file_hash = $7,"+
+			"duplicate_of = $8,"+
+			"requestor = $9,"+
+			"notes = $10,"+
+			"status = $11,"+
+			"reviewer = $12,"+
+			"material = $13,"+
+			"color = $14,"+
+			"layer_height = $15,"+
+			"infill = $16,"+
+			"supports = $17,"+
+			"nozzle_size = $18,"+
+			"quantity = $19,"+
+			"est_cost = $20,"+
+			"project_id = $21 "+
+			"WHERE id = $22 AND is_active = true;",
+			model.ItemName,
+			model.EstimatedWeight,
+			model.EstimatedFilamentLength,
+			model.EstimatedDuration,
+			model.FileUrl,
+			model.FileKey,
+			model.FileHash,
+			model.DuplicateOf,
+			model.Requestor,
+			model.Notes,
+			model.Status,
+			model.Reviewer,
+			model.Material,
+			model.Color,
+			model.LayerHeight,
+			model.Infill,
+			model.Supports,
+			model.NozzleSize,
+			model.Quantity,
+			model.EstimatedCost,
+			model.ProjectId,
+			model.Id)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return &print_request.NotFoundError{Id: model.Id}
+		}
+
+		if previousStatus != model.Status {
+			return insertOutboxEvent(tx, entity.EventStatusChanged, model.Id, entity.StatusChangedEvent{PrintRequest: model, PreviousStatus: previousStatus})
+		}
+		return insertOutboxEvent(tx, entity.EventUpdated, model.Id, model)
+	})
 	if err != nil {
 		return false, err
 	}
-	defer db.Close()
-
-	_, err = db.Query("UPDATE tbl_m_3d_print_request SET "+
-		"item_name = $1,"+
-		"est_weight = $2,"+
-		"est_filament_length = $3,"+
-		"est_duration = $4,"+
-		"file_url = $5,"+
-		"requestor = $6,"+
-		"status = $7 "+
-		"WHERE id = $8;",
-		model.ItemName,
-		model.EstimatedWeight,
-		model.EstimatedFilamentLength,
-		model.EstimatedDuration,
-		model.FileUrl,
-		model.Requestor,
-		model.Status,
-		model.Id)
+	return true, nil
+}
+
+func (r *PrintRequestRepository) Delete(id int) (bool, error) {
+	err := inTransaction(r.tx, func(tx *sql.Tx) error {
+		// lock the row so the event carries the state it was deleted in
+		deleted := entity.DeletedEvent{Id: id}
+		err := tx.QueryRow("SELECT requestor, status FROM tbl_m_3d_print_request WHERE id = $1 AND is_active = true FOR UPDATE;", id).Scan(&deleted.Requestor, &deleted.Status)
+		if err == sql.ErrNoRows {
+			return &print_request.NotFoundError{Id: id}
+		}
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.Exec("UPDATE tbl_m_3d_print_request SET "+
+			"is_active = false "+
+			"WHERE id = $1 AND is_active = true;",
+			id)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return &print_request.NotFoundError{Id: id}
+		}
+
+		return insertOutboxEvent(tx, entity.EventDeleted, id, deleted)
+	})
 	if err != nil {
 		return false, err
 	}
 	return true, nil
 }
 
-func (*PrintRequestRepository) Delete(id int) (bool, error) {
-	db, err := database.NewPostgresql()
+func (r *PrintRequestRepository) GetReviewThread(printRequestId int) ([]*entity.ReviewEntry, error) {
+	db, closeDb, err := connect(r.tx)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-	defer db.Close()
+	defer closeDb()
 
-	_, err = db.Query("UPDATE tbl_m_3d_print_request SET "+
-		"is_active = false "+
-		"WHERE id = $1;",
-		id)
+	rows, err := db.Query(reviewThreadQuery, printRequestId)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-	return true, nil
+	defer rows.Close()
+
+	return scanReviewEntries(rows)
+}
+
+func (r *PrintRequestRepository) AddReviewEntry(entry *entity.ReviewEntry) (int, error) {
+	var id int
+	err := inTransaction(r.tx, func(tx *sql.Tx) error {
+		err := tx.QueryRow(insertReviewEntryQuery,
+			entry.PrintRequestId,
+			entry.Author,
+			entry.Action,
+			entry.Comment).Scan(&id)
+		if err == sql.ErrNoRows {
+			return &print_request.NotFoundError{Id: entry.PrintRequestId}
+		}
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// The review queries work for Postgres and SQLite
+const (
+	reviewThreadQuery = "select " +
+		"a.id," +
+		"a.print_request_id," +
+		"a.author," +
+		"a.action," +
+		"a.comment," +
+		"a.created_on " +
+		"from tbl_t_review a where a.print_request_id = $1 order by a.id"
+
+	// inserts nothing when the request is not active
+	insertReviewEntryQuery = "INSERT INTO tbl_t_review(print_request_id, author, action, comment) " +
+		"SELECT a.id, $2, $3, $4 FROM tbl_m_3d_print_request a WHERE a.id = $1 AND a.is_active = true " +
+		"RETURNING id;"
+)
+
+func scanReviewEntries(rows *sql.Rows) ([]*entity.ReviewEntry, error) {
+	result := make([]*entity.ReviewEntry, 0)
+	for rows.Next() {
+		item := entity.NewReviewEntry()
+		err := rows.Scan(
+			&item.Id,
+			&item.PrintRequestId,
+			&item.Author,
+			&item.Action,
+			&item.Comment,
+			&item.CreatedOn,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	err := rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"threedee/database"
+	"threedee/entity"
+)
+
+type PrinterRepository struct {
+}
+
+func NewPrinterRepository() *PrinterRepository {
+	return &PrinterRepository{}
+}
+
+// GetAll returns the active printers of the registry
+func (*PrinterRepository) GetAll() ([]*entity.Printer, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("select " +
+		"a.id," +
+		"a.name," +
+		"a.build_width," +
+		"a.build_depth," +
+		"a.build_height " +
+		"from tbl_m_printer a where a.is_active = true order by a.id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*entity.Printer, 0)
+	for rows.Next() {
+		item := entity.NewPrinter()
+		err := rows.Scan(
+			&item.Id,
+			&item.Name,
+			&item.BuildWidth,
+			&item.BuildDepth,
+			&item.BuildHeight,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"threedee/database"
+	"threedee/entity"
+	"threedee/interfaces/project"
+)
+
+type ProjectRepository struct {
+}
+
+func NewProjectRepository() *ProjectRepository {
+	return &ProjectRepository{}
+}
+
+func (*ProjectRepository) GetAll() ([]*entity.Project, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return queryProjects(db, 0)
+}
+
+func (*ProjectRepository) GetById(id int) (*entity.Project, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return queryProject(db, id)
+}
+
+func (*ProjectRepository) Insert(model *entity.Project) (int, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	return insertProject(db, model)
+}
+
+func (*ProjectRepository) Update(model *entity.Project) (bool, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+
+	return updateProject(db, model)
+}
+
+func (*ProjectRepository) Delete(id int) (bool, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+
+	return deleteProject(db, id)
+}
+
+// The project queries work for Postgres and SQLite, $1 is the project id or 0 for all
+const (
+	projectsQuery = "select " +
+		"a.id," +
+		"a.name," +
+		"a.owner," +
+		"a.description," +
+		"a.created_on " +
+		"from tbl_m_project a where a.is_active = true and ($1 = 0 or a.id = $1) order by a.id"
+
+	// the parts of the projects by status, summed by the database
+	projectPartsQuery = "select " +
+		"b.project_id," +
+		"b.status," +
+		"count(*)," +
+		"sum(b.est_weight)," +
+		"sum(b.est_filament_length)," +
+		"sum(b.est_duration)," +
+		"sum(b.est_cost) " +
+		"from tbl_m_3d_print_request b join tbl_m_project a on a.id = b.project_id " +
+		"where b.is_active = true and a.is_active = true and ($1 = 0 or a.id = $1) " +
+		"group by b.project_id, b.status"
+
+	insertProjectQuery = "INSERT INTO tbl_m_project(name, owner, description) VALUES ($1, $2, $3) RETURNING id;"
+
+	updateProjectQuery = "UPDATE tbl_m_project SET " +
+		"name = $1," +
+		"owner = $2," +
+		"description = $3," +
+		"modified_on = current_timestamp " +
+		"WHERE id = $4 AND is_active = true;"
+
+	deleteProjectQuery = "UPDATE tbl_m_project SET is_active = false, modified_on = current_timestamp " +
+		"WHERE id = $1 AND is_active = true " +
+		"AND NOT EXISTS (SELECT 1 FROM tbl_m_3d_print_request b WHERE b.project_id = $1 AND b.is_active = true);"
+
+	activeProjectQuery = "select count(*) from tbl_m_project a where a.id = $1 and a.is_active = true"
+)
+
+// queryProjects reads the active projects, or the one with id when it is not 0, and then
+// the totals of their parts
+func queryProjects(db querier, id int) ([]*entity.Project, error) {
+	rows, err := db.Query(projectsQuery, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*entity.Project, 0)
+	byId := make(map[int]*entity.Project)
+	for rows.Next() {
+		item := entity.NewProject()
+		err := rows.Scan(
+			&item.Id,
+			&item.Name,
+			&item.Owner,
+			&item.Description,
+			&item.CreatedOn,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+		byId[item.Id] = item
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	parts, err := db.Query(projectPartsQuery, id)
+	if err != nil {
+		return nil, err
+	}
+	defer parts.Close()
+
+	for parts.Next() {
+		var projectId, count, duration int
+		var status string
+		var weight, filamentLength, cost float32
+		err := parts.Scan(&projectId, &status, &count, &weight, &filamentLength, &duration, &cost)
+		if err != nil {
+			return nil, err
+		}
+		if item, ok := byId[projectId]; ok {
+			item.AddParts(status, count, weight, filamentLength, duration, cost)
+		}
+	}
+	err = parts.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func queryProject(db querier, id int) (*entity.Project, error) {
+	if id == 0 {
+		return entity.NewProject(), nil
+	}
+	result, err := queryProjects(db, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return entity.NewProject(), nil
+	}
+	return result[0], nil
+}
+
+func insertProject(db querier, model *entity.Project) (int, error) {
+	var id int
+	err := db.QueryRow(insertProjectQuery,
+		model.Name,
+		model.Owner,
+		model.Description).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func updateProject(db querier, model *entity.Project) (bool, error) {
+	result, err := db.Exec(updateProjectQuery,
+		model.Name,
+		model.Owner,
+		model.Description,
+		model.Id)
+	return affectedProject(result, err, model.Id)
+}
+
+// deleteProject returns a HasPartsError when the project is still active after nothing was
+// deleted
+func deleteProject(db querier, id int) (bool, error) {
+	result, err := db.Exec(deleteProjectQuery, id)
+	deleted, err := affectedProject(result, err, id)
+	var notFound *project.NotFoundError
+	if !errors.As(err, &notFound) {
+		return deleted, err
+	}
+
+	var active int
+	err = db.QueryRow(activeProjectQuery, id).Scan(&active)
+	if err != nil {
+		return false, err
+	}
+	if active > 0 {
+		return false, &project.HasPartsError{Id: id}
+	}
+	return false, notFound
+}
+
+// affectedProject turns the result of an update of project id into a NotFoundError when no
+// active project was changed
+func affectedProject(result sql.Result, err error, id int) (bool, error) {
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected == 0 {
+		return false, &project.NotFoundError{Id: id}
+	}
+	return true, nil
+}
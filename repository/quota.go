@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"threedee/database"
+	"threedee/entity"
+	"time"
+)
+
+type QuotaRepository struct {
+}
+
+func NewQuotaRepository() *QuotaRepository {
+	return &QuotaRepository{}
+}
+
+func (*QuotaRepository) GetByRequestor(requestor string) (*entity.Quota, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("select "+
+		"a.requestor,"+
+		"a.max_active,"+
+		"a.max_grams_per_month,"+
+		"a.max_hours_per_week "+
+		"from tbl_m_quota a where a.requestor = $1", requestor)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	item := entity.NewQuota()
+	for rows.Next() {
+		err := rows.Scan(
+			&item.Requestor,
+			&item.MaxActive,
+			&item.MaxGramsPerMonth,
+			&item.MaxHoursPerWeek,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (*QuotaRepository) Upsert(model *entity.Quota) error {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.Exec("insert into tbl_m_quota "+
+		"(requestor, max_active, max_grams_per_month, max_hours_per_week) values ($1, $2, $3, $4) "+
+		"on conflict (requestor) do update set "+
+		"max_active = excluded.max_active,"+
+		"max_grams_per_month = excluded.max_grams_per_month,"+
+		"max_hours_per_week = excluded.max_hours_per_week,"+
+		"modified_on = now()",
+		model.Requestor,
+		model.MaxActive,
+		model.MaxGramsPerMonth,
+		model.MaxHoursPerWeek,
+	)
+	return err
+}
+
+func (*QuotaRepository) GetUsage(requestor string, monthStart time.Time, weekStart time.Time) (*entity.QuotaUsage, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return queryQuotaUsage(db, requestor, monthStart.UTC(), weekStart.UTC())
+}
+
+// queryQuotaUsage runs quotaUsageQuery, with the period starts in the time format of db
+func queryQuotaUsage(db querier, requestor string, monthStart interface{}, weekStart interface{}) (*entity.QuotaUsage, error) {
+	item := &entity.QuotaUsage{}
+	var seconds int
+	err := db.QueryRow(quotaUsageQuery, requestor, monthStart, weekStart).Scan(&item.Active, &item.GramsThisMonth, &seconds)
+	if err != nil {
+		return nil, err
+	}
+	item.HoursThisWeek = float64(seconds) / 3600
+	return item, nil
+}
+
+// quotaUsageQuery counts like entity.QuotaUsage, for Postgres and SQLite
+var quotaUsageQuery = "select " +
+	"count(case when a.status not in " + terminalStatuses + " then 1 end)," +
+	"coalesce(sum(case when a.created_on >= $2 then a.est_weight end), 0)," +
+	"coalesce(sum(case when a.created_on >= $3 then a.est_duration end), 0) " +
+	"from tbl_m_3d_print_request a " +
+	"where a.requestor = $1 and a.is_active = true and a.status <> 'rejected'"
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"threedee/database"
+	"threedee/entity"
+)
+
+type ReportRepository struct {
+}
+
+func NewReportRepository() *ReportRepository {
+	return &ReportRepository{}
+}
+
+func (*ReportRepository) GetUsage(query *entity.UsageQuery) ([]*entity.UsageRow, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	requestor, status := usageGroups(query)
+	rows, err := db.Query("select "+
+		"to_char(date_trunc($3, a.created_on at time zone 'UTC'), 'YYYY-MM-DD') as period,"+
+		requestor+" as requestor,"+
+		status+" as status,"+
+		"count(*),"+
+		"sum(a.est_weight),"+
+		"sum(a.est_filament_length),"+
+		"sum(a.est_duration) "+
+		"from tbl_m_3d_print_request a "+
+		"where a.is_active = true and a.created_on >= $1 and a.created_on < $2 "+
+		"group by 1, 2, 3 order by 1, 2, 3",
+		query.From.UTC(),
+		query.To.UTC(),
+		query.Interval)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanUsageRows(rows)
+}
+
+// usageGroups returns the requestor and status columns, or empty strings to not group by them
+func usageGroups(query *entity.UsageQuery) (string, string) {
+	requestor, status := "''", "''"
+	if query.ByRequestor {
+		requestor = "a.requestor"
+	}
+	if query.ByStatus {
+		status = "a.status"
+	}
+	return requestor, status
+}
+
+type usageRows interface {
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+}
+
+func scanUsageRows(rows usageRows) ([]*entity.UsageRow, error) {
+	result := make([]*entity.UsageRow, 0)
+	for rows.Next() {
+		item := &entity.UsageRow{}
+		err := rows.Scan(
+			&item.Period,
+			&item.Requestor,
+			&item.Status,
+			&item.Requests,
+			&item.Weight,
+			&item.FilamentLength,
+			&item.Duration,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	err := rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
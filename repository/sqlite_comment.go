@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"database/sql"
+	"threedee/entity"
+)
+
+type SqliteCommentRepository struct {
+	db *sql.DB
+}
+
+func NewSqliteCommentRepository(db *sql.DB) *SqliteCommentRepository {
+	return &SqliteCommentRepository{db}
+}
+
+func (r *SqliteCommentRepository) GetByPrintRequest(printRequestId int) ([]*entity.Comment, error) {
+	return queryComments(r.db, printRequestId)
+}
+
+func (r *SqliteCommentRepository) Insert(model *entity.Comment) (int, error) {
+	var id int
+	err := runTransaction(r.db, func(tx *sql.Tx) error {
+		var err error
+		id, err = insertComment(tx, model)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
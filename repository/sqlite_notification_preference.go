@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"database/sql"
+	"threedee/entity"
+)
+
+type SqliteNotificationPreferenceRepository struct {
+	db *sql.DB
+}
+
+func NewSqliteNotificationPreferenceRepository(db *sql.DB) *SqliteNotificationPreferenceRepository {
+	return &SqliteNotificationPreferenceRepository{db}
+}
+
+func (r *SqliteNotificationPreferenceRepository) GetByRequestor(requestor string) (*entity.NotificationPreference, error) {
+	item := entity.NewNotificationPreference()
+	err := r.db.QueryRow("select "+
+		"a.requestor,"+
+		"a.email,"+
+		"a.opt_out "+
+		"from tbl_m_notification_preference a where a.requestor = $1", requestor).Scan(
+		&item.Requestor,
+		&item.Email,
+		&item.OptOut,
+	)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (r *SqliteNotificationPreferenceRepository) Upsert(model *entity.NotificationPreference) error {
+	_, err := r.db.Exec("insert into tbl_m_notification_preference "+
+		"(requestor, email, opt_out) values ($1, $2, $3) "+
+		"on conflict (requestor) do update set "+
+		"email = excluded.email,"+
+		"opt_out = excluded.opt_out,"+
+		"modified_on = current_timestamp",
+		model.Requestor,
+		model.Email,
+		model.OptOut,
+	)
+	return err
+}
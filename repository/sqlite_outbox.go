@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"database/sql"
+	"threedee/entity"
+	"time"
+)
+
+// SqliteOutboxRepository reads the outbox written by SqlitePrintRequestRepository
+type SqliteOutboxRepository struct {
+	db *sql.DB
+}
+
+func NewSqliteOutboxRepository(db *sql.DB) *SqliteOutboxRepository {
+	return &SqliteOutboxRepository{db}
+}
+
+func (r *SqliteOutboxRepository) GetPending(sink string, limit int, maxAttempts int, now time.Time) ([]*entity.OutboxEvent, error) {
+	return queryPendingOutboxEvents(r.db, sink, limit, maxAttempts, now.UTC().Format(sqliteTimeFormat))
+}
+
+func (r *SqliteOutboxRepository) MarkDelivered(id int, sink string, sinks int) error {
+	return runTransaction(r.db, func(tx *sql.Tx) error {
+		return markOutboxDelivered(tx, id, sink, sinks)
+	})
+}
+
+func (r *SqliteOutboxRepository) MarkFailed(id int, sink string, reason string, retryAt time.Time) error {
+	return runTransaction(r.db, func(tx *sql.Tx) error {
+		return markOutboxFailed(tx, id, sink, reason, retryAt.UTC().Format(sqliteTimeFormat))
+	})
+}
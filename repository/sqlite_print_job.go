@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"database/sql"
+	"threedee/entity"
+)
+
+type SqlitePrintJobRepository struct {
+	db *sql.DB
+}
+
+func NewSqlitePrintJobRepository(db *sql.DB) *SqlitePrintJobRepository {
+	return &SqlitePrintJobRepository{db}
+}
+
+func (r *SqlitePrintJobRepository) GetByPrintRequest(printRequestId int) ([]*entity.PrintJob, error) {
+	return queryPrintJobs(r.db, printJobsQuery, printRequestId)
+}
+
+func (r *SqlitePrintJobRepository) GetById(id int) (*entity.PrintJob, error) {
+	return queryPrintJob(r.db, id)
+}
+
+func (r *SqlitePrintJobRepository) Insert(model *entity.PrintJob) (int, error) {
+	var id int
+	err := runTransaction(r.db, func(tx *sql.Tx) error {
+		var err error
+		id, err = insertPrintJob(tx, model)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *SqlitePrintJobRepository) Update(model *entity.PrintJob) (bool, error) {
+	return updatePrintJob(r.db, model)
+}
+
+func (r *SqlitePrintJobRepository) GetEstimates(query *entity.EstimateQuery) ([]*entity.EstimateRow, error) {
+	return queryEstimates(r.db, query)
+}
@@ -0,0 +1,466 @@
+package repository
+
+import (
+	"database/sql"
+	"threedee/entity"
+	print_request "threedee/interfaces/print-request"
+	"threedee/utility/search"
+	"time"
+)
+
+// SqlitePrintRequestRepository is PrintRequestRepository for SQLite, see database.NewSqlite.
+// SQLite has no SELECT ... FOR UPDATE, the single connection serializes writers instead.
+
+type SqlitePrintRequestRepository struct {
+	db *sql.DB
+	tx *sql.Tx // set on the repository passed to WithTransaction
+}
+
+func NewSqlitePrintRequestRepository(db *sql.DB) *SqlitePrintRequestRepository {
+	return &SqlitePrintRequestRepository{db: db}
+}
+
+const sqlitePrintRequestColumns = "a.id," +
+	"a.item_name," +
+	"a.est_weight," +
+	"a.est_filament_length," +
+	"a.est_duration," +
+	"a.file_url," +
+	"a.file_key," +
+	"a.file_hash," +
+	"a.duplicate_of," +
+	"a.requestor," +
+	"a.notes," +
+	"a.reviewer," +
+	"a.material," +
+	"a.color," +
+	"a.layer_height," +
+	"a.infill," +
+	"a.supports," +
+	"a.nozzle_size," +
+	"a.quantity," +
+	"a.est_cost," +
+	"a.project_id," +
+	"a.status "
+
+func (r *SqlitePrintRequestRepository) conn() querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+func (r *SqlitePrintRequestRepository) inTransaction(fn func(tx *sql.Tx) error) error {
+	if r.tx != nil {
+		return fn(r.tx)
+	}
+	return runTransaction(r.db, fn)
+}
+
+func (r *SqlitePrintRequestRepository) query(query string, args ...interface{}) ([]*entity.PrintRequest, error) {
+	rows, err := r.conn().Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*entity.PrintRequest, 0)
+	for rows.Next() {
+		item := entity.NewPrintRequest()
+		err := rows.Scan(
+			&item.Id,
+			&item.ItemName,
+			&item.EstimatedWeight,
+			&item.EstimatedFilamentLength,
+			&item.EstimatedDuration,
+			&item.FileUrl,
+			&item.FileKey,
+			&item.FileHash,
+			&item.DuplicateOf,
+			&item.Requestor,
+			&item.Notes,
+			&item.Reviewer,
+			&item.Material,
+			&item.Color,
+			&item.LayerHeight,
+			&item.Infill,
+			&item.Supports,
+			&item.NozzleSize,
+			&item.Quantity,
+			&item.EstimatedCost,
+			&item.ProjectId,
+			&item.Status,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *SqlitePrintRequestRepository) GetAll(filter *entity.PrintRequestFilter) ([]*entity.PrintRequest, error) {
+	where, args := printRequestWhere(filter)
+	return r.query("select "+sqlitePrintRequestColumns+
+		"from tbl_m_3d_print_request a where "+where+" order by a.id", args...)
+}
+
+func (r *SqlitePrintRequestRepository) Each(filter *entity.PrintRequestFilter, fn func(item *entity.PrintRequest) error) error {
+	where, args := printRequestWhere(filter)
+	rows, err := r.conn().Query("select "+sqlitePrintRequestColumns+
+		"from tbl_m_3d_print_request a where "+where+" order by a.id", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := entity.NewPrintRequest()
+		err := rows.Scan(
+			&item.Id,
+			&item.ItemName,
+			&item.EstimatedWeight,
+			&item.EstimatedFilamentLength,
+			&item.EstimatedDuration,
+			&item.FileUrl,
+			&item.FileKey,
+			&item.FileHash,
+			&item.DuplicateOf,
+			&item.Requestor,
+			&item.Notes,
+			&item.Reviewer,
+			&item.Material,
+			&item.Color,
+			&item.LayerHeight,
+			&item.Infill,
+			&item.Supports,
+			&item.NozzleSize,
+			&item.Quantity,
+			&item.EstimatedCost,
+			&item.ProjectId,
+			&item.Status,
+		)
+		if err != nil {
+			return err
+		}
+		err = fn(item)
+		if err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *SqlitePrintRequestRepository) GetById(id int) (*entity.PrintRequest, error) {
+	result, err := r.query("select "+sqlitePrintRequestColumns+
+		"from tbl_m_3d_print_request a where a.id = $1 and a.is_active = true", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return entity.NewPrintRequest(), nil
+	}
+	return result[0], nil
+}
+
+func (r *SqlitePrintRequestRepository) GetActiveByFileHash(hash string, requestor string) ([]*entity.PrintRequest, error) {
+	return r.query("select "+sqlitePrintRequestColumns+
+		"from tbl_m_3d_print_request a "+
+		"where a.file_hash = $1 and a.requestor = $2 "+
+		"and a.is_active = true and a.status not in "+terminalStatuses+" "+
+		"order by a.id", hash, requestor)
+}
+
+// GetQuotaUsage needs no lock, the transaction holds the only connection until it ends
+func (r *SqlitePrintRequestRepository) GetQuotaUsage(requestor string, monthStart time.Time, weekStart time.Time) (*entity.QuotaUsage, error) {
+	return queryQuotaUsage(r.conn(), requestor, monthStart.UTC().Format(sqliteTimeFormat), weekStart.UTC().Format(sqliteTimeFormat))
+}
+
+// Search ranks with bm25, weighting item_name above requestor above notes like the
+// Postgres search vector, newest first on a tie
+func (r *SqlitePrintRequestRepository) Search(query string, limit int) ([]*entity.SearchResult, error) {
+	terms := search.Terms(query)
+	if len(terms) == 0 {
+		return make([]*entity.SearchResult, 0), nil
+	}
+
+	rows, err := r.conn().Query("select "+sqlitePrintRequestColumns+","+
+		"-bm25(tbl_m_3d_print_request_fts, 10.0, 4.0, 1.0) as rank,"+
+		"highlight(tbl_m_3d_print_request_fts, 0, $2, $3),"+
+		"highlight(tbl_m_3d_print_request_fts, 1, $2, $3),"+
+		"highlight(tbl_m_3d_print_request_fts, 2, $2, $3) "+
+		"from tbl_m_3d_print_request_fts join tbl_m_3d_print_request a on a.id = tbl_m_3d_print_request_fts.rowid "+
+		"where tbl_m_3d_print_request_fts match $1 and a.is_active = true "+
+		"order by rank desc, a.id desc limit $4",
+		search.FtsQuery(terms), search.StartMark, search.StopMark, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*entity.SearchResult, 0)
+	for rows.Next() {
+		item := &entity.SearchResult{PrintRequest: entity.NewPrintRequest()}
+		err := rows.Scan(
+			&item.Id,
+			&item.ItemName,
+			&item.EstimatedWeight,
+			&item.EstimatedFilamentLength,
+			&item.EstimatedDuration,
+			&item.FileUrl,
+			&item.FileKey,
+			&item.FileHash,
+			&item.DuplicateOf,
+			&item.Requestor,
+			&item.Notes,
+			&item.Reviewer,
+			&item.Material,
+			&item.Color,
+			&item.LayerHeight,
+			&item.Infill,
+			&item.Supports,
+			&item.NozzleSize,
+			&item.Quantity,
+			&item.EstimatedCost,
+			&item.ProjectId,
+			&item.Status,
+			&item.Rank,
+			&item.Highlights.ItemName,
+			&item.Highlights.Requestor,
+			&item.Highlights.Notes,
+		)
+		if err != nil {
+			return nil, err
+		}
+		item.Highlights = markedHighlight(item.Highlights)
+		result = append(result, item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *SqlitePrintRequestRepository) WithTransaction(fn func(repo print_request.PrintRequestRepositoryInterface) error) error {
+	return r.inTransaction(func(tx *sql.Tx) error {
+		return fn(&SqlitePrintRequestRepository{db: r.db, tx: tx})
+	})
+}
+
+func (r *SqlitePrintRequestRepository) Insert(model *entity.PrintRequest) (int, error) {
+	created := *model
+	err := r.inTransaction(func(tx *sql.Tx) error {
+		err := tx.QueryRow("INSERT INTO tbl_m_3d_print_request("+
+			"item_name,"+
+			"est_weight,"+
+			"est_filament_length,"+
+			"est_duration,"+
+			"file_url,"+
+			"file_key,"+
+			"file_hash,"+
+			"duplicate_of,"+
+			"requestor,"+
+			"notes,"+
+			"status,"+
+			"reviewer,"+
+			"material,"+
+			"color,"+
+			"layer_height,"+
+			"infill,"+
+			"supports,"+
+			"nozzle_size,"+
+			"quantity,"+
+			"est_cost,"+
+			"project_id) "+
+			"VALUES "+
+			"($1,"+
+			"$2,"+
+			"$3,"+
+			"$4,"+
+			"$5,"+
+			"$6,"+
+			"$7,"+
+			"$8,"+
+			"$9,"+
+			"$10,"+
+			"$11,"+
+			"$12,"+
+			"$13,"+
+			"$14,"+
+			"$15,"+
+			"$16,"+
+			"$17,"+
+			"$18,"+
+			"$19,"+
+			"$20,"+
+			"$21) "+
+			"RETURNING id, status;",
+			model.ItemName,
+			model.EstimatedWeight,
+			model.EstimatedFilamentLength,
+			model.EstimatedDuration,
+			model.FileUrl,
+			model.FileKey,
+			model.FileHash,
+			model.DuplicateOf,
+			model.Requestor,
+			model.Notes,
+			entity.StatusPendingReview,
+			model.Reviewer,
+			model.Material,
+			model.Color,
+			model.LayerHeight,
+			model.Infill,
+			model.Supports,
+			model.NozzleSize,
+			model.Quantity,
+			model.EstimatedCost,
+			model.ProjectId).Scan(&created.Id, &created.Status)
+		if err != nil {
+			return err
+		}
+
+		return insertOutboxEvent(tx, entity.EventCreated, created.Id, &created)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return created.Id, nil
+}
+
+func (r *SqlitePrintRequestRepository) Update(model *entity.PrintRequest) (bool, error) {
+	err := r.inTransaction(func(tx *sql.Tx) error {
+		var previousStatus string
+		err := tx.QueryRow("SELECT status FROM tbl_m_3d_print_request WHERE id = $1 AND is_active = true;", model.Id).Scan(&previousStatus)
+		if err == sql.ErrNoRows {
+			return &print_request.NotFoundError{Id: model.Id}
+		}
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.Exec("UPDATE tbl_m_3d_print_request SET "+
+			"item_name = $1,"+
+			"est_weight = $2,"+
+			"est_filament_length = $3,"+
+			"est_duration = $4,"+
+			"file_url = $5,"+
+			"file_key = $6,"+
+			"file_hash = $7,"+
+			"duplicate_of = $8,"+
+			"requestor = $9,"+
+			"notes = $10,"+
+			"status = $11,"+
+			"reviewer = $12,"+
+			"material = $13,"+
+			"color = $14,"+
+			"layer_height = $15,"+
+			"infill = $16,"+
+			"supports = $17,"+
+			"nozzle_size = $18,"+
+			"quantity = $19,"+
+			"est_cost = $20,"+
+			"project_id = $21 "+
+			"WHERE id = $22 AND is_active = true;",
+			model.ItemName,
+			model.EstimatedWeight,
+			model.EstimatedFilamentLength,
+			model.EstimatedDuration,
+			model.FileUrl,
+			model.FileKey,
+			model.FileHash,
+			model.DuplicateOf,
+			model.Requestor,
+			model.Notes,
+			model.Status,
+			model.Reviewer,
+			model.Material,
+			model.Color,
+			model.LayerHeight,
+			model.Infill,
+			model.Supports,
+			model.NozzleSize,
+			model.Quantity,
+			model.EstimatedCost,
+			model.ProjectId,
+			model.Id)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return &print_request.NotFoundError{Id: model.Id}
+		}
+
+		if previousStatus != model.Status {
+			return insertOutboxEvent(tx, entity.EventStatusChanged, model.Id, entity.StatusChangedEvent{PrintRequest: model, PreviousStatus: previousStatus})
+		}
+		return insertOutboxEvent(tx, entity.EventUpdated, model.Id, model)
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *SqlitePrintRequestRepository) Delete(id int) (bool, error) {
+	err := r.inTransaction(func(tx *sql.Tx) error {
+		deleted := entity.DeletedEvent{Id: id}
+		err := tx.QueryRow("UPDATE tbl_m_3d_print_request SET "+
+			"is_active = false "+
+			"WHERE id = $1 AND is_active = true "+
+			"RETURNING requestor, status;",
+			id).Scan(&deleted.Requestor, &deleted.Status)
+		if err == sql.ErrNoRows {
+			return &print_request.NotFoundError{Id: id}
+		}
+		if err != nil {
+			return err
+		}
+
+		return insertOutboxEvent(tx, entity.EventDeleted, id, deleted)
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *SqlitePrintRequestRepository) GetReviewThread(printRequestId int) ([]*entity.ReviewEntry, error) {
+	rows, err := r.conn().Query(reviewThreadQuery, printRequestId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanReviewEntries(rows)
+}
+
+func (r *SqlitePrintRequestRepository) AddReviewEntry(entry *entity.ReviewEntry) (int, error) {
+	var id int
+	err := r.inTransaction(func(tx *sql.Tx) error {
+		err := tx.QueryRow(insertReviewEntryQuery,
+			entry.PrintRequestId,
+			entry.Author,
+			entry.Action,
+			entry.Comment).Scan(&id)
+		if err == sql.ErrNoRows {
+			return &print_request.NotFoundError{Id: entry.PrintRequestId}
+		}
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"database/sql"
+	"threedee/entity"
+)
+
+type SqlitePrinterRepository struct {
+	db *sql.DB
+}
+
+func NewSqlitePrinterRepository(db *sql.DB) *SqlitePrinterRepository {
+	return &SqlitePrinterRepository{db}
+}
+
+func (r *SqlitePrinterRepository) GetAll() ([]*entity.Printer, error) {
+	rows, err := r.db.Query("select " +
+		"a.id," +
+		"a.name," +
+		"a.build_width," +
+		"a.build_depth," +
+		"a.build_height " +
+		"from tbl_m_printer a where a.is_active = true order by a.id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*entity.Printer, 0)
+	for rows.Next() {
+		item := entity.NewPrinter()
+		err := rows.Scan(
+			&item.Id,
+			&item.Name,
+			&item.BuildWidth,
+			&item.BuildDepth,
+			&item.BuildHeight,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
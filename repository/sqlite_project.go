@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"database/sql"
+	"threedee/entity"
+)
+
+type SqliteProjectRepository struct {
+	db *sql.DB
+}
+
+func NewSqliteProjectRepository(db *sql.DB) *SqliteProjectRepository {
+	return &SqliteProjectRepository{db}
+}
+
+func (r *SqliteProjectRepository) GetAll() ([]*entity.Project, error) {
+	return queryProjects(r.db, 0)
+}
+
+func (r *SqliteProjectRepository) GetById(id int) (*entity.Project, error) {
+	return queryProject(r.db, id)
+}
+
+func (r *SqliteProjectRepository) Insert(model *entity.Project) (int, error) {
+	return insertProject(r.db, model)
+}
+
+func (r *SqliteProjectRepository) Update(model *entity.Project) (bool, error) {
+	return updateProject(r.db, model)
+}
+
+func (r *SqliteProjectRepository) Delete(id int) (bool, error) {
+	return deleteProject(r.db, id)
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"database/sql"
+	"threedee/entity"
+	"time"
+)
+
+type SqliteQuotaRepository struct {
+	db *sql.DB
+}
+
+func NewSqliteQuotaRepository(db *sql.DB) *SqliteQuotaRepository {
+	return &SqliteQuotaRepository{db}
+}
+
+func (r *SqliteQuotaRepository) GetByRequestor(requestor string) (*entity.Quota, error) {
+	item := entity.NewQuota()
+	err := r.db.QueryRow("select "+
+		"a.requestor,"+
+		"a.max_active,"+
+		"a.max_grams_per_month,"+
+		"a.max_hours_per_week "+
+		"from tbl_m_quota a where a.requestor = $1", requestor).Scan(
+		&item.Requestor,
+		&item.MaxActive,
+		&item.MaxGramsPerMonth,
+		&item.MaxHoursPerWeek,
+	)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (r *SqliteQuotaRepository) Upsert(model *entity.Quota) error {
+	_, err := r.db.Exec("insert into tbl_m_quota "+
+		"(requestor, max_active, max_grams_per_month, max_hours_per_week) values ($1, $2, $3, $4) "+
+		"on conflict (requestor) do update set "+
+		"max_active = excluded.max_active,"+
+		"max_grams_per_month = excluded.max_grams_per_month,"+
+		"max_hours_per_week = excluded.max_hours_per_week,"+
+		"modified_on = current_timestamp",
+		model.Requestor,
+		model.MaxActive,
+		model.MaxGramsPerMonth,
+		model.MaxHoursPerWeek,
+	)
+	return err
+}
+
+func (r *SqliteQuotaRepository) GetUsage(requestor string, monthStart time.Time, weekStart time.Time) (*entity.QuotaUsage, error) {
+	return queryQuotaUsage(r.db, requestor, monthStart.UTC().Format(sqliteTimeFormat), weekStart.UTC().Format(sqliteTimeFormat))
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"database/sql"
+	"threedee/entity"
+)
+
+// sqlitePeriods start a period like Postgres date_trunc, weeks on Monday
+var sqlitePeriods = map[string]string{
+	entity.IntervalDay:   "date(a.created_on)",
+	entity.IntervalWeek:  "date(a.created_on, 'weekday 0', '-6 days')",
+	entity.IntervalMonth: "strftime('%Y-%m-01', a.created_on)",
+}
+
+// sqliteTimeFormat is how SQLite stores current_timestamp, in UTC
+const sqliteTimeFormat = "2006-01-02 15:04:05"
+
+type SqliteReportRepository struct {
+	db *sql.DB
+}
+
+func NewSqliteReportRepository(db *sql.DB) *SqliteReportRepository {
+	return &SqliteReportRepository{db}
+}
+
+func (r *SqliteReportRepository) GetUsage(query *entity.UsageQuery) ([]*entity.UsageRow, error) {
+	period, ok := sqlitePeriods[query.Interval]
+	if !ok {
+		period = sqlitePeriods[entity.IntervalMonth]
+	}
+
+	requestor, status := usageGroups(query)
+	rows, err := r.db.Query("select "+
+		period+" as period,"+
+		requestor+" as requestor,"+
+		status+" as status,"+
+		"count(*),"+
+		"sum(a.est_weight),"+
+		"sum(a.est_filament_length),"+
+		"sum(a.est_duration) "+
+		"from tbl_m_3d_print_request a "+
+		"where a.is_active = true and a.created_on >= $1 and a.created_on < $2 "+
+		"group by 1, 2, 3 order by 1, 2, 3",
+		query.From.UTC().Format(sqliteTimeFormat),
+		query.To.UTC().Format(sqliteTimeFormat))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanUsageRows(rows)
+}
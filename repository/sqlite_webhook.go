@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"threedee/entity"
+)
+
+// SqliteWebhookRepository stores the events of a subscription as a JSON array
+type SqliteWebhookRepository struct {
+	db *sql.DB
+}
+
+func NewSqliteWebhookRepository(db *sql.DB) *SqliteWebhookRepository {
+	return &SqliteWebhookRepository{db}
+}
+
+func (r *SqliteWebhookRepository) query(query string, args ...interface{}) ([]*entity.WebhookSubscription, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*entity.WebhookSubscription, 0)
+	for rows.Next() {
+		item := entity.NewWebhookSubscription()
+		var events string
+		err := rows.Scan(
+			&item.Id,
+			&item.Url,
+			&item.Secret,
+			&events,
+		)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal([]byte(events), &item.Events)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *SqliteWebhookRepository) GetAll() ([]*entity.WebhookSubscription, error) {
+	return r.query("select a.id, a.url, a.secret, a.events " +
+		"from tbl_m_webhook_subscription a where a.is_active = true order by a.id")
+}
+
+func (r *SqliteWebhookRepository) GetById(id int) (*entity.WebhookSubscription, error) {
+	result, err := r.query("select a.id, a.url, a.secret, a.events "+
+		"from tbl_m_webhook_subscription a where a.id = $1 and a.is_active = true", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return entity.NewWebhookSubscription(), nil
+	}
+	return result[0], nil
+}
+
+func (r *SqliteWebhookRepository) GetByEvent(event string) ([]*entity.WebhookSubscription, error) {
+	return r.query("select a.id, a.url, a.secret, a.events "+
+		"from tbl_m_webhook_subscription a where a.is_active = true "+
+		"and exists (select 1 from json_each(a.events) e where e.value = $1) order by a.id", event)
+}
+
+func (r *SqliteWebhookRepository) Insert(model *entity.WebhookSubscription) (int, error) {
+	events, err := json.Marshal(model.Events)
+	if err != nil {
+		return 0, err
+	}
+
+	var lastInsertId int
+	err = r.db.QueryRow("INSERT INTO tbl_m_webhook_subscription("+
+		"url,"+
+		"secret,"+
+		"events) "+
+		"VALUES "+
+		"($1,"+
+		"$2,"+
+		"$3) "+
+		"RETURNING id;",
+		model.Url,
+		model.Secret,
+		string(events)).Scan(&lastInsertId)
+	if err != nil {
+		return 0, err
+	}
+	return lastInsertId, nil
+}
+
+func (r *SqliteWebhookRepository) Delete(id int) (bool, error) {
+	res, err := r.db.Exec("UPDATE tbl_m_webhook_subscription SET "+
+		"is_active = false "+
+		"WHERE id = $1 AND is_active = true;",
+		id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *SqliteWebhookRepository) InsertDelivery(model *entity.WebhookDelivery) (int, error) {
+	var lastInsertId int
+	err := r.db.QueryRow("INSERT INTO tbl_t_webhook_delivery("+
+		"subscription_id,"+
+		"event,"+
+		"payload,"+
+		"attempt,"+
+		"status_code,"+
+		"error,"+
+		"success) "+
+		"VALUES "+
+		"($1,"+
+		"$2,"+
+		"$3,"+
+		"$4,"+
+		"$5,"+
+		"$6,"+
+		"$7) "+
+		"RETURNING id;",
+		model.SubscriptionId,
+		model.Event,
+		model.Payload,
+		model.Attempt,
+		model.StatusCode,
+		model.Error,
+		model.Success).Scan(&lastInsertId)
+	if err != nil {
+		return 0, err
+	}
+	return lastInsertId, nil
+}
+
+func (r *SqliteWebhookRepository) GetDeliveries(subscriptionId int) ([]*entity.WebhookDelivery, error) {
+	rows, err := r.db.Query("select "+
+		"a.id,"+
+		"a.subscription_id,"+
+		"a.event,"+
+		"a.payload,"+
+		"a.attempt,"+
+		"a.status_code,"+
+		"a.error,"+
+		"a.success,"+
+		"a.created_on "+
+		"from tbl_t_webhook_delivery a where a.subscription_id = $1 order by a.id desc limit 100", subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*entity.WebhookDelivery, 0)
+	for rows.Next() {
+		item := entity.NewWebhookDelivery()
+		err := rows.Scan(
+			&item.Id,
+			&item.SubscriptionId,
+			&item.Event,
+			&item.Payload,
+			&item.Attempt,
+			&item.StatusCode,
+			&item.Error,
+			&item.Success,
+			&item.CreatedOn,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"database/sql"
+	"threedee/database"
+)
+
+// querier is what *sql.DB and *sql.Tx have in common
+type querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// connect returns the transaction of the current unit of work, or a new connection that
+// has to be closed with the returned function.
+func connect(tx *sql.Tx) (querier, func() error, error) {
+	if tx != nil {
+		return tx, func() error { return nil }, nil
+	}
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, db.Close, nil
+}
+
+// inTransaction runs fn in the transaction of the current unit of work, or in a new
+// transaction that is committed when fn succeeds and rolled back otherwise.
+func inTransaction(tx *sql.Tx, fn func(tx *sql.Tx) error) error {
+	if tx != nil {
+		return fn(tx)
+	}
+
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return runTransaction(db, fn)
+}
+
+// runTransaction runs fn in a new transaction on db, committed when fn succeeds
+func runTransaction(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// lockInTransaction returns the clause that locks the selected rows until the transaction
+// of the current unit of work ends, or nothing outside of one
+func lockInTransaction(tx *sql.Tx) string {
+	if tx == nil {
+		return ""
+	}
+	return " for update"
+}
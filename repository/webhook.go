@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"threedee/database"
+	"threedee/entity"
+
+	"github.com/lib/pq"
+)
+
+type WebhookRepository struct {
+}
+
+func NewWebhookRepository() *WebhookRepository {
+	return &WebhookRepository{}
+}
+
+func (*WebhookRepository) GetAll() ([]*entity.WebhookSubscription, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("select " +
+		"a.id," +
+		"a.url," +
+		"a.secret," +
+		"a.events " +
+		"from tbl_m_webhook_subscription a where a.is_active = true order by a.id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*entity.WebhookSubscription, 0)
+	for rows.Next() {
+		item := entity.NewWebhookSubscription()
+		err := rows.Scan(
+			&item.Id,
+			&item.Url,
+			&item.Secret,
+			pq.Array(&item.Events),
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (*WebhookRepository) GetById(id int) (*entity.WebhookSubscription, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("select "+
+		"a.id,"+
+		"a.url,"+
+		"a.secret,"+
+		"a.events "+
+		"from tbl_m_webhook_subscription a where a.id = $1 and a.is_active = true", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	item := entity.NewWebhookSubscription()
+	for rows.Next() {
+		err := rows.Scan(
+			&item.Id,
+			&item.Url,
+			&item.Secret,
+			pq.Array(&item.Events),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (*WebhookRepository) GetByEvent(event string) ([]*entity.WebhookSubscription, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("select "+
+		"a.id,"+
+		"a.url,"+
+		"a.secret,"+
+		"a.events "+
+		"from tbl_m_webhook_subscription a where $1 = any(a.events) and a.is_active = true order by a.id", event)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*entity.WebhookSubscription, 0)
+	for rows.Next() {
+		item := entity.NewWebhookSubscription()
+		err := rows.Scan(
+			&item.Id,
+			&item.Url,
+			&item.Secret,
+			pq.Array(&item.Events),
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (*WebhookRepository) Insert(model *entity.WebhookSubscription) (int, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	var lastInsertId *int
+	err = db.QueryRow("INSERT INTO tbl_m_webhook_subscription("+
+		"url,"+
+		"secret,"+
+		"events) "+
+		"VALUES "+
+		"($1,"+
+		"$2,"+
+		"$3) "+
+		"RETURNING id;",
+		model.Url,
+		model.Secret,
+		pq.Array(model.Events)).Scan(&lastInsertId)
+	if err != nil {
+		return 0, err
+	}
+	return *lastInsertId, nil
+}
+
+func (*WebhookRepository) Delete(id int) (bool, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+
+	res, err := db.Exec("UPDATE tbl_m_webhook_subscription SET "+
+		"is_active = false "+
+		"WHERE id = $1 AND is_active = true;",
+		id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (*WebhookRepository) InsertDelivery(model *entity.WebhookDelivery) (int, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	var lastInsertId *int
+	err = db.QueryRow("INSERT INTO tbl_t_webhook_delivery("+
+		"subscription_id,"+
+		"event,"+
+		"payload,"+
+		"attempt,"+
+		"status_code,"+
+		"error,"+
+		"success) "+
+		"VALUES "+
+		"($1,"+
+		"$2,"+
+		"$3,"+
+		"$4,"+
+		"$5,"+
+		"$6,"+
+		"$7) "+
+		"RETURNING id;",
+		model.SubscriptionId,
+		model.Event,
+		model.Payload,
+		model.Attempt,
+		model.StatusCode,
+		model.Error,
+		model.Success).Scan(&lastInsertId)
+	if err != nil {
+		return 0, err
+	}
+	return *lastInsertId, nil
+}
+
+// GetDeliveries returns the latest 100 delivery attempts of a subscription, newest first
+func (*WebhookRepository) GetDeliveries(subscriptionId int) ([]*entity.WebhookDelivery, error) {
+	db, err := database.NewPostgresql()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("select "+
+		"a.id,"+
+		"a.subscription_id,"+
+		"a.event,"+
+		"a.payload,"+
+		"a.attempt,"+
+		"a.status_code,"+
+		"a.error,"+
+		"a.success,"+
+		"a.created_on "+
+		"from tbl_t_webhook_delivery a where a.subscription_id = $1 order by a.id desc limit 100", subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]*entity.WebhookDelivery, 0)
+	for rows.Next() {
+		item := entity.NewWebhookDelivery()
+		err := rows.Scan(
+			&item.Id,
+			&item.SubscriptionId,
+			&item.Event,
+			&item.Payload,
+			&item.Attempt,
+			&item.StatusCode,
+			&item.Error,
+			&item.Success,
+			&item.CreatedOn,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
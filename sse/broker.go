@@ -0,0 +1,152 @@
+package sse
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+/*
+ * Broker fans print request events out to Server-Sent Events clients.
+ *
+ * The latest BufferSize events are kept in memory so a client that reconnects with a
+ * Last-Event-ID header gets what it missed, as long as it is still in the buffer. Every
+ * client has its own bounded channel. A client that does not keep up is disconnected
+ * instead of slowing down everyone else, its EventSource reconnects and resumes from the
+ * buffer.
+ *
+ * Outbox ids come from concurrent transactions, so events can arrive out of id order. They
+ * are kept and sent in arrival order, and the ids of the latest deliveredSize events are
+ * remembered to ignore an event the relay publishes again.
+ */
+
+const (
+	clientBufferSize = 64
+	deliveredSize    = 1024
+)
+
+type Event struct {
+	Id        int
+	Type      string
+	Requestor string
+	Status    string
+	Data      json.RawMessage
+}
+
+// Filter narrows a stream down, empty fields match everything
+type Filter struct {
+	Requestor string
+	Status    string
+}
+
+func (f Filter) Match(e Event) bool {
+	if f.Requestor != "" && f.Requestor != e.Requestor {
+		return false
+	}
+	if f.Status != "" && f.Status != e.Status {
+		return false
+	}
+	return true
+}
+
+type Client struct {
+	Events chan Event
+	filter Filter
+}
+
+type Broker struct {
+	mu         sync.Mutex
+	bufferSize int
+	buffer     []Event
+	clients    map[*Client]struct{}
+
+	// ids of the latest published events, deliveredIds keeps their arrival order
+	delivered    map[int]struct{}
+	deliveredIds []int
+}
+
+func NewBroker(bufferSize int) *Broker {
+	return &Broker{
+		bufferSize: bufferSize,
+		buffer:     make([]Event, 0, bufferSize),
+		delivered:  make(map[int]struct{}),
+		clients:    make(map[*Client]struct{}),
+	}
+}
+
+// Publish buffers the event and sends it to every matching client. An id that was
+// already published is ignored.
+func (b *Broker) Publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.delivered[e.Id]; ok {
+		return
+	}
+	if len(b.deliveredIds) == deliveredSize {
+		delete(b.delivered, b.deliveredIds[0])
+		b.deliveredIds = b.deliveredIds[1:]
+	}
+	b.delivered[e.Id] = struct{}{}
+	b.deliveredIds = append(b.deliveredIds, e.Id)
+
+	if len(b.buffer) == b.bufferSize && b.bufferSize > 0 {
+		copy(b.buffer, b.buffer[1:])
+		b.buffer = b.buffer[:len(b.buffer)-1]
+	}
+	if b.bufferSize > 0 {
+		b.buffer = append(b.buffer, e)
+	}
+
+	for c := range b.clients {
+		if !c.filter.Match(e) {
+			continue
+		}
+		select {
+		case c.Events <- e:
+		default:
+			// too slow, let it reconnect and resume from the buffer
+			delete(b.clients, c)
+			close(c.Events)
+		}
+	}
+}
+
+// Subscribe registers a client and returns the buffered events after lastEventId that
+// match the filter. A lastEventId of 0 means the client is new and gets no backlog.
+// Events that arrived after lastEventId are sent even when their id is lower, when
+// lastEventId is no longer buffered only the higher ids are.
+func (b *Broker) Subscribe(filter Filter, lastEventId int) (*Client, []Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	backlog := make([]Event, 0)
+	if lastEventId > 0 {
+		missed := b.buffer
+		found := false
+		for i, e := range b.buffer {
+			if e.Id == lastEventId {
+				missed = b.buffer[i+1:]
+				found = true
+			}
+		}
+		for _, e := range missed {
+			if (found || e.Id > lastEventId) && filter.Match(e) {
+				backlog = append(backlog, e)
+			}
+		}
+	}
+
+	c := &Client{Events: make(chan Event, clientBufferSize), filter: filter}
+	b.clients[c] = struct{}{}
+	return c, backlog
+}
+
+func (b *Broker) Unsubscribe(c *Client) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.clients[c]; ok {
+		delete(b.clients, c)
+		close(c.Events)
+	}
+}
@@ -0,0 +1,123 @@
+package sse_test
+
+import (
+	"testing"
+	"threedee/sse"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BrokerTestSuite struct {
+	suite.Suite
+	broker *sse.Broker
+}
+
+func (suite *BrokerTestSuite) SetupTest() {
+	suite.broker = sse.NewBroker(3)
+}
+
+func ids(events []sse.Event) []int {
+	result := make([]int, 0, len(events))
+	for _, e := range events {
+		result = append(result, e.Id)
+	}
+	return result
+}
+
+func (suite *BrokerTestSuite) TestSubscribe() {
+	for id := 1; id <= 5; id++ {
+		requestor := "andi"
+		if id%2 == 0 {
+			requestor = "budi"
+		}
+		suite.broker.Publish(sse.Event{Id: id, Type: "created", Requestor: requestor, Status: "received"})
+	}
+
+	var testCase = []struct {
+		testcase    string
+		filter      sse.Filter
+		lastEventId int
+		backlog     []int
+	}{
+		{
+			testcase:    "new client",
+			lastEventId: 0,
+			backlog:     []int{},
+		},
+		{
+			testcase:    "resume",
+			lastEventId: 3,
+			backlog:     []int{4, 5},
+		},
+		{
+			testcase:    "resume from outside the buffer",
+			lastEventId: 1,
+			backlog:     []int{3, 4, 5},
+		},
+		{
+			testcase:    "resume filtered",
+			filter:      sse.Filter{Requestor: "andi"},
+			lastEventId: 1,
+			backlog:     []int{3, 5},
+		},
+	}
+	for _, tc := range testCase {
+		client, backlog := suite.broker.Subscribe(tc.filter, tc.lastEventId)
+		suite.Equal(tc.backlog, ids(backlog), tc.testcase)
+		suite.broker.Unsubscribe(client)
+	}
+}
+
+func (suite *BrokerTestSuite) TestPublish() {
+	all, _ := suite.broker.Subscribe(sse.Filter{}, 0)
+	finished, _ := suite.broker.Subscribe(sse.Filter{Status: "finished"}, 0)
+
+	suite.broker.Publish(sse.Event{Id: 1, Status: "received"})
+	suite.broker.Publish(sse.Event{Id: 2, Status: "finished"})
+	// relayed again after another sink failed
+	suite.broker.Publish(sse.Event{Id: 2, Status: "finished"})
+
+	suite.Equal(1, (<-all.Events).Id)
+	suite.Equal(2, (<-all.Events).Id)
+	suite.Equal(2, (<-finished.Events).Id)
+	suite.Len(all.Events, 0)
+	suite.Len(finished.Events, 0)
+}
+
+func (suite *BrokerTestSuite) TestPublishOutOfOrder() {
+	all, _ := suite.broker.Subscribe(sse.Filter{}, 0)
+
+	// committed after 3, published late
+	for _, id := range []int{1, 3, 2, 3} {
+		suite.broker.Publish(sse.Event{Id: id})
+	}
+
+	suite.Equal(1, (<-all.Events).Id)
+	suite.Equal(3, (<-all.Events).Id)
+	suite.Equal(2, (<-all.Events).Id)
+	suite.Len(all.Events, 0)
+
+	_, backlog := suite.broker.Subscribe(sse.Filter{}, 3)
+	suite.Equal([]int{2}, ids(backlog))
+}
+
+func (suite *BrokerTestSuite) TestPublishSlowClient() {
+	slow, _ := suite.broker.Subscribe(sse.Filter{}, 0)
+
+	for id := 1; id <= cap(slow.Events)+1; id++ {
+		suite.broker.Publish(sse.Event{Id: id})
+	}
+
+	received := 0
+	for range slow.Events {
+		received++
+	}
+	suite.Equal(cap(slow.Events), received)
+
+	// unsubscribing a disconnected client is a no-op
+	suite.broker.Unsubscribe(slow)
+}
+
+func TestBrokerTestSuite(t *testing.T) {
+	suite.Run(t, new(BrokerTestSuite))
+}
@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"threedee/entity"
+	file_storage "threedee/interfaces/file-storage"
+	"time"
+)
+
+/*
+ * Files wraps a storage backend with the parts every backend shares:
+ *
+ * 1. Content hashing: files are stored under the SHA-256 of their content, so uploading the
+ *    same model twice stores it once and the hash can be compared across requests.
+ * 2. Size limits: anything above MaxSize is refused before it reaches the backend.
+ * 3. Signed download URLs: /files/:key only serves a file when the URL carries a valid
+ *    HMAC signature that has not expired yet.
+ */
+
+var (
+	ErrFileTooLarge     = errors.New("file is too large")
+	ErrInvalidKey       = errors.New("invalid file key")
+	ErrInvalidSignature = errors.New("download link is invalid or expired")
+)
+
+var validKey = regexp.MustCompile(`^[0-9a-f]{64}(\.[0-9a-z]{1,10})?$`)
+
+type Files struct {
+	Backend    file_storage.FileStorageInterface
+	MaxSize    int64
+	SigningKey []byte
+	UrlTTL     time.Duration
+	BaseUrl    string // prepended to signed URLs, e.g. https://threedee.example.com
+}
+
+func NewFiles(backend file_storage.FileStorageInterface, maxSize int64, signingKey string, ttl time.Duration) *Files {
+	return &Files{
+		Backend:    backend,
+		MaxSize:    maxSize,
+		SigningKey: []byte(signingKey),
+		UrlTTL:     ttl,
+	}
+}
+
+// Save stores the content read from r and returns its metadata
+func (f *Files) Save(filename string, r io.Reader) (*entity.StoredFile, error) {
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, io.LimitReader(r, f.MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if n > f.MaxSize {
+		return nil, ErrFileTooLarge
+	}
+
+	hash := Hash(buf.Bytes())
+	ext := strings.ToLower(filepath.Ext(filename))
+	if !validKey.MatchString(hash + ext) {
+		ext = ""
+	}
+
+	contentType := mime.TypeByExtension(ext)
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	file := entity.NewStoredFile()
+	file.Key = hash + ext
+	file.Filename = filepath.Base(filename)
+	file.Hash = hash
+	file.Size = n
+	file.ContentType = contentType
+
+	file.Existed = f.exists(file.Key)
+
+	err = f.Backend.Put(file.Key, bytes.NewReader(buf.Bytes()), n, contentType)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// exists tells whether key is stored, a backend error counts as stored
+func (f *Files) exists(key string) bool {
+	rc, err := f.Backend.Get(key)
+	if err == file_storage.ErrFileNotFound {
+		return false
+	}
+	if err == nil {
+		rc.Close()
+	}
+	return true
+}
+
+// Discard deletes a file Save stored for a record that was not created after all. Content
+// that was stored before may belong to another record, so it is kept.
+func (f *Files) Discard(file *entity.StoredFile) error {
+	if file.Existed {
+		return nil
+	}
+	return f.Backend.Delete(file.Key)
+}
+
+// Hash returns the content hash files are stored and compared by
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (f *Files) Open(key string) (io.ReadCloser, error) {
+	if !validKey.MatchString(key) {
+		return nil, ErrInvalidKey
+	}
+	return f.Backend.Get(key)
+}
+
+// SignedUrl returns a download URL for key that is valid for UrlTTL
+func (f *Files) SignedUrl(key string, now time.Time) string {
+	expires := strconv.FormatInt(now.Add(f.UrlTTL).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", f.signature(key, expires))
+	return fmt.Sprintf("%s/files/%s?%s", f.BaseUrl, key, query.Encode())
+}
+
+// Verify checks the expires and signature query parameters of a download URL
+func (f *Files) Verify(key, expires, signature string, now time.Time) error {
+	if !validKey.MatchString(key) {
+		return ErrInvalidKey
+	}
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.Unix() > unix {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(f.signature(key, expires))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (f *Files) signature(key, expires string) string {
+	h := hmac.New(sha256.New, f.SigningKey)
+	h.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(h.Sum(nil))
+}
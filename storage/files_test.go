@@ -0,0 +1,107 @@
+package storage_test
+
+import (
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"testing"
+	file_storage "threedee/interfaces/file-storage"
+	"threedee/storage"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FilesTestSuite struct {
+	suite.Suite
+	files *storage.Files
+}
+
+func (suite *FilesTestSuite) SetupTest() {
+	local, err := storage.NewLocalStorage(suite.T().TempDir())
+	suite.Require().Nil(err)
+	suite.files = storage.NewFiles(local, 16, "secret", time.Minute)
+}
+
+func (suite *FilesTestSuite) TestSave() {
+	file, err := suite.files.Save("Phone Holder.GCODE", strings.NewReader("G1 X1 E1"))
+	suite.Nil(err)
+	suite.Equal(file.Hash+".gcode", file.Key)
+	suite.Equal(int64(8), file.Size)
+
+	rc, err := suite.files.Open(file.Key)
+	suite.Nil(err)
+	b, _ := ioutil.ReadAll(rc)
+	rc.Close()
+	suite.Equal("G1 X1 E1", string(b))
+
+	again, err := suite.files.Save("copy.gcode", strings.NewReader("G1 X1 E1"))
+	suite.Nil(err)
+	suite.Equal(file.Key, again.Key)
+}
+
+func (suite *FilesTestSuite) TestDiscard() {
+	file, err := suite.files.Save("a.gcode", strings.NewReader("G1 X1 E1"))
+	suite.Require().Nil(err)
+	suite.False(file.Existed)
+	again, err := suite.files.Save("b.gcode", strings.NewReader("G1 X1 E1"))
+	suite.Require().Nil(err)
+	suite.True(again.Existed)
+
+	suite.Nil(suite.files.Discard(again))
+	_, err = suite.files.Open(file.Key)
+	suite.Nil(err, "content stored before is kept")
+
+	suite.Nil(suite.files.Discard(file))
+	_, err = suite.files.Open(file.Key)
+	suite.Equal(file_storage.ErrFileNotFound, err)
+}
+
+func (suite *FilesTestSuite) TestSaveTooLarge() {
+	_, err := suite.files.Save("big.gcode", strings.NewReader(strings.Repeat("G", 17)))
+	suite.Equal(storage.ErrFileTooLarge, err)
+}
+
+func (suite *FilesTestSuite) TestOpenInvalidKey() {
+	_, err := suite.files.Open("../../etc/passwd")
+	suite.Equal(storage.ErrInvalidKey, err)
+}
+
+func (suite *FilesTestSuite) TestVerify() {
+	key := strings.Repeat("a", 64) + ".3mf"
+	now := time.Unix(1600000000, 0)
+	signed := suite.files.SignedUrl(key, now)
+
+	var testCase = []struct {
+		testcase  string
+		key       string
+		expires   string
+		signature string
+		at        time.Time
+		isError   bool
+	}{
+		{"valid", key, "1600000060", query(signed, "signature"), now, false},
+		{"expired", key, "1600000060", query(signed, "signature"), now.Add(2 * time.Minute), true},
+		{"extended expiry", key, "1700000000", query(signed, "signature"), now, true},
+		{"other key", strings.Repeat("b", 64) + ".3mf", "1600000060", query(signed, "signature"), now, true},
+		{"no signature", key, "1600000060", "", now, true},
+	}
+	suite.Equal("1600000060", query(signed, "expires"))
+	for _, tc := range testCase {
+		err := suite.files.Verify(tc.key, tc.expires, tc.signature, tc.at)
+		if tc.isError {
+			suite.NotNil(err, tc.testcase)
+		} else {
+			suite.Nil(err, tc.testcase)
+		}
+	}
+}
+
+func query(signed, name string) string {
+	u, _ := url.Parse(signed)
+	return u.Query().Get(name)
+}
+
+func TestFilesTestSuite(t *testing.T) {
+	suite.Run(t, new(FilesTestSuite))
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	file_storage "threedee/interfaces/file-storage"
+)
+
+// LocalStorage keeps files in a directory on the local filesystem
+type LocalStorage struct {
+	Root string
+}
+
+func NewLocalStorage(root string) (*LocalStorage, error) {
+	err := os.MkdirAll(root, 0755)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalStorage{Root: root}, nil
+}
+
+func (s *LocalStorage) Put(key string, r io.Reader, size int64, contentType string) error {
+	// write to a temporary file first so a failed upload never leaves a partial file behind
+	tmp, err := ioutil.TempFile(s.Root, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, r)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(s.Root, key))
+}
+
+func (s *LocalStorage) Get(key string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(s.Root, key))
+	if os.IsNotExist(err) {
+		return nil, file_storage.ErrFileNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (s *LocalStorage) Delete(key string) error {
+	err := os.Remove(filepath.Join(s.Root, key))
+	if os.IsNotExist(err) {
+		return file_storage.ErrFileNotFound
+	}
+	return err
+}
@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	file_storage "threedee/interfaces/file-storage"
+	"time"
+)
+
+/*
+ * S3Storage talks to any S3-compatible object store (AWS S3, MinIO, ...) with path-style
+ * URLs and AWS Signature Version 4. Only the three object calls we need are implemented,
+ * which keeps the service free of a full SDK.
+ */
+
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+type S3Storage struct {
+	Endpoint  string // e.g. http://localhost:9000
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	Client    *http.Client
+}
+
+func NewS3Storage(endpoint, region, bucket, accessKey, secretKey string) *S3Storage {
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &S3Storage{
+		Endpoint:  strings.TrimRight(endpoint, "/"),
+		Region:    region,
+		Bucket:    bucket,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		Client:    &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+func (s *S3Storage) Put(key string, r io.Reader, size int64, contentType string) error {
+	req, err := http.NewRequest(http.MethodPut, s.objectUrl(key), r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	res, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return s.checkResponse(res)
+}
+
+func (s *S3Storage) Get(key string) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, s.objectUrl(key), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	err = s.checkResponse(res)
+	if err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+func (s *S3Storage) Delete(key string) error {
+	req, err := http.NewRequest(http.MethodDelete, s.objectUrl(key), nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return s.checkResponse(res)
+}
+
+func (s *S3Storage) objectUrl(key string) string {
+	return s.Endpoint + "/" + escapePath(s.Bucket) + "/" + escapePath(key)
+}
+
+func (s *S3Storage) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	sign(req, s.AccessKey, s.SecretKey, s.Region, time.Now().UTC())
+	return s.Client.Do(req)
+}
+
+func (s *S3Storage) checkResponse(res *http.Response) error {
+	if res.StatusCode == http.StatusNotFound {
+		return file_storage.ErrFileNotFound
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("s3 responded with %d: %s", res.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// sign adds the AWS Signature Version 4 Authorization header. The payload hash has to be
+// set in X-Amz-Content-Sha256 beforehand.
+func sign(req *http.Request, accessKey, secretKey, region string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" || lower == "range" {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		req.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hashed[:])
+
+	key := hmacSha256([]byte("AWS4"+secretKey), date)
+	key = hmacSha256(key, region)
+	key = hmacSha256(key, "s3")
+	key = hmacSha256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSha256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func canonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		vs := append([]string(nil), values[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			parts = append(parts, escape(k)+"="+escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// escape implements the URI encoding of SigV4, which only leaves A-Z a-z 0-9 - _ . ~ as is
+func escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func escapePath(s string) string {
+	segments := strings.Split(s, "/")
+	for i, seg := range segments {
+		segments[i] = escape(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
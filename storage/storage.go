@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	file_storage "threedee/interfaces/file-storage"
+	"time"
+)
+
+const (
+	defaultMaxSize = 50 << 20
+	defaultUrlTTL  = 15 * time.Minute
+)
+
+// NewFileStorage builds the file storage configured in the environment
+func NewFileStorage() (*Files, error) {
+	var backend file_storage.FileStorageInterface
+	switch os.Getenv("STORAGE_DRIVER") {
+	case "", "local":
+		root := os.Getenv("STORAGE_LOCAL_ROOT")
+		if root == "" {
+			root = "uploads"
+		}
+		local, err := NewLocalStorage(root)
+		if err != nil {
+			return nil, err
+		}
+		backend = local
+	case "s3":
+		backend = NewS3Storage(
+			os.Getenv("S3_ENDPOINT"),
+			os.Getenv("S3_REGION"),
+			os.Getenv("S3_BUCKET"),
+			os.Getenv("S3_ACCESS_KEY"),
+			os.Getenv("S3_SECRET_KEY"),
+		)
+	default:
+		return nil, errors.New("unknown STORAGE_DRIVER " + os.Getenv("STORAGE_DRIVER"))
+	}
+
+	signingKey := os.Getenv("STORAGE_SIGNING_KEY")
+	if signingKey == "" {
+		return nil, errors.New("STORAGE_SIGNING_KEY is not set")
+	}
+
+	maxSize := int64(defaultMaxSize)
+	if v := os.Getenv("STORAGE_MAX_SIZE"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, errors.New("STORAGE_MAX_SIZE is not a number")
+		}
+		maxSize = n
+	}
+
+	ttl := defaultUrlTTL
+	if v := os.Getenv("STORAGE_URL_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, errors.New("STORAGE_URL_TTL is not a duration")
+		}
+		ttl = d
+	}
+
+	files := NewFiles(backend, maxSize, signingKey, ttl)
+	files.BaseUrl = os.Getenv("PUBLIC_URL")
+	return files, nil
+}
@@ -0,0 +1,21 @@
+package mock
+
+import (
+	"threedee/entity"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCommentRepository struct {
+	mock.Mock
+}
+
+func (mr *MockCommentRepository) GetByPrintRequest(printRequestId int) ([]*entity.Comment, error) {
+	args := mr.Called(printRequestId)
+	return args.Get(0).([]*entity.Comment), args.Error(1)
+}
+
+func (mr *MockCommentRepository) Insert(model *entity.Comment) (int, error) {
+	args := mr.Called(model)
+	return args.Int(0), args.Error(1)
+}
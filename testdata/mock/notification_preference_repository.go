@@ -0,0 +1,21 @@
+package mock
+
+import (
+	"threedee/entity"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockNotificationPreferenceRepository struct {
+	mock.Mock
+}
+
+func (mr *MockNotificationPreferenceRepository) GetByRequestor(requestor string) (*entity.NotificationPreference, error) {
+	args := mr.Called(requestor)
+	return args.Get(0).(*entity.NotificationPreference), args.Error(1)
+}
+
+func (mr *MockNotificationPreferenceRepository) Upsert(model *entity.NotificationPreference) error {
+	args := mr.Called(model)
+	return args.Error(0)
+}
@@ -0,0 +1,27 @@
+package mock
+
+import (
+	"threedee/entity"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (mr *MockOutboxRepository) GetPending(sink string, limit int, maxAttempts int, now time.Time) ([]*entity.OutboxEvent, error) {
+	args := mr.Called(sink, limit, maxAttempts, now)
+	return args.Get(0).([]*entity.OutboxEvent), args.Error(1)
+}
+
+func (mr *MockOutboxRepository) MarkDelivered(id int, sink string, sinks int) error {
+	args := mr.Called(id, sink, sinks)
+	return args.Error(0)
+}
+
+func (mr *MockOutboxRepository) MarkFailed(id int, sink string, reason string, retryAt time.Time) error {
+	args := mr.Called(id, sink, reason, retryAt)
+	return args.Error(0)
+}
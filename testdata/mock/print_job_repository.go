@@ -0,0 +1,36 @@
+package mock
+
+import (
+	"threedee/entity"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPrintJobRepository struct {
+	mock.Mock
+}
+
+func (mr *MockPrintJobRepository) GetByPrintRequest(printRequestId int) ([]*entity.PrintJob, error) {
+	args := mr.Called(printRequestId)
+	return args.Get(0).([]*entity.PrintJob), args.Error(1)
+}
+
+func (mr *MockPrintJobRepository) GetById(id int) (*entity.PrintJob, error) {
+	args := mr.Called(id)
+	return args.Get(0).(*entity.PrintJob), args.Error(1)
+}
+
+func (mr *MockPrintJobRepository) Insert(model *entity.PrintJob) (int, error) {
+	args := mr.Called(model)
+	return args.Int(0), args.Error(1)
+}
+
+func (mr *MockPrintJobRepository) Update(model *entity.PrintJob) (bool, error) {
+	args := mr.Called(model)
+	return args.Bool(0), args.Error(1)
+}
+
+func (mr *MockPrintJobRepository) GetEstimates(query *entity.EstimateQuery) ([]*entity.EstimateRow, error) {
+	args := mr.Called(query)
+	return args.Get(0).([]*entity.EstimateRow), args.Error(1)
+}
@@ -2,24 +2,51 @@ package mock
 
 import (
 	"threedee/entity"
+	print_request "threedee/interfaces/print-request"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
 
 type MockPrintRequestRepository struct {
 	mock.Mock
+
+	Commits   int // WithTransaction calls whose fn succeeded
+	Rollbacks int // WithTransaction calls whose fn returned an error
 }
 
-func (mr *MockPrintRequestRepository) GetAll() ([]*entity.PrintRequest, error) {
-	args := mr.Called()
+func (mr *MockPrintRequestRepository) GetAll(filter *entity.PrintRequestFilter) ([]*entity.PrintRequest, error) {
+	args := mr.Called(filter)
 	return args.Get(0).([]*entity.PrintRequest), args.Error(1)
 }
 
+// Each calls fn with the requests returned for filter, then returns the mocked error
+func (mr *MockPrintRequestRepository) Each(filter *entity.PrintRequestFilter, fn func(item *entity.PrintRequest) error) error {
+	args := mr.Called(filter)
+	for _, item := range args.Get(0).([]*entity.PrintRequest) {
+		err := fn(item)
+		if err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
 func (mr *MockPrintRequestRepository) GetById(id int) (*entity.PrintRequest, error) {
 	args := mr.Called(id)
 	return args.Get(0).(*entity.PrintRequest), args.Error(1)
 }
 
+func (mr *MockPrintRequestRepository) GetActiveByFileHash(hash string, requestor string) ([]*entity.PrintRequest, error) {
+	args := mr.Called(hash, requestor)
+	return args.Get(0).([]*entity.PrintRequest), args.Error(1)
+}
+
+func (mr *MockPrintRequestRepository) Search(query string, limit int) ([]*entity.SearchResult, error) {
+	args := mr.Called(query, limit)
+	return args.Get(0).([]*entity.SearchResult), args.Error(1)
+}
+
 func (mr *MockPrintRequestRepository) Insert(model *entity.PrintRequest) (int, error) {
 	args := mr.Called(model)
 	return args.Get(0).(int), args.Error(1)
@@ -34,3 +61,30 @@ func (mr *MockPrintRequestRepository) Delete(id int) (bool, error) {
 	args := mr.Called(id)
 	return args.Get(0).(bool), args.Error(1)
 }
+
+func (mr *MockPrintRequestRepository) GetQuotaUsage(requestor string, monthStart time.Time, weekStart time.Time) (*entity.QuotaUsage, error) {
+	args := mr.Called(requestor, monthStart, weekStart)
+	return args.Get(0).(*entity.QuotaUsage), args.Error(1)
+}
+
+// WithTransaction runs fn against the mock itself and counts whether the transaction
+// would be committed or rolled back. Calls made in a rolled back fn are still recorded.
+func (mr *MockPrintRequestRepository) WithTransaction(fn func(repo print_request.PrintRequestRepositoryInterface) error) error {
+	err := fn(mr)
+	if err != nil {
+		mr.Rollbacks++
+		return err
+	}
+	mr.Commits++
+	return nil
+}
+
+func (mr *MockPrintRequestRepository) GetReviewThread(printRequestId int) ([]*entity.ReviewEntry, error) {
+	args := mr.Called(printRequestId)
+	return args.Get(0).([]*entity.ReviewEntry), args.Error(1)
+}
+
+func (mr *MockPrintRequestRepository) AddReviewEntry(entry *entity.ReviewEntry) (int, error) {
+	args := mr.Called(entry)
+	return args.Int(0), args.Error(1)
+}
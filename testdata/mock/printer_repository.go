@@ -0,0 +1,16 @@
+package mock
+
+import (
+	"threedee/entity"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPrinterRepository struct {
+	mock.Mock
+}
+
+func (mr *MockPrinterRepository) GetAll() ([]*entity.Printer, error) {
+	args := mr.Called()
+	return args.Get(0).([]*entity.Printer), args.Error(1)
+}
@@ -0,0 +1,36 @@
+package mock
+
+import (
+	"threedee/entity"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockProjectRepository struct {
+	mock.Mock
+}
+
+func (mr *MockProjectRepository) GetAll() ([]*entity.Project, error) {
+	args := mr.Called()
+	return args.Get(0).([]*entity.Project), args.Error(1)
+}
+
+func (mr *MockProjectRepository) GetById(id int) (*entity.Project, error) {
+	args := mr.Called(id)
+	return args.Get(0).(*entity.Project), args.Error(1)
+}
+
+func (mr *MockProjectRepository) Insert(model *entity.Project) (int, error) {
+	args := mr.Called(model)
+	return args.Int(0), args.Error(1)
+}
+
+func (mr *MockProjectRepository) Update(model *entity.Project) (bool, error) {
+	args := mr.Called(model)
+	return args.Bool(0), args.Error(1)
+}
+
+func (mr *MockProjectRepository) Delete(id int) (bool, error) {
+	args := mr.Called(id)
+	return args.Bool(0), args.Error(1)
+}
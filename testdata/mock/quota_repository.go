@@ -0,0 +1,27 @@
+package mock
+
+import (
+	"threedee/entity"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockQuotaRepository struct {
+	mock.Mock
+}
+
+func (mr *MockQuotaRepository) GetByRequestor(requestor string) (*entity.Quota, error) {
+	args := mr.Called(requestor)
+	return args.Get(0).(*entity.Quota), args.Error(1)
+}
+
+func (mr *MockQuotaRepository) Upsert(model *entity.Quota) error {
+	args := mr.Called(model)
+	return args.Error(0)
+}
+
+func (mr *MockQuotaRepository) GetUsage(requestor string, monthStart time.Time, weekStart time.Time) (*entity.QuotaUsage, error) {
+	args := mr.Called(requestor, monthStart, weekStart)
+	return args.Get(0).(*entity.QuotaUsage), args.Error(1)
+}
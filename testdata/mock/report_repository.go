@@ -0,0 +1,16 @@
+package mock
+
+import (
+	"threedee/entity"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReportRepository struct {
+	mock.Mock
+}
+
+func (mr *MockReportRepository) GetUsage(query *entity.UsageQuery) ([]*entity.UsageRow, error) {
+	args := mr.Called(query)
+	return args.Get(0).([]*entity.UsageRow), args.Error(1)
+}
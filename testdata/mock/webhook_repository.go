@@ -0,0 +1,46 @@
+package mock
+
+import (
+	"threedee/entity"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (mr *MockWebhookRepository) GetAll() ([]*entity.WebhookSubscription, error) {
+	args := mr.Called()
+	return args.Get(0).([]*entity.WebhookSubscription), args.Error(1)
+}
+
+func (mr *MockWebhookRepository) GetById(id int) (*entity.WebhookSubscription, error) {
+	args := mr.Called(id)
+	return args.Get(0).(*entity.WebhookSubscription), args.Error(1)
+}
+
+func (mr *MockWebhookRepository) GetByEvent(event string) ([]*entity.WebhookSubscription, error) {
+	args := mr.Called(event)
+	return args.Get(0).([]*entity.WebhookSubscription), args.Error(1)
+}
+
+func (mr *MockWebhookRepository) Insert(model *entity.WebhookSubscription) (int, error) {
+	args := mr.Called(model)
+	return args.Get(0).(int), args.Error(1)
+}
+
+func (mr *MockWebhookRepository) Delete(id int) (bool, error) {
+	args := mr.Called(id)
+	return args.Get(0).(bool), args.Error(1)
+}
+
+func (mr *MockWebhookRepository) InsertDelivery(model *entity.WebhookDelivery) (int, error) {
+	args := mr.Called(model)
+	return args.Get(0).(int), args.Error(1)
+}
+
+func (mr *MockWebhookRepository) GetDeliveries(subscriptionId int) ([]*entity.WebhookDelivery, error) {
+	args := mr.Called(subscriptionId)
+	return args.Get(0).([]*entity.WebhookDelivery), args.Error(1)
+}
@@ -1,11 +1,35 @@
 package threedee
 
 import (
+	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"threedee/database"
+	"threedee/email"
+	"threedee/entity"
 	"threedee/handler"
+	notification_preference "threedee/interfaces/notification-preference"
+	outbox_event "threedee/interfaces/outbox-event"
+	print_job "threedee/interfaces/print-job"
+	print_request "threedee/interfaces/print-request"
+	print_request_comment "threedee/interfaces/print-request-comment"
+	"threedee/interfaces/printer"
+	"threedee/interfaces/project"
+	"threedee/interfaces/report"
+	requestor_quota "threedee/interfaces/requestor-quota"
+	webhook_subscription "threedee/interfaces/webhook-subscription"
 	m "threedee/middleware"
+	"threedee/outbox"
 	"threedee/repository"
+	"threedee/sse"
+	"threedee/storage"
+	"threedee/utility/estimator"
 	"threedee/utility/normalizer"
+	"threedee/webhook"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/rs/cors"
@@ -28,6 +52,7 @@ import (
 
 type Threedee struct {
 	Router http.Handler
+	Relay  *outbox.Relay // publishes print request events, started by main
 }
 
 func NewThreedee() *Threedee {
@@ -40,16 +65,252 @@ func NewThreedee() *Threedee {
 
 	router := httprouter.New()
 
-	// We input the repo here, not the interface. The interface is for contraint purpose only
-	rep := repository.NewPrintRequestRepository()
+	// We input the repos here, picked by DB_DRIVER. The interface is for contraint purpose only
+	repos := newRepositories(os.Getenv("DB_DRIVER"))
+	rep := repos.PrintRequests
 	norm := normalizer.NewPrintRequestNormalizer()
-	rh := handler.NewRequestHandler(rep, norm)
+
+	// without a storage config the service still runs, uploads are then only analyzed
+	files, err := storage.NewFileStorage()
+	if err != nil {
+		log.Println("file storage disabled:", err)
+	}
+
+	printers := repos.Printers
+	webhooks := repos.Webhooks
+	prefs := repos.NotificationPreferences
+	limit := defaultQuota()
+	estimator.SetPrices(materialPrices())
+	rh := handler.NewRequestHandler(rep, norm, files, os.Getenv("DUPLICATE_POLICY"), printers, repos.Quotas, limit, reviewers(), repos.Projects)
+	fh := handler.NewFileHandler(files)
+	wh := handler.NewWebhookHandler(webhooks, normalizer.NewWebhookNormalizer())
+	nh := handler.NewNotificationPreferenceHandler(prefs, normalizer.NewNotificationPreferenceNormalizer())
+	reph := handler.NewReportHandler(repos.Reports, repos.PrintJobs, normalizer.NewReportNormalizer())
+	qh := handler.NewQuotaHandler(repos.Quotas, normalizer.NewQuotaNormalizer(), limit)
+	rvh := handler.NewReviewHandler(rep, normalizer.NewReviewNormalizer())
+	ch := handler.NewCommentHandler(repos.Comments, rep, normalizer.NewCommentNormalizer(), files)
+	ph := handler.NewProjectHandler(repos.Projects, rep, normalizer.NewProjectNormalizer(), norm)
+	jh := handler.NewPrintJobHandler(repos.PrintJobs, rep, printers, normalizer.NewPrintJobNormalizer())
+
+	broker := sse.NewBroker(eventBufferSize())
+	eh := handler.NewEventHandler(broker)
+
 	router.GET("/print-requests", m.Middleware(rh.Index))
-	router.GET("/print-requests/:id", m.Middleware(rh.Show))
+	router.GET("/print-requests/:id", m.Middleware(m.Static("id", map[string]m.Handler{
+		"events": eh.Stream,
+		"search": rh.Search,
+		"export": rh.Export,
+	}, rh.Show)))
 	router.POST("/print-requests", m.Middleware(rh.Create))
+	// POST /print-requests/:id/comments and /jobs take the segment of /import and /batch/status
+	router.POST("/print-requests/:id", m.Middleware(m.Static("id", map[string]m.Handler{
+		"import": rh.Import,
+	}, m.NotFound)))
+	router.POST("/print-requests/:id/:resource", m.Middleware(m.Static("id", map[string]m.Handler{
+		"batch": m.Static("resource", map[string]m.Handler{"status": rh.BatchStatus}, m.NotFound),
+	}, m.Static("resource", map[string]m.Handler{
+		"comments": ch.Create,
+		"jobs":     jh.Create,
+	}, m.NotFound))))
 	router.PUT("/print-requests/:id", m.Middleware(rh.Update))
 	router.PUT("/print-requests/:id/status", m.Middleware(rh.ChangeStatus))
 	router.DELETE("/print-requests/:id", m.Middleware(rh.Delete))
+	router.GET("/print-requests/:id/file", m.Middleware(rh.File))
+	router.GET("/print-requests/:id/review", m.Middleware(rvh.Thread))
+	router.PUT("/print-requests/:id/review", m.Middleware(rvh.Review))
+	router.GET("/print-requests/:id/comments", m.Middleware(ch.Index))
+	router.GET("/print-requests/:id/jobs", m.Middleware(jh.Index))
+	router.PUT("/print-requests/:id/jobs/:job", m.Middleware(jh.Update))
+	router.GET("/projects", m.Middleware(ph.Index))
+	router.POST("/projects", m.Middleware(ph.Create))
+	router.GET("/projects/:id", m.Middleware(ph.Show))
+	router.PUT("/projects/:id", m.Middleware(ph.Update))
+	router.DELETE("/projects/:id", m.Middleware(ph.Delete))
+	router.GET("/projects/:id/print-requests", m.Middleware(ph.PrintRequests))
+	router.GET("/files/:key", m.Middleware(fh.Download))
+	router.GET("/webhooks", m.Middleware(wh.Index))
+	router.POST("/webhooks", m.Middleware(wh.Create))
+	router.DELETE("/webhooks/:id", m.Middleware(wh.Delete))
+	router.GET("/webhooks/:id/deliveries", m.Middleware(wh.Deliveries))
+	router.GET("/notification-preferences/:requestor", m.Middleware(nh.Show))
+	router.PUT("/notification-preferences/:requestor", m.Middleware(nh.Update))
+	router.GET("/reports/usage", m.Middleware(reph.Usage))
+	router.GET("/reports/estimates", m.Middleware(reph.Estimates))
+	router.GET("/quotas/:requestor", m.Middleware(qh.Show))
+	router.PUT("/quotas/:requestor", m.Middleware(qh.Update))
+
+	sinks := append(newOutboxSinks(webhooks, prefs), outbox.NewBrokerSink(broker))
+	relay := outbox.NewRelay(repos.Outbox, sinks...)
+
+	var routes http.Handler = router
+	if limiter := newRateLimiter(); limiter != nil {
+		routes = limiter.Handler(router)
+	}
+
+	return &Threedee{corsConfig.Handler(routes), relay}
+}
+
+// repositories are the storage of one DB_DRIVER
+type repositories struct {
+	PrintRequests           print_request.PrintRequestRepositoryInterface
+	Printers                printer.PrinterRepositoryInterface
+	Webhooks                webhook_subscription.WebhookRepositoryInterface
+	NotificationPreferences notification_preference.NotificationPreferenceRepositoryInterface
+	Outbox                  outbox_event.OutboxRepositoryInterface
+	Reports                 report.ReportRepositoryInterface
+	Quotas                  requestor_quota.QuotaRepositoryInterface
+	Comments                print_request_comment.CommentRepositoryInterface
+	Projects                project.ProjectRepositoryInterface
+	PrintJobs               print_job.PrintJobRepositoryInterface
+}
+
+// newRepositories picks the storage by DB_DRIVER, "postgres" (default), "sqlite" or
+// "memory". The memory driver needs no database and forgets everything on restart.
+func newRepositories(driver string) *repositories {
+	switch driver {
+	case "memory":
+		events := repository.NewMemoryOutboxRepository()
+		requests := repository.NewMemoryPrintRequestRepository(events)
+		return &repositories{
+			PrintRequests:           requests,
+			Printers:                repository.NewMemoryPrinterRepository(),
+			Webhooks:                repository.NewMemoryWebhookRepository(),
+			NotificationPreferences: repository.NewMemoryNotificationPreferenceRepository(),
+			Outbox:                  events,
+			Reports:                 repository.NewMemoryReportRepository(requests),
+			Quotas:                  repository.NewMemoryQuotaRepository(requests),
+			Comments:                repository.NewMemoryCommentRepository(requests),
+			Projects:                repository.NewMemoryProjectRepository(requests),
+			PrintJobs:               repository.NewMemoryPrintJobRepository(requests),
+		}
+	case "sqlite":
+		path := os.Getenv("SQLITE_PATH")
+		if path == "" {
+			path = "threedee.db"
+		}
+		db, err := database.NewSqlite(path)
+		if err != nil {
+			panic(fmt.Sprintf("%s: %s", "Failed to open the SQLite database", err))
+		}
+		return &repositories{
+			PrintRequests:           repository.NewSqlitePrintRequestRepository(db),
+			Printers:                repository.NewSqlitePrinterRepository(db),
+			Webhooks:                repository.NewSqliteWebhookRepository(db),
+			NotificationPreferences: repository.NewSqliteNotificationPreferenceRepository(db),
+			Outbox:                  repository.NewSqliteOutboxRepository(db),
+			Reports:                 repository.NewSqliteReportRepository(db),
+			Quotas:                  repository.NewSqliteQuotaRepository(db),
+			Comments:                repository.NewSqliteCommentRepository(db),
+			Projects:                repository.NewSqliteProjectRepository(db),
+			PrintJobs:               repository.NewSqlitePrintJobRepository(db),
+		}
+	case "", "postgres":
+	default:
+		log.Println("unknown DB_DRIVER " + driver + ", using postgres")
+	}
+	return &repositories{
+		PrintRequests:           repository.NewPrintRequestRepository(),
+		Printers:                repository.NewPrinterRepository(),
+		Webhooks:                repository.NewWebhookRepository(),
+		NotificationPreferences: repository.NewNotificationPreferenceRepository(),
+		Outbox:                  repository.NewOutboxRepository(),
+		Reports:                 repository.NewReportRepository(),
+		Quotas:                  repository.NewQuotaRepository(),
+		Comments:                repository.NewCommentRepository(),
+		Projects:                repository.NewProjectRepository(),
+		PrintJobs:               repository.NewPrintJobRepository(),
+	}
+}
+
+// defaultQuota reads QUOTA_MAX_ACTIVE, QUOTA_MAX_GRAMS_PER_MONTH and QUOTA_MAX_HOURS_PER_WEEK,
+// the quota of requestors without their own. A missing limit is unlimited.
+func defaultQuota() *entity.Quota {
+	limit := entity.NewQuota()
+	limit.MaxActive, _ = strconv.Atoi(os.Getenv("QUOTA_MAX_ACTIVE"))
+	limit.MaxGramsPerMonth, _ = strconv.ParseFloat(os.Getenv("QUOTA_MAX_GRAMS_PER_MONTH"), 64)
+	limit.MaxHoursPerWeek, _ = strconv.ParseFloat(os.Getenv("QUOTA_MAX_HOURS_PER_WEEK"), 64)
+	return limit
+}
+
+// reviewers reads the comma separated REVIEWERS, who new print requests are assigned to
+func reviewers() []string {
+	names := make([]string, 0)
+	for _, name := range strings.Split(os.Getenv("REVIEWERS"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// materialPrices reads the comma separated MATERIAL_PRICES, e.g. "pla=20,petg=25", the
+// price per kg of the materials. Entries that are not a price are skipped.
+func materialPrices() map[string]float64 {
+	prices := make(map[string]float64)
+	for _, entry := range strings.Split(os.Getenv("MATERIAL_PRICES"), ",") {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		price, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil || price < 0 {
+			continue
+		}
+		prices[strings.ToLower(strings.TrimSpace(parts[0]))] = price
+	}
+	return prices
+}
+
+// newRateLimiter reads RATE_LIMIT_RPS, the requests per second of one client, and
+// RATE_LIMIT_BURST (default twice the rate). Rate limiting is off without RATE_LIMIT_RPS.
+func newRateLimiter() *m.RateLimiter {
+	rate, err := strconv.ParseFloat(os.Getenv("RATE_LIMIT_RPS"), 64)
+	if err != nil || rate <= 0 {
+		return nil
+	}
+	burst, err := strconv.Atoi(os.Getenv("RATE_LIMIT_BURST"))
+	if err != nil || burst < 1 {
+		burst = int(math.Max(1, math.Ceil(2*rate)))
+	}
+	return m.NewRateLimiter(rate, burst)
+}
+
+// eventBufferSize reads SSE_BUFFER_SIZE, the number of events kept for resuming streams
+func eventBufferSize() int {
+	size, err := strconv.Atoi(os.Getenv("SSE_BUFFER_SIZE"))
+	if err != nil || size < 0 {
+		return 1000
+	}
+	return size
+}
+
+// newOutboxSinks reads the comma separated OUTBOX_SINKS, e.g. "log,webhook,nats,email"
+func newOutboxSinks(webhooks webhook_subscription.WebhookRepositoryInterface, prefs notification_preference.NotificationPreferenceRepositoryInterface) []outbox.Sink {
+	names := os.Getenv("OUTBOX_SINKS")
+	if names == "" {
+		names = "log,webhook"
+	}
 
-	return &Threedee{corsConfig.Handler(router)}
+	sinks := make([]outbox.Sink, 0)
+	for _, name := range strings.Split(names, ",") {
+		switch strings.TrimSpace(name) {
+		case "log":
+			sinks = append(sinks, outbox.NewLogSink())
+		case "webhook":
+			sinks = append(sinks, outbox.NewWebhookSink(webhook.NewDispatcher(webhooks)))
+		case "nats":
+			sinks = append(sinks, outbox.NewNatsSink(os.Getenv("NATS_URL"), os.Getenv("NATS_SUBJECT_PREFIX")))
+		case "email":
+			templates, err := email.NewTemplates()
+			if err != nil {
+				log.Println("email sink disabled:", err)
+				continue
+			}
+			sender := email.NewSmtpSender(os.Getenv("SMTP_ADDR"), os.Getenv("SMTP_FROM"), os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"))
+			sinks = append(sinks, outbox.NewEmailSink(prefs, sender, templates))
+		default:
+			log.Println("unknown outbox sink:", name)
+		}
+	}
+	return sinks
 }
@@ -0,0 +1,156 @@
+package threedee_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"threedee"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// ThreedeeTestSuite runs the whole API against the repositories of Driver, memory or
+// sqlite
+type ThreedeeTestSuite struct {
+	suite.Suite
+	Driver string
+	server *httptest.Server
+	client *http.Client
+}
+
+func (suite *ThreedeeTestSuite) SetupTest() {
+	os.Setenv("DB_DRIVER", suite.Driver)
+	os.Setenv("SQLITE_PATH", filepath.Join(suite.T().TempDir(), "threedee.db"))
+	os.Setenv("OUTBOX_SINKS", "log")
+	os.Setenv("STORAGE_LOCAL_ROOT", suite.T().TempDir())
+	suite.server = httptest.NewServer(threedee.NewThreedee().Router)
+	// a request waiting for a connection held by its own transaction fails instead of hanging
+	suite.client = &http.Client{Timeout: 10 * time.Second}
+}
+
+func (suite *ThreedeeTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *ThreedeeTestSuite) do(method string, path string, body string) (int, map[string]interface{}) {
+	req, _ := http.NewRequest(method, suite.server.URL+path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	res, err := suite.client.Do(req)
+	suite.Require().Nil(err)
+	defer res.Body.Close()
+
+	var meta struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	json.NewDecoder(res.Body).Decode(&meta)
+	return res.StatusCode, meta.Data
+}
+
+func (suite *ThreedeeTestSuite) TestPrintRequestLifecycle() {
+	code, created := suite.do("POST", "/print-requests", `{"item_name":"Cup Holder","requestor":"andi","estimated_weight":12.5}`)
+	suite.Equal(http.StatusOK, code)
+	suite.Equal(float64(1), created["id"])
+	suite.Equal("pending_review", created["status"])
+
+	code, _ = suite.do("PUT", "/print-requests/1/status", `{"status":"approved"}`)
+	suite.Equal(http.StatusConflict, code)
+	code, _ = suite.do("PUT", "/print-requests/1/review", `{"author":"andi","action":"approve"}`)
+	suite.Equal(http.StatusForbidden, code)
+	code, reviewed := suite.do("PUT", "/print-requests/1/review", `{"author":"sari","action":"approve"}`)
+	suite.Equal(http.StatusOK, code)
+	suite.Len(reviewed["thread"], 1)
+
+	code, shown := suite.do("GET", "/print-requests/1", "")
+	suite.Equal(http.StatusOK, code)
+	suite.Equal("approved", shown["status"])
+
+	code, _ = suite.do("DELETE", "/print-requests/1", "")
+	suite.Equal(http.StatusOK, code)
+
+	code, _ = suite.do("GET", "/print-requests/1", "")
+	suite.Equal(http.StatusNotFound, code)
+	code, _ = suite.do("DELETE", "/print-requests/1", "")
+	suite.Equal(http.StatusNotFound, code)
+}
+
+// TestPostRoutes checks the POST routes sharing the segment after /print-requests
+func (suite *ThreedeeTestSuite) TestPostRoutes() {
+	code, imported := suite.do("POST", "/print-requests/import?format=jsonl", `{"item_name":"Cup Holder","requestor":"andi"}`)
+	suite.Equal(http.StatusOK, code)
+	suite.Equal(float64(1), imported["imported"])
+
+	code, _ = suite.do("POST", "/print-requests/batch/status", `{"ids":[1],"status":"pending_review"}`)
+	suite.Equal(http.StatusOK, code)
+
+	code, comment := suite.do("POST", "/print-requests/1/comments", `{"author":"andi","body":"Red please"}`)
+	suite.Equal(http.StatusOK, code)
+	suite.Equal("Red please", comment["body"])
+
+	code, _ = suite.do("POST", "/print-requests/2/comments", `{"author":"andi","body":"Red please"}`)
+	suite.Equal(http.StatusNotFound, code)
+	code, _ = suite.do("POST", "/print-requests/1/status", `{"status":"approved"}`)
+	suite.Equal(http.StatusNotFound, code)
+	code, _ = suite.do("POST", "/print-requests/export", "")
+	suite.Equal(http.StatusNotFound, code)
+}
+
+func (suite *ThreedeeTestSuite) TestProjects() {
+	code, created := suite.do("POST", "/projects", `{"name":"Drone Frame","owner":"andi"}`)
+	suite.Equal(http.StatusOK, code)
+	suite.Equal("empty", created["status"])
+
+	suite.do("POST", "/print-requests", `{"item_name":"Arm","requestor":"andi","estimated_weight":20,"quantity":4,"project_id":1}`)
+	suite.do("POST", "/print-requests", `{"item_name":"Body","requestor":"andi","estimated_weight":50,"project_id":1}`)
+	code, _ = suite.do("POST", "/print-requests", `{"item_name":"Canopy","requestor":"andi","project_id":2}`)
+	suite.Equal(http.StatusBadRequest, code)
+
+	code, shown := suite.do("GET", "/projects/1", "")
+	suite.Equal(http.StatusOK, code)
+	suite.Equal("in_review", shown["status"])
+	suite.Equal(float64(70), shown["estimated_weight"])
+	suite.Equal(float64(1.4), shown["estimated_cost"])
+
+	code, updated := suite.do("PUT", "/print-requests/2", `{"item_name":"Body v2","requestor":"andi","estimated_weight":60,"project_id":1}`)
+	suite.Equal(http.StatusOK, code)
+	suite.Equal("Body v2", updated["item_name"])
+	code, _ = suite.do("PUT", "/print-requests/2", `{"item_name":"Body v2","requestor":"andi","project_id":2}`)
+	suite.Equal(http.StatusBadRequest, code)
+
+	code, _ = suite.do("GET", "/projects/1/print-requests", "")
+	suite.Equal(http.StatusOK, code)
+	code, _ = suite.do("DELETE", "/projects/1", "")
+	suite.Equal(http.StatusConflict, code)
+}
+
+func (suite *ThreedeeTestSuite) TestPrintJobs() {
+	suite.do("POST", "/print-requests", `{"item_name":"Cup Holder","requestor":"andi","estimated_weight":40,"estimated_duration":7200}`)
+	code, _ := suite.do("POST", "/print-requests/1/jobs", `{"printer_id":1,"started_on":"2021-03-01T10:00:00Z"}`)
+	suite.Equal(http.StatusConflict, code)
+	suite.do("PUT", "/print-requests/1/review", `{"author":"sari","action":"approve"}`)
+
+	code, job := suite.do("POST", "/print-requests/1/jobs", `{"printer_id":1,"started_on":"2021-03-01T10:00:00Z"}`)
+	suite.Equal(http.StatusOK, code)
+	suite.Equal("printing", job["outcome"])
+	code, job = suite.do("PUT", "/print-requests/1/jobs/1", `{"printer_id":1,"started_on":"2021-03-01T10:00:00Z","ended_on":"2021-03-01T12:30:00Z","filament_used":36,"outcome":"succeeded"}`)
+	suite.Equal(http.StatusOK, code)
+	suite.Equal(float64(9000), job["duration"])
+
+	code, report := suite.do("GET", "/reports/estimates?from=2021-03-01&to=2021-03-31", "")
+	suite.Equal(http.StatusOK, code)
+	suite.Equal(float64(1), report["requests"])
+	suite.Equal(0.25, report["duration_bias"])
+	suite.Equal(-0.1, report["weight_bias"])
+}
+
+func TestThreedeeTestSuite(t *testing.T) {
+	suite.Run(t, &ThreedeeTestSuite{Driver: "memory"})
+}
+
+func TestThreedeeSqliteTestSuite(t *testing.T) {
+	suite.Run(t, &ThreedeeTestSuite{Driver: "sqlite"})
+}
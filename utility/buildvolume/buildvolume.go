@@ -0,0 +1,99 @@
+package buildvolume
+
+import (
+	"fmt"
+	"strings"
+	"threedee/entity"
+)
+
+/*
+ * Build Volume Check
+ *
+ * A part fits a printer when its bounding box fits the build volume in at least one of the
+ * six axis-aligned orientations, i.e. the part may be laid on any of its sides.
+ */
+
+var dimensionNames = [3]string{"width", "depth", "height"}
+
+// orientations lists which part axis ends up on the printer's x, y and z axis
+var orientations = [6][3]int{
+	{0, 1, 2}, {1, 0, 2},
+	{0, 2, 1}, {2, 0, 1},
+	{1, 2, 0}, {2, 1, 0},
+}
+
+type Exceeded struct {
+	Dimension string  `json:"dimension"`
+	Size      float64 `json:"size"`
+	Limit     float64 `json:"limit"`
+}
+
+type PrinterMismatch struct {
+	Printer  string     `json:"printer"`
+	Exceeded []Exceeded `json:"exceeded"`
+}
+
+// NoFitError lists, for every printer, the dimensions that exceeded its build volume in
+// the orientation that came closest to fitting.
+type NoFitError struct {
+	Width, Depth, Height float64
+	Printers             []PrinterMismatch
+}
+
+func (e *NoFitError) Error() string {
+	parts := make([]string, 0, len(e.Printers))
+	for _, p := range e.Printers {
+		exceeded := make([]string, 0, len(p.Exceeded))
+		for _, x := range p.Exceeded {
+			exceeded = append(exceeded, fmt.Sprintf("%s %.1f > %.1f mm", x.Dimension, x.Size, x.Limit))
+		}
+		parts = append(parts, fmt.Sprintf("%s (%s)", p.Printer, strings.Join(exceeded, ", ")))
+	}
+	return fmt.Sprintf("part of %.1f x %.1f x %.1f mm does not fit any printer: %s",
+		e.Width, e.Depth, e.Height, strings.Join(parts, "; "))
+}
+
+// Check returns nil when at least one printer can print a part of the given size, and a
+// *NoFitError otherwise. Without printers there is nothing to check against.
+func Check(width, depth, height float64, printers []*entity.Printer) error {
+	if len(printers) == 0 {
+		return nil
+	}
+
+	size := [3]float64{width, depth, height}
+	noFit := &NoFitError{Width: width, Depth: depth, Height: height}
+	for _, p := range printers {
+		exceeded := closestFit(size, [3]float64{p.BuildWidth, p.BuildDepth, p.BuildHeight})
+		if len(exceeded) == 0 {
+			return nil
+		}
+		noFit.Printers = append(noFit.Printers, PrinterMismatch{Printer: p.Name, Exceeded: exceeded})
+	}
+	return noFit
+}
+
+// closestFit returns the exceeded dimensions of the orientation with the fewest and
+// smallest overshoots, which is empty when the part fits.
+func closestFit(size, volume [3]float64) []Exceeded {
+	var best []Exceeded
+	bestOvershoot := -1.0
+	for _, o := range orientations {
+		var exceeded []Exceeded
+		overshoot := 0.0
+		for axis := 0; axis < 3; axis++ {
+			s := size[o[axis]]
+			if s > volume[axis] {
+				exceeded = append(exceeded, Exceeded{Dimension: dimensionNames[o[axis]], Size: s, Limit: volume[axis]})
+				overshoot += s - volume[axis]
+			}
+		}
+		if len(exceeded) == 0 {
+			return nil
+		}
+		if bestOvershoot < 0 || len(exceeded) < len(best) || (len(exceeded) == len(best) && overshoot < bestOvershoot) {
+			best = exceeded
+			bestOvershoot = overshoot
+		}
+	}
+	return best
+}
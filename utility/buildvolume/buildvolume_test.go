@@ -0,0 +1,61 @@
+package buildvolume_test
+
+import (
+	"testing"
+	"threedee/entity"
+	"threedee/utility/buildvolume"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BuildVolumeTestSuite struct {
+	suite.Suite
+	printers []*entity.Printer
+}
+
+func (suite *BuildVolumeTestSuite) SetupTest() {
+	suite.printers = []*entity.Printer{
+		{Id: 1, Name: "Ender 3", BuildWidth: 220, BuildDepth: 220, BuildHeight: 250},
+		{Id: 2, Name: "Prusa MK3S", BuildWidth: 250, BuildDepth: 210, BuildHeight: 210},
+	}
+}
+
+func (suite *BuildVolumeTestSuite) TestCheck() {
+	var testCase = []struct {
+		testcase string
+		size     [3]float64
+		printers []*entity.Printer
+		fits     bool
+	}{
+		{"fits as is", [3]float64{100, 100, 100}, suite.printers, true},
+		{"fits the larger printer only", [3]float64{240, 200, 200}, suite.printers, true},
+		{"fits when laid down", [3]float64{50, 50, 245}, suite.printers[1:], true},
+		{"fits when rotated", [3]float64{200, 245, 50}, suite.printers[1:], true},
+		{"too large for every printer", [3]float64{300, 100, 100}, suite.printers, false},
+		{"no printers registered", [3]float64{900, 900, 900}, nil, true},
+	}
+	for _, tc := range testCase {
+		err := buildvolume.Check(tc.size[0], tc.size[1], tc.size[2], tc.printers)
+		if tc.fits {
+			suite.Nil(err, tc.testcase)
+		} else {
+			suite.NotNil(err, tc.testcase)
+		}
+	}
+}
+
+func (suite *BuildVolumeTestSuite) TestNoFitError() {
+	err := buildvolume.Check(300, 100, 260, suite.printers)
+
+	noFit, ok := err.(*buildvolume.NoFitError)
+	suite.Require().True(ok)
+	suite.Len(noFit.Printers, 2)
+	suite.Equal([]buildvolume.Exceeded{{Dimension: "width", Size: 300, Limit: 220}, {Dimension: "height", Size: 260, Limit: 250}}, noFit.Printers[0].Exceeded)
+	suite.Equal("part of 300.0 x 100.0 x 260.0 mm does not fit any printer: "+
+		"Ender 3 (width 300.0 > 220.0 mm, height 260.0 > 250.0 mm); "+
+		"Prusa MK3S (width 300.0 > 250.0 mm, height 260.0 > 210.0 mm)", err.Error())
+}
+
+func TestBuildVolumeTestSuite(t *testing.T) {
+	suite.Run(t, new(BuildVolumeTestSuite))
+}
@@ -0,0 +1,49 @@
+package estimator
+
+import (
+	"math"
+	"threedee/entity"
+)
+
+// Accuracy sets the errors of the rows, the actual duration and weight of the succeeded jobs
+// against the estimates, and sums them. Errors are rounded to 4 decimals, 0.25 means the
+// print took 25% more than estimated.
+func Accuracy(rows []*entity.EstimateRow) *entity.EstimateAccuracy {
+	result := &entity.EstimateAccuracy{Requests: len(rows), Rows: rows}
+	var durationSum, durationAbs, weightSum, weightAbs float64
+	for _, row := range rows {
+		if row.EstimatedDuration > 0 {
+			v := relativeError(float64(row.ActualDuration), float64(row.EstimatedDuration))
+			row.DurationError = roundError(v)
+			durationSum += v
+			durationAbs += math.Abs(v)
+			result.RequestsWithDuration++
+		}
+		// jobs recorded before filament_used was required may not have it
+		if row.EstimatedWeight > 0 && row.ActualWeight > 0 {
+			v := relativeError(float64(row.ActualWeight), float64(row.EstimatedWeight))
+			row.WeightError = roundError(v)
+			weightSum += v
+			weightAbs += math.Abs(v)
+			result.RequestsWithWeight++
+		}
+	}
+
+	if result.RequestsWithDuration > 0 {
+		result.DurationBias = roundError(durationSum / float64(result.RequestsWithDuration))
+		result.DurationMeanAbsoluteError = roundError(durationAbs / float64(result.RequestsWithDuration))
+	}
+	if result.RequestsWithWeight > 0 {
+		result.WeightBias = roundError(weightSum / float64(result.RequestsWithWeight))
+		result.WeightMeanAbsoluteError = roundError(weightAbs / float64(result.RequestsWithWeight))
+	}
+	return result
+}
+
+func relativeError(actual float64, estimated float64) float64 {
+	return (actual - estimated) / estimated
+}
+
+func roundError(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
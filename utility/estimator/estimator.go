@@ -0,0 +1,184 @@
+package estimator
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"path/filepath"
+	"strings"
+	"threedee/entity"
+	"threedee/utility/gcode"
+	"threedee/utility/mesh"
+	"threedee/utility/threemf"
+)
+
+/*
+ * Estimator turns an uploaded print file into the estimates stored on a PrintRequest.
+ *
+ * Stored units follow the README: est_weight in gram, est_filament_length in cm and
+ * est_duration in second, for all copies of a request. When a file does not carry a value
+ * (e.g. the slicer did not report filament weight) it is derived from the filament length
+ * and the density of the requested material.
+ *
+ * Model files (3MF) carry geometry instead of slicer output. Their estimates fill the
+ * model volume by FillFactor of the infill and supports, a rough guess of what the slicer
+ * will use, and they can not tell the duration.
+ */
+
+const FilamentDiameter = 1.75 // mm
+
+// Share of a model printed solid as walls, top and bottom whatever the infill, and the
+// material added by supports
+const (
+	ShellFraction = 0.3
+	SupportFactor = 1.15
+)
+
+type Material struct {
+	Density    float64 // g/cm3
+	PricePerKg float64
+}
+
+// Materials are the filaments by entity.Materials. Prices are in the currency of the lab
+// and can be changed with SetPrices.
+var Materials = map[string]Material{
+	entity.MaterialPla:   {Density: 1.24, PricePerKg: 20},
+	entity.MaterialPetg:  {Density: 1.27, PricePerKg: 22},
+	entity.MaterialAbs:   {Density: 1.04, PricePerKg: 20},
+	entity.MaterialAsa:   {Density: 1.07, PricePerKg: 25},
+	entity.MaterialTpu:   {Density: 1.21, PricePerKg: 35},
+	entity.MaterialNylon: {Density: 1.14, PricePerKg: 45},
+}
+
+type Estimate struct {
+	Weight         float32 // g, 0 when the slicer did not report it
+	FilamentLength float32 // cm
+	Duration       int     // s
+
+	// Only known for model files
+	Objects int
+	Volume  float64 // mm3
+	Width   float64 // mm
+	Depth   float64 // mm
+	Height  float64 // mm
+}
+
+func FromFile(filename string, data []byte) (*Estimate, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".gcode", ".gco", ".g":
+		return fromGcode(data)
+	case ".3mf":
+		meshes, err := threemf.Parse(data)
+		if err != nil {
+			return nil, err
+		}
+		return fromMeshes(meshes), nil
+	default:
+		return nil, errors.New("unsupported file type, expected .gcode or .3mf")
+	}
+}
+
+// Apply overwrites the estimates of the model with the computed ones for its print
+// settings and quantity, leaving fields the file could not provide untouched.
+func (e *Estimate) Apply(model *entity.PrintRequest) {
+	quantity := model.Quantity
+	if quantity < 1 {
+		quantity = 1
+	}
+
+	length := float64(e.FilamentLength) * 10
+	if e.Volume > 0 {
+		length = LengthFromVolume(e.Volume * FillFactor(model.Infill, model.Supports))
+	}
+	weight := float64(e.Weight)
+	if weight == 0 {
+		weight = WeightFromLength(length, model.Material)
+	}
+
+	if weight > 0 {
+		model.EstimatedWeight = round(weight * float64(quantity))
+	}
+	if length > 0 {
+		model.EstimatedFilamentLength = round(length / 10 * float64(quantity))
+	}
+	if e.Duration > 0 {
+		model.EstimatedDuration = e.Duration * quantity
+	}
+}
+
+// FillFactor returns the share of a model volume that is printed with infill percent,
+// the walls are always solid
+func FillFactor(infill int, supports bool) float64 {
+	factor := ShellFraction + (1-ShellFraction)*float64(infill)/100
+	if supports {
+		factor *= SupportFactor
+	}
+	return factor
+}
+
+// Cost returns the price of the filament of the estimated weight of model
+func Cost(model *entity.PrintRequest) float32 {
+	return round(float64(model.EstimatedWeight) / 1000 * material(model.Material).PricePerKg)
+}
+
+// SetPrices changes the price per kg of materials, unknown materials are ignored
+func SetPrices(prices map[string]float64) {
+	for name, price := range prices {
+		if m, ok := Materials[name]; ok {
+			m.PricePerKg = price
+			Materials[name] = m
+		}
+	}
+}
+
+// material returns the filament of name, or of entity.DefaultMaterial when it is unknown
+func material(name string) Material {
+	if m, ok := Materials[name]; ok {
+		return m
+	}
+	return Materials[entity.DefaultMaterial]
+}
+
+func fromGcode(data []byte) (*Estimate, error) {
+	summary, err := gcode.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Estimate{
+		Weight:         round(summary.FilamentWeight),
+		FilamentLength: round(summary.FilamentLength / 10),
+		Duration:       summary.Duration,
+	}, nil
+}
+
+func fromMeshes(meshes []*mesh.Mesh) *Estimate {
+	analysis := mesh.Analyze(meshes)
+	width, depth, height := analysis.BoundingBox.Size()
+
+	return &Estimate{
+		Objects: analysis.Objects,
+		Volume:  analysis.Volume,
+		Width:   width,
+		Depth:   depth,
+		Height:  height,
+	}
+}
+
+// LengthFromVolume returns the filament length in mm needed to print the volume in mm3
+func LengthFromVolume(volume float64) float64 {
+	radius := FilamentDiameter / 2
+	return volume / (math.Pi * radius * radius)
+}
+
+// WeightFromLength returns the weight in gram of the given filament length in mm of a
+// material
+func WeightFromLength(length float64, name string) float64 {
+	radius := FilamentDiameter / 2
+	volume := math.Pi * radius * radius * length / 1000 // cm3
+	return volume * material(name).Density
+}
+
+func round(v float64) float32 {
+	return float32(math.Round(v*100) / 100)
+}
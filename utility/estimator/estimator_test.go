@@ -0,0 +1,113 @@
+package estimator_test
+
+import (
+	"testing"
+	"threedee/entity"
+	"threedee/utility/estimator"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type EstimatorTestSuite struct {
+	suite.Suite
+}
+
+func (suite *EstimatorTestSuite) TestApply() {
+	var testCase = []struct {
+		testcase string
+		estimate *estimator.Estimate
+		model    *entity.PrintRequest
+		weight   float32
+		length   float32
+		duration int
+	}{
+		{
+			testcase: "slicer weight for all copies",
+			estimate: &estimator.Estimate{Weight: 7.01, FilamentLength: 234.57, Duration: 3723},
+			model:    &entity.PrintRequest{Material: entity.MaterialPla, Quantity: 2},
+			weight:   14.02,
+			length:   469.14,
+			duration: 7446,
+		},
+		{
+			testcase: "weight from length and density",
+			estimate: &estimator.Estimate{FilamentLength: 100, Duration: 600},
+			model:    &entity.PrintRequest{Material: entity.MaterialPla, Quantity: 3},
+			weight:   8.95,
+			length:   300,
+			duration: 1800,
+		},
+		{
+			testcase: "solid model",
+			estimate: &estimator.Estimate{Volume: 10000},
+			model:    &entity.PrintRequest{Material: entity.MaterialPla, Infill: 100, Quantity: 1},
+			weight:   12.4,
+			length:   415.75,
+		},
+		{
+			testcase: "hollow model with supports",
+			estimate: &estimator.Estimate{Volume: 10000},
+			model:    &entity.PrintRequest{Material: entity.MaterialPla, Infill: 0, Supports: true, Quantity: 1},
+			weight:   4.28,
+			length:   143.43,
+		},
+		{
+			testcase: "lighter material",
+			estimate: &estimator.Estimate{Volume: 10000},
+			model:    &entity.PrintRequest{Material: entity.MaterialAbs, Infill: 100, Quantity: 1},
+			weight:   10.4,
+			length:   415.75,
+		},
+	}
+	for _, tc := range testCase {
+		tc.estimate.Apply(tc.model)
+
+		suite.Equal(tc.weight, tc.model.EstimatedWeight, tc.testcase)
+		suite.Equal(tc.length, tc.model.EstimatedFilamentLength, tc.testcase)
+		suite.Equal(tc.duration, tc.model.EstimatedDuration, tc.testcase)
+	}
+}
+
+func (suite *EstimatorTestSuite) TestCost() {
+	suite.Equal(float32(0.75), estimator.Cost(&entity.PrintRequest{Material: entity.MaterialPla, EstimatedWeight: 37.5}))
+	suite.Equal(float32(1.69), estimator.Cost(&entity.PrintRequest{Material: entity.MaterialNylon, EstimatedWeight: 37.5}))
+	suite.Equal(float32(0), estimator.Cost(&entity.PrintRequest{Material: entity.MaterialPla}))
+
+	defer estimator.SetPrices(map[string]float64{entity.MaterialPla: estimator.Materials[entity.MaterialPla].PricePerKg})
+	estimator.SetPrices(map[string]float64{entity.MaterialPla: 30, "wood": 50})
+	suite.Equal(float32(1.13), estimator.Cost(&entity.PrintRequest{Material: entity.MaterialPla, EstimatedWeight: 37.5}))
+	suite.NotContains(estimator.Materials, "wood")
+}
+
+func (suite *EstimatorTestSuite) TestAccuracy() {
+	rows := []*entity.EstimateRow{
+		{PrintRequestId: 1, EstimatedDuration: 9000, ActualDuration: 10800, EstimatedWeight: 37.5, ActualWeight: 36},
+		{PrintRequestId: 2, EstimatedDuration: 3600, ActualDuration: 3000, EstimatedWeight: 10, ActualWeight: 12},
+		{PrintRequestId: 3, ActualDuration: 600, ActualWeight: 5},
+		{PrintRequestId: 4, EstimatedWeight: 20},
+	}
+
+	result := estimator.Accuracy(rows)
+
+	suite.Equal(4, result.Requests)
+	suite.Equal(0.2, rows[0].DurationError)
+	suite.Equal(-0.04, rows[0].WeightError)
+	suite.Equal(-0.1667, rows[1].DurationError)
+	suite.Equal(0.2, rows[1].WeightError)
+	suite.Equal(float64(0), rows[2].DurationError, "no estimate")
+	suite.Equal(float64(0), rows[3].WeightError, "no filament used")
+	suite.Equal(2, result.RequestsWithDuration)
+	suite.Equal(0.0167, result.DurationBias)
+	suite.Equal(0.1833, result.DurationMeanAbsoluteError)
+	suite.Equal(2, result.RequestsWithWeight)
+	suite.Equal(0.08, result.WeightBias)
+	suite.Equal(0.12, result.WeightMeanAbsoluteError)
+
+	empty := estimator.Accuracy([]*entity.EstimateRow{})
+	suite.Equal(0, empty.Requests)
+	suite.Equal(float64(0), empty.DurationBias)
+}
+
+func TestEstimatorTestSuite(t *testing.T) {
+	suite.Run(t, new(EstimatorTestSuite))
+}
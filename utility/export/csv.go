@@ -0,0 +1,50 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"threedee/entity"
+)
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCsvWriter(w io.Writer) (*csvWriter, error) {
+	c := &csvWriter{csv.NewWriter(w)}
+	return c, c.w.Write(Columns)
+}
+
+func (c *csvWriter) Write(model *entity.PrintRequest) error {
+	record := make([]string, 0, len(Columns))
+	for _, v := range values(model) {
+		switch v := v.(type) {
+		case int:
+			record = append(record, strconv.Itoa(v))
+		case float32:
+			record = append(record, strconv.FormatFloat(float64(v), 'f', -1, 32))
+		case string:
+			record = append(record, escapeFormula(v))
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// escapeFormula keeps spreadsheet programs from running text that starts like a formula,
+// e.g. an item name of "=HYPERLINK(...)", by prefixing it with a quote
+func escapeFormula(s string) string {
+	if s == "" {
+		return s
+	}
+	switch s[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + s
+	}
+	return s
+}
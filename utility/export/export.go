@@ -0,0 +1,92 @@
+package export
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"threedee/entity"
+)
+
+/*
+ * Export writes print requests as a spreadsheet one row at a time, so an export takes the
+ * same memory whatever the number of rows. The first row holds the column names, which
+ * are the JSON field names of entity.PrintRequest.
+ */
+
+// Formats of an export
+const (
+	FormatCsv  = "csv"
+	FormatXlsx = "xlsx"
+)
+
+// Columns are the exported fields of a print request, in order
+var Columns = []string{
+	"id",
+	"item_name",
+	"requestor",
+	"status",
+	"estimated_weight",
+	"estimated_filament_length",
+	"estimated_duration",
+	"file_url",
+	"duplicate_of",
+	"notes",
+	"material",
+	"color",
+	"layer_height",
+	"infill",
+	"supports",
+	"nozzle_size",
+	"quantity",
+	"estimated_cost",
+	"project_id",
+}
+
+// values returns the cells of model in the order of Columns, strings or numbers
+func values(model *entity.PrintRequest) []interface{} {
+	return []interface{}{
+		model.Id,
+		model.ItemName,
+		model.Requestor,
+		model.Status,
+		model.EstimatedWeight,
+		model.EstimatedFilamentLength,
+		model.EstimatedDuration,
+		model.FileUrl,
+		model.DuplicateOf,
+		model.Notes,
+		model.Material,
+		model.Color,
+		model.LayerHeight,
+		model.Infill,
+		strconv.FormatBool(model.Supports),
+		model.NozzleSize,
+		model.Quantity,
+		model.EstimatedCost,
+		model.ProjectId,
+	}
+}
+
+type Writer interface {
+	// Write adds model as the next row
+	Write(model *entity.PrintRequest) error
+	// Close finishes the file, it does not close the underlying writer
+	Close() error
+}
+
+// ContentTypes are the media types of the formats
+var ContentTypes = map[string]string{
+	FormatCsv:  "text/csv; charset=utf-8",
+	FormatXlsx: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// NewWriter returns a writer of format that has written the header row to w
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCsv:
+		return newCsvWriter(w)
+	case FormatXlsx:
+		return newXlsxWriter(w)
+	}
+	return nil, errors.New("format must be csv or xlsx")
+}
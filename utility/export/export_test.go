@@ -0,0 +1,92 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"io/ioutil"
+	"testing"
+	"threedee/entity"
+	"threedee/utility/export"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ExportTestSuite struct {
+	suite.Suite
+}
+
+var exported = []*entity.PrintRequest{
+	{Id: 1, ItemName: "Cup Holder", Requestor: "andi", Status: entity.StatusReceived, EstimatedWeight: 37.5, EstimatedFilamentLength: 1250.5, EstimatedDuration: 9000,
+		Material: entity.MaterialPetg, Color: "Red", LayerHeight: 0.2, Infill: 20, Supports: true, NozzleSize: 0.4, Quantity: 2, EstimatedCost: 0.83, ProjectId: 3},
+	{Id: 2, ItemName: "=HYPERLINK(\"x\")", Requestor: "budi", Status: entity.StatusFinished, Notes: "red <PLA> & \"matte\""},
+}
+
+func (suite *ExportTestSuite) write(format string) []byte {
+	var b bytes.Buffer
+	out, err := export.NewWriter(format, &b)
+	suite.Require().Nil(err)
+	for _, item := range exported {
+		suite.Require().Nil(out.Write(item))
+	}
+	suite.Require().Nil(out.Close())
+	return b.Bytes()
+}
+
+func (suite *ExportTestSuite) TestCsv() {
+	records, err := csv.NewReader(bytes.NewReader(suite.write(export.FormatCsv))).ReadAll()
+
+	suite.Nil(err)
+	suite.Require().Len(records, 3)
+	suite.Equal(export.Columns, records[0])
+	suite.Equal([]string{"1", "Cup Holder", "andi", "received", "37.5", "1250.5", "9000", "", "0", "", "petg", "Red", "0.2", "20", "true", "0.4", "2", "0.83", "3"}, records[1])
+	suite.Equal(`'=HYPERLINK("x")`, records[2][1], "formulas are escaped")
+	suite.Equal(`red <PLA> & "matte"`, records[2][9])
+}
+
+func (suite *ExportTestSuite) TestXlsx() {
+	data := suite.write(export.FormatXlsx)
+
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	suite.Require().Nil(err)
+	parts := make(map[string]*zip.File)
+	for _, f := range z.File {
+		parts[f.Name] = f
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels"} {
+		suite.Contains(parts, name)
+	}
+
+	f, err := parts["xl/worksheets/sheet1.xml"].Open()
+	suite.Require().Nil(err)
+	sheetXml, _ := ioutil.ReadAll(f)
+	var sheet struct {
+		Rows []struct {
+			Cells []struct {
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	suite.Require().Nil(xml.Unmarshal(sheetXml, &sheet))
+
+	suite.Require().Len(sheet.Rows, 3)
+	suite.Equal("id", sheet.Rows[0].Cells[0].Inline)
+	suite.Equal("", sheet.Rows[1].Cells[0].Type, "numbers are numbers")
+	suite.Equal("37.5", sheet.Rows[1].Cells[4].Value)
+	suite.Equal("inlineStr", sheet.Rows[2].Cells[1].Type)
+	suite.Equal(`=HYPERLINK("x")`, sheet.Rows[2].Cells[1].Inline, "inline strings are never formulas")
+	suite.Equal(`red <PLA> & "matte"`, sheet.Rows[2].Cells[9].Inline)
+}
+
+func (suite *ExportTestSuite) TestUnknownFormat() {
+	_, err := export.NewWriter("ods", &bytes.Buffer{})
+
+	suite.NotNil(err)
+}
+
+func TestExportTestSuite(t *testing.T) {
+	suite.Run(t, new(ExportTestSuite))
+}
@@ -0,0 +1,104 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"threedee/entity"
+)
+
+/*
+ * An XLSX file is a zip of XML parts. Everything but the sheet is fixed, and the sheet is
+ * the last part, so rows are streamed into it as they come. Text is written as inline
+ * strings instead of a shared string table, which would have to be complete before the
+ * sheet.
+ */
+
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Print Requests" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+type xlsxWriter struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+}
+
+func newXlsxWriter(w io.Writer) (*xlsxWriter, error) {
+	z := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		f, err := z.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.WriteString(f, part.content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := z.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	x := &xlsxWriter{z, bufio.NewWriter(f)}
+	x.sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]interface{}, 0, len(Columns))
+	for _, c := range Columns {
+		header = append(header, c)
+	}
+	return x, x.writeRow(header)
+}
+
+func (x *xlsxWriter) Write(model *entity.PrintRequest) error {
+	return x.writeRow(values(model))
+}
+
+func (x *xlsxWriter) writeRow(cells []interface{}) error {
+	x.sheet.WriteString("<row>")
+	for _, v := range cells {
+		switch v := v.(type) {
+		case int:
+			x.sheet.WriteString("<c><v>" + strconv.Itoa(v) + "</v></c>")
+		case float32:
+			x.sheet.WriteString("<c><v>" + strconv.FormatFloat(float64(v), 'f', -1, 32) + "</v></c>")
+		case string:
+			x.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+			err := xml.EscapeText(x.sheet, []byte(v))
+			if err != nil {
+				return err
+			}
+			x.sheet.WriteString("</t></is></c>")
+		}
+	}
+	_, err := x.sheet.WriteString("</row>")
+	return err
+}
+
+func (x *xlsxWriter) Close() error {
+	x.sheet.WriteString("</sheetData></worksheet>")
+	err := x.sheet.Flush()
+	if err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
@@ -0,0 +1,251 @@
+package gcode
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/*
+ * G-code Parser
+ *
+ * Slicers already know how long a print takes and how much filament it needs, and they
+ * write those numbers as comments in the G-code header. Parse reads those comments first
+ * (Cura and PrusaSlicer flavors) and only falls back to walking the extrusion moves when
+ * the header does not have them.
+ *
+ * Units follow the G-code itself: millimetres for lengths, grams for weight and seconds
+ * for duration. Converting to the units stored in a PrintRequest is the caller's job.
+ */
+
+const (
+	SourceCura        = "cura"
+	SourcePrusaSlicer = "prusaslicer"
+	SourceMoves       = "moves"
+)
+
+type Summary struct {
+	FilamentLength float64 // mm
+	FilamentWeight float64 // g, zero when the slicer does not report it
+	Duration       int     // seconds
+	Source         string
+}
+
+var (
+	curaTime             = regexp.MustCompile(`^;TIME:\s*([0-9.]+)`)
+	curaFilament         = regexp.MustCompile(`^;Filament used:\s*([0-9.]+)m`)
+	prusaFilamentLength  = regexp.MustCompile(`^; filament used \[mm\] = ([0-9.]+)`)
+	prusaFilamentWeight  = regexp.MustCompile(`^; (?:total )?filament used \[g\] = ([0-9.]+)`)
+	prusaEstimatedTime   = regexp.MustCompile(`^; estimated printing time(?: \(normal mode\))? = (.+)$`)
+	prusaDurationSegment = regexp.MustCompile(`(\d+)([dhms])`)
+)
+
+func Parse(r io.Reader) (*Summary, error) {
+	header := &Summary{}
+	moves := newMoveTracker()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, ";") {
+			readHeaderComment(line, header)
+			continue
+		}
+		moves.apply(line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &Summary{
+		FilamentLength: header.FilamentLength,
+		FilamentWeight: header.FilamentWeight,
+		Duration:       header.Duration,
+		Source:         header.Source,
+	}
+	if result.FilamentLength == 0 {
+		result.FilamentLength = moves.extruded
+		result.Source = SourceMoves
+	}
+	if result.Duration == 0 {
+		result.Duration = int(math.Round(moves.seconds))
+		result.Source = SourceMoves
+	}
+	if result.FilamentLength <= 0 {
+		return nil, errors.New("gcode does not contain any extrusion")
+	}
+	return result, nil
+}
+
+func readHeaderComment(line string, s *Summary) {
+	if m := curaTime.FindStringSubmatch(line); m != nil {
+		if v, err := strconv.ParseFloat(m[1], 64); err == nil {
+			s.Duration = int(math.Round(v))
+			s.Source = SourceCura
+		}
+		return
+	}
+	if m := curaFilament.FindStringSubmatch(line); m != nil {
+		if v, err := strconv.ParseFloat(m[1], 64); err == nil {
+			s.FilamentLength = v * 1000
+			s.Source = SourceCura
+		}
+		return
+	}
+	if m := prusaFilamentLength.FindStringSubmatch(line); m != nil {
+		if v, err := strconv.ParseFloat(m[1], 64); err == nil {
+			s.FilamentLength = v
+			s.Source = SourcePrusaSlicer
+		}
+		return
+	}
+	if m := prusaFilamentWeight.FindStringSubmatch(line); m != nil {
+		if v, err := strconv.ParseFloat(m[1], 64); err == nil {
+			s.FilamentWeight = v
+			s.Source = SourcePrusaSlicer
+		}
+		return
+	}
+	if m := prusaEstimatedTime.FindStringSubmatch(line); m != nil {
+		if v := parsePrusaDuration(m[1]); v > 0 {
+			s.Duration = v
+			s.Source = SourcePrusaSlicer
+		}
+	}
+}
+
+// parsePrusaDuration reads durations like "1d 2h 3m 4s" into seconds
+func parsePrusaDuration(value string) int {
+	total := 0
+	for _, m := range prusaDurationSegment.FindAllStringSubmatch(value, -1) {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "d":
+			total += n * 86400
+		case "h":
+			total += n * 3600
+		case "m":
+			total += n * 60
+		case "s":
+			total += n
+		}
+	}
+	return total
+}
+
+// moveTracker follows the tool head through G0/G1 moves to sum the extruded filament and
+// approximate the time spent moving when the slicer header is missing.
+type moveTracker struct {
+	x, y, z, e       float64
+	feedrate         float64 // mm/min
+	absolutePosition bool
+	absoluteExtruder bool
+	extruded         float64
+	seconds          float64
+}
+
+func newMoveTracker() *moveTracker {
+	return &moveTracker{
+		feedrate:         1500,
+		absolutePosition: true,
+		absoluteExtruder: true,
+	}
+}
+
+func (t *moveTracker) apply(line string) {
+	if i := strings.Index(line, ";"); i >= 0 {
+		line = line[:i]
+	}
+	fields := strings.Fields(strings.ToUpper(line))
+	if len(fields) == 0 {
+		return
+	}
+
+	switch fields[0] {
+	case "G90":
+		t.absolutePosition = true
+		t.absoluteExtruder = true
+	case "G91":
+		t.absolutePosition = false
+		t.absoluteExtruder = false
+	case "M82":
+		t.absoluteExtruder = true
+	case "M83":
+		t.absoluteExtruder = false
+	case "G92":
+		for _, f := range fields[1:] {
+			if v, ok := parseWord(f, 'E'); ok {
+				t.e = v
+			}
+		}
+	case "G0", "G1":
+		t.move(fields[1:])
+	}
+}
+
+func (t *moveTracker) move(words []string) {
+	x, y, z := t.x, t.y, t.z
+	extrusion := 0.0
+	for _, w := range words {
+		v, err := strconv.ParseFloat(w[1:], 64)
+		if err != nil {
+			continue
+		}
+		switch w[0] {
+		case 'X':
+			x = t.target(t.x, v)
+		case 'Y':
+			y = t.target(t.y, v)
+		case 'Z':
+			z = t.target(t.z, v)
+		case 'E':
+			if t.absoluteExtruder {
+				extrusion = v - t.e
+				t.e = v
+			} else {
+				extrusion = v
+			}
+		case 'F':
+			if v > 0 {
+				t.feedrate = v
+			}
+		}
+	}
+
+	// Retractions pull filament back and the matching prime pushes the same length again,
+	// so the net sum is what actually left the nozzle.
+	t.extruded += extrusion
+
+	distance := math.Sqrt((x-t.x)*(x-t.x) + (y-t.y)*(y-t.y) + (z-t.z)*(z-t.z))
+	if distance == 0 {
+		distance = math.Abs(extrusion)
+	}
+	t.seconds += distance / (t.feedrate / 60)
+	t.x, t.y, t.z = x, y, z
+}
+
+func (t *moveTracker) target(current, value float64) float64 {
+	if t.absolutePosition {
+		return value
+	}
+	return current + value
+}
+
+func parseWord(word string, letter byte) (float64, bool) {
+	if len(word) < 2 || word[0] != letter {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(word[1:], 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
@@ -0,0 +1,90 @@
+package gcode_test
+
+import (
+	"strings"
+	"testing"
+	"threedee/utility/gcode"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type GcodeTestSuite struct {
+	suite.Suite
+}
+
+func (suite *GcodeTestSuite) TestParse() {
+	var testCase = []struct {
+		testcase       string
+		gcode          string
+		isError        bool
+		filamentLength float64
+		filamentWeight float64
+		duration       int
+		source         string
+	}{
+		{
+			testcase: "cura header",
+			gcode: ";FLAVOR:Marlin\n" +
+				";TIME:6666\n" +
+				";Filament used: 1.5m\n" +
+				"G1 X10 Y10 E1\n",
+			filamentLength: 1500,
+			duration:       6666,
+			source:         gcode.SourceCura,
+		},
+		{
+			testcase: "prusaslicer footer",
+			gcode: "G1 X10 Y10 E1\n" +
+				"; filament used [mm] = 2345.67\n" +
+				"; filament used [g] = 7.01\n" +
+				"; estimated printing time (normal mode) = 1d 2h 3m 4s\n",
+			filamentLength: 2345.67,
+			filamentWeight: 7.01,
+			duration:       93784,
+			source:         gcode.SourcePrusaSlicer,
+		},
+		{
+			testcase: "absolute extrusion with retraction and reset",
+			gcode: "G90\nM82\nG1 F6000\n" +
+				"G1 X10 E5\n" +
+				"G1 E4\n" +
+				"G1 E5\n" +
+				"G92 E0\n" +
+				"G1 X20 E3 ; comment\n",
+			filamentLength: 8,
+			duration:       0,
+			source:         gcode.SourceMoves,
+		},
+		{
+			testcase:       "relative extrusion",
+			gcode:          "M83\nG1 F600\nG1 X10 E2\nG1 X20 E2\nG1 E-1\nG1 E1\n",
+			filamentLength: 4,
+			duration:       2,
+			source:         gcode.SourceMoves,
+		},
+		{
+			testcase: "no extrusion",
+			gcode:    "G28\nG1 X10 Y10\n",
+			isError:  true,
+		},
+	}
+	for _, tc := range testCase {
+		result, err := gcode.Parse(strings.NewReader(tc.gcode))
+
+		if tc.isError {
+			suite.NotNil(err, tc.testcase)
+			continue
+		}
+		suite.Nil(err, tc.testcase)
+		suite.InDelta(tc.filamentLength, result.FilamentLength, 0.001, tc.testcase)
+		suite.InDelta(tc.filamentWeight, result.FilamentWeight, 0.001, tc.testcase)
+		suite.Equal(tc.source, result.Source, tc.testcase)
+		if tc.duration > 0 {
+			suite.Equal(tc.duration, result.Duration, tc.testcase)
+		}
+	}
+}
+
+func TestGcodeTestSuite(t *testing.T) {
+	suite.Run(t, new(GcodeTestSuite))
+}
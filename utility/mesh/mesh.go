@@ -0,0 +1,83 @@
+package mesh
+
+import "math"
+
+/*
+ * Mesh holds triangle geometry in millimetres, independent of the file format it was read
+ * from, so every model format shares the same volume and bounding box analysis.
+ */
+
+type Vertex struct {
+	X, Y, Z float64
+}
+
+type Triangle struct {
+	V1, V2, V3 int
+}
+
+type Mesh struct {
+	Name      string
+	Vertices  []Vertex
+	Triangles []Triangle
+}
+
+type BoundingBox struct {
+	Min Vertex
+	Max Vertex
+}
+
+// Size returns the width (x), depth (y) and height (z) of the box
+func (b BoundingBox) Size() (float64, float64, float64) {
+	return b.Max.X - b.Min.X, b.Max.Y - b.Min.Y, b.Max.Z - b.Min.Z
+}
+
+// Extend grows the box so it also contains other
+func (b BoundingBox) Extend(other BoundingBox) BoundingBox {
+	return BoundingBox{
+		Min: Vertex{math.Min(b.Min.X, other.Min.X), math.Min(b.Min.Y, other.Min.Y), math.Min(b.Min.Z, other.Min.Z)},
+		Max: Vertex{math.Max(b.Max.X, other.Max.X), math.Max(b.Max.Y, other.Max.Y), math.Max(b.Max.Z, other.Max.Z)},
+	}
+}
+
+// Volume returns the enclosed volume in mm3 using the signed tetrahedron method. The
+// mesh has to be closed for the result to be meaningful.
+func (m *Mesh) Volume() float64 {
+	total := 0.0
+	for _, t := range m.Triangles {
+		a, b, c := m.Vertices[t.V1], m.Vertices[t.V2], m.Vertices[t.V3]
+		total += a.X*(b.Y*c.Z-b.Z*c.Y) - a.Y*(b.X*c.Z-b.Z*c.X) + a.Z*(b.X*c.Y-b.Y*c.X)
+	}
+	return math.Abs(total / 6)
+}
+
+func (m *Mesh) BoundingBox() BoundingBox {
+	if len(m.Vertices) == 0 {
+		return BoundingBox{}
+	}
+	box := BoundingBox{Min: m.Vertices[0], Max: m.Vertices[0]}
+	for _, v := range m.Vertices[1:] {
+		box = box.Extend(BoundingBox{Min: v, Max: v})
+	}
+	return box
+}
+
+// Analysis summarizes one or more meshes that are printed together
+type Analysis struct {
+	Objects     int
+	Volume      float64 // mm3
+	BoundingBox BoundingBox
+}
+
+func Analyze(meshes []*Mesh) *Analysis {
+	result := &Analysis{}
+	for i, m := range meshes {
+		result.Objects++
+		result.Volume += m.Volume()
+		if i == 0 {
+			result.BoundingBox = m.BoundingBox()
+		} else {
+			result.BoundingBox = result.BoundingBox.Extend(m.BoundingBox())
+		}
+	}
+	return result
+}
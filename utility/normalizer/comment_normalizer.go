@@ -0,0 +1,95 @@
+package normalizer
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"threedee/entity"
+)
+
+// MaxAttachments limits the number of images of one comment
+const MaxAttachments = 5
+
+// imageTypes are the attachment extensions and the content types their files must have
+var imageTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+}
+
+type CommentNormalizer struct {
+}
+
+func NewCommentNormalizer() *CommentNormalizer {
+	return &CommentNormalizer{}
+}
+
+// ReadAndNormalize reads a comment from a JSON body, or from a multipart/form-data body
+// with the images in "attachments" fields. A comment needs a body or an image.
+func (*CommentNormalizer) ReadAndNormalize(w http.ResponseWriter, r *http.Request) (*entity.Comment, []*Upload, error) {
+	var output *entity.Comment
+	uploads := make([]*Upload, 0)
+	if isMultipart(r) {
+		r.Body = http.MaxBytesReader(w, r.Body, MaxUploadSize)
+		defer r.Body.Close()
+
+		err := r.ParseMultipartForm(MaxUploadSize)
+		if err != nil {
+			return nil, nil, errors.New("failed to read multipart request body")
+		}
+		output = entity.NewComment()
+		output.Author = r.FormValue("author")
+		output.Body = r.FormValue("body")
+
+		for _, header := range r.MultipartForm.File["attachments"] {
+			file, err := header.Open()
+			if err != nil {
+				return nil, nil, errors.New("failed to read attachment " + header.Filename)
+			}
+			data, err := ioutil.ReadAll(file)
+			file.Close()
+			if err != nil {
+				return nil, nil, errors.New("failed to read attachment " + header.Filename)
+			}
+			uploads = append(uploads, &Upload{Filename: header.Filename, Data: data})
+		}
+	} else {
+		b, err := ioutil.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			return nil, nil, errors.New("failed to read request body")
+		}
+		err = json.Unmarshal(b, &output)
+		if err != nil || output == nil {
+			return nil, nil, errors.New("failed to unmarshal request body")
+		}
+	}
+
+	// Validate, attachments only come from uploads
+	output.Author = strings.TrimSpace(output.Author)
+	output.Body = strings.TrimSpace(output.Body)
+	output.Attachments = make([]*entity.Attachment, 0)
+	if output.Author == "" {
+		return nil, nil, errors.New("author is required")
+	}
+	if output.Body == "" && len(uploads) == 0 {
+		return nil, nil, errors.New("body or attachments are required")
+	}
+	if len(uploads) > MaxAttachments {
+		return nil, nil, errors.New("at most " + strconv.Itoa(MaxAttachments) + " attachments are allowed")
+	}
+	for _, upload := range uploads {
+		contentType, ok := imageTypes[strings.ToLower(filepath.Ext(upload.Filename))]
+		if !ok || http.DetectContentType(upload.Data) != contentType {
+			return nil, nil, errors.New("attachment " + upload.Filename + " must be a PNG, JPEG, GIF or WebP image")
+		}
+	}
+
+	return output, uploads, nil
+}
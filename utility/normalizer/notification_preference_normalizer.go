@@ -0,0 +1,43 @@
+package normalizer
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/mail"
+	"threedee/entity"
+)
+
+type NotificationPreferenceNormalizer struct {
+}
+
+func NewNotificationPreferenceNormalizer() *NotificationPreferenceNormalizer {
+	return &NotificationPreferenceNormalizer{}
+}
+
+func (*NotificationPreferenceNormalizer) ReadAndNormalize(w http.ResponseWriter, r *http.Request) (*entity.NotificationPreference, error) {
+	// Read body
+	b, err := ioutil.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		return nil, errors.New("failed to read request body")
+	}
+
+	// Unmarshal
+	var output *entity.NotificationPreference
+	err = json.Unmarshal(b, &output)
+	if err != nil || output == nil {
+		return nil, errors.New("failed to unmarshal request body")
+	}
+
+	// Validate, an empty email falls back to the requestor name when it is an address
+	if output.Email != "" {
+		address, err := mail.ParseAddress(output.Email)
+		if err != nil || address.Address != output.Email {
+			return nil, errors.New("email must be a plain email address")
+		}
+	}
+
+	return output, nil
+}
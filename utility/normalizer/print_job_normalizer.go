@@ -0,0 +1,96 @@
+package normalizer
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"threedee/entity"
+	"time"
+)
+
+// MaxFailureReasonLength limits the failure reason of a print job
+const MaxFailureReasonLength = 500
+
+type PrintJobNormalizer struct {
+}
+
+func NewPrintJobNormalizer() *PrintJobNormalizer {
+	return &PrintJobNormalizer{}
+}
+
+// ReadAndNormalize reads a print job. A job without ended_on is printing, an ended job
+// needs an outcome, succeeded jobs the filament_used and failed jobs a failure_reason. The
+// duration is computed from the times, which are kept to the second in UTC.
+func (*PrintJobNormalizer) ReadAndNormalize(w http.ResponseWriter, r *http.Request) (*entity.PrintJob, error) {
+	// Read body
+	b, err := ioutil.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		return nil, errors.New("failed to read request body")
+	}
+
+	// Unmarshal
+	var input *entity.PrintJob
+	err = json.Unmarshal(b, &input)
+	if err != nil || input == nil {
+		return nil, errors.New("failed to unmarshal request body")
+	}
+
+	// Validate
+	output := entity.NewPrintJob()
+	output.PrinterId = input.PrinterId
+	output.StartedOn = input.StartedOn.UTC().Truncate(time.Second)
+	output.FilamentUsed = input.FilamentUsed
+	output.FailureReason = strings.TrimSpace(input.FailureReason)
+	if output.PrinterId <= 0 {
+		return nil, errors.New("printer_id is required")
+	}
+	if input.StartedOn.IsZero() {
+		return nil, errors.New("started_on is required")
+	}
+	if output.FilamentUsed < 0 {
+		return nil, errors.New("filament_used must not be negative")
+	}
+	if len(output.FailureReason) > MaxFailureReasonLength {
+		return nil, errors.New("failure_reason must be at most 500 characters")
+	}
+
+	outcome := strings.ToLower(strings.TrimSpace(input.Outcome))
+	if input.EndedOn == nil {
+		if outcome != "" && outcome != entity.JobPrinting {
+			return nil, errors.New("ended_on is required once the job is " + outcome)
+		}
+		if output.FailureReason != "" {
+			return nil, errors.New("failure_reason is only for failed or cancelled jobs")
+		}
+		return output, nil
+	}
+
+	endedOn := input.EndedOn.UTC().Truncate(time.Second)
+	if endedOn.Before(output.StartedOn) {
+		return nil, errors.New("ended_on must not be before started_on")
+	}
+	switch outcome {
+	case entity.JobSucceeded:
+		if output.FailureReason != "" {
+			return nil, errors.New("failure_reason is only for failed or cancelled jobs")
+		}
+		if output.FilamentUsed == 0 {
+			return nil, errors.New("filament_used is required for succeeded jobs")
+		}
+	case entity.JobFailed:
+		if output.FailureReason == "" {
+			return nil, errors.New("failure_reason is required for failed jobs")
+		}
+	case entity.JobCancelled:
+	default:
+		return nil, errors.New("outcome of an ended job must be succeeded, failed or cancelled")
+	}
+	output.EndedOn = &endedOn
+	output.Outcome = outcome
+	output.Duration = int(endedOn.Sub(output.StartedOn).Seconds())
+
+	return output, nil
+}
@@ -0,0 +1,147 @@
+package normalizer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"threedee/entity"
+)
+
+// MaxImportSize limits the size of an import body
+const MaxImportSize = 8 << 20
+
+// Formats of an import
+const (
+	ImportCsv   = "csv"
+	ImportJsonl = "jsonl"
+)
+
+// ImportRow is one print request of an import. Line counts from 1, the header of a CSV
+// file included. Err is set instead of Model when the row is not a valid print request.
+type ImportRow struct {
+	Line  int
+	Model *entity.PrintRequest
+	Err   error
+}
+
+// ReadAndNormalizeImport reads the print requests of POST /print-requests/import. The
+// format is the "format" query parameter, or else told by the Content-Type: text/csv or
+// application/x-ndjson. A CSV file starts with a header of the JSON field names, in any
+// order, and unknown columns like those of an export are ignored. JSON Lines hold one
+// print request per line, blank lines are skipped.
+//
+// Rows are read like a multipart print request and item_name and requestor are required.
+// Imported requests wait for review like new ones, so a row can not set another status.
+// A body that can not be split into rows is an error, an invalid row is not.
+func (*PrintRequestNormalizer) ReadAndNormalizeImport(w http.ResponseWriter, r *http.Request) ([]*ImportRow, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxImportSize)
+	defer r.Body.Close()
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch mediaType {
+		case "text/csv":
+			format = ImportCsv
+		case "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+			format = ImportJsonl
+		}
+	}
+
+	switch format {
+	case ImportCsv:
+		return readImportCsv(r.Body)
+	case ImportJsonl:
+		return readImportJsonl(r.Body)
+	}
+	return nil, errors.New("format must be csv or jsonl")
+}
+
+func readImportCsv(body io.Reader) ([]*ImportRow, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("failed to read the csv header")
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+
+	result := make([]*ImportRow, 0)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return nil, errors.New("failed to read csv: " + err.Error())
+		}
+		model, err := normalizeFields(func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		})
+		result = append(result, importRow(line, model, err))
+	}
+}
+
+func readImportJsonl(body io.Reader) ([]*ImportRow, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), MaxImportSize)
+
+	result := make([]*ImportRow, 0)
+	for line := 1; scanner.Scan(); line++ {
+		b := bytes.TrimSpace(scanner.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+		model := entity.NewPrintRequest()
+		err := json.Unmarshal(b, model)
+		if err != nil {
+			result = append(result, importRow(line, nil, errors.New("failed to unmarshal line")))
+			continue
+		}
+		clearFile(model)
+		result = append(result, importRow(line, model, normalizeSettings(model)))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.New("failed to read jsonl: " + err.Error())
+	}
+	return result, nil
+}
+
+func importRow(line int, model *entity.PrintRequest, err error) *ImportRow {
+	if err == nil {
+		err = validateImport(model)
+	}
+	if err != nil {
+		return &ImportRow{Line: line, Err: err}
+	}
+	return &ImportRow{Line: line, Model: model}
+}
+
+func validateImport(model *entity.PrintRequest) error {
+	if strings.TrimSpace(model.ItemName) == "" {
+		return errors.New("item_name is required")
+	}
+	if strings.TrimSpace(model.Requestor) == "" {
+		return errors.New("requestor is required")
+	}
+	if model.EstimatedWeight < 0 || model.EstimatedFilamentLength < 0 || model.EstimatedDuration < 0 {
+		return errors.New("estimates must not be negative")
+	}
+	if model.Status != "" && model.Status != entity.StatusPendingReview {
+		return errors.New("status must be empty or pending_review, imported requests wait for review")
+	}
+	return nil
+}
@@ -4,18 +4,47 @@ import (
 	"encoding/json"
 	"errors"
 	"io/ioutil"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
 	"threedee/entity"
 )
 
+// MaxUploadSize limits the size of a multipart print request including its file
+const MaxUploadSize = 64 << 20
+
 type PrintRequestNormalizer struct {
 }
 
+// Upload is the print file sent along a multipart print request
+type Upload struct {
+	Filename string
+	Data     []byte
+}
+
 func NewPrintRequestNormalizer() *PrintRequestNormalizer {
 	return &PrintRequestNormalizer{}
 }
 
+// ReadAndNormalize reads a print request, print settings that are left out get their
+// defaults
 func (*PrintRequestNormalizer) ReadAndNormalize(w http.ResponseWriter, r *http.Request) (*entity.PrintRequest, error) {
+	output, err := readPrintRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate
+	err = normalizeSettings(output)
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+func readPrintRequest(r *http.Request) (*entity.PrintRequest, error) {
 	// Read body
 	b, err := ioutil.ReadAll(r.Body)
 	defer r.Body.Close()
@@ -24,11 +53,296 @@ func (*PrintRequestNormalizer) ReadAndNormalize(w http.ResponseWriter, r *http.R
 	}
 
 	// Unmarshal
-	var output *entity.PrintRequest
-	err = json.Unmarshal(b, &output)
+	output := entity.NewPrintRequest()
+	err = json.Unmarshal(b, output)
 	if err != nil {
 		return nil, errors.New("failed to unmarshal request body")
 	}
+	clearFile(output)
+
+	return output, nil
+}
+
+// clearFile drops the fields that only an uploaded file sets, see ReadAndNormalizeUpload
+func clearFile(model *entity.PrintRequest) {
+	model.FileKey = ""
+	model.FileHash = ""
+	model.DuplicateOf = 0
+}
+
+// Limits of the print settings
+const (
+	MinLayerHeight = 0.05 // mm
+	MinNozzleSize  = 0.1  // mm
+	MaxNozzleSize  = 1.2  // mm
+	MaxColorLength = 30
+	MaxQuantity    = 100
+)
+
+// normalizeSettings lower cases the material, trims the color and checks the print settings
+// of model. An empty material and a zero layer height, nozzle size or quantity get their
+// defaults, and layers can be at most 80% of the nozzle size.
+func normalizeSettings(model *entity.PrintRequest) error {
+	model.Material = strings.ToLower(strings.TrimSpace(model.Material))
+	model.Color = strings.TrimSpace(model.Color)
+	if model.Material == "" {
+		model.Material = entity.DefaultMaterial
+	}
+	if model.LayerHeight == 0 {
+		model.LayerHeight = entity.DefaultLayerHeight
+	}
+	if model.NozzleSize == 0 {
+		model.NozzleSize = entity.DefaultNozzleSize
+	}
+	if model.Quantity == 0 {
+		model.Quantity = 1
+	}
+	if !isKnownMaterial(model.Material) {
+		return errUnknownMaterial
+	}
+	if len(model.Color) > MaxColorLength {
+		return errors.New("color must be at most " + strconv.Itoa(MaxColorLength) + " characters")
+	}
+	if model.NozzleSize < MinNozzleSize || model.NozzleSize > MaxNozzleSize {
+		return errors.New("nozzle_size must be from 0.1 to 1.2 mm")
+	}
+	if model.LayerHeight < MinLayerHeight || model.LayerHeight > model.NozzleSize*0.8 {
+		return errors.New("layer_height must be from 0.05 mm to 80% of the nozzle_size")
+	}
+	if model.Infill < 0 || model.Infill > 100 {
+		return errors.New("infill must be from 0 to 100 percent")
+	}
+	if model.Quantity < 1 || model.Quantity > MaxQuantity {
+		return errors.New("quantity must be from 1 to " + strconv.Itoa(MaxQuantity))
+	}
+	return nil
+}
+
+// errUnknownMaterial is returned for a material that is not one of entity.Materials
+var errUnknownMaterial = errors.New("material must be one of pla, petg, abs, asa, tpu or nylon")
+
+func isKnownMaterial(material string) bool {
+	for _, m := range entity.Materials {
+		if m == material {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadAndNormalizeStatus reads a status change, the status must be one of entity.Statuses
+func (*PrintRequestNormalizer) ReadAndNormalizeStatus(w http.ResponseWriter, r *http.Request) (*entity.PrintRequest, error) {
+	output, err := readPrintRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	if output == nil || !isKnownStatus(output.Status) {
+		return nil, errUnknownStatus
+	}
+	return output, nil
+}
+
+// MaxBatchSize limits the number of requests of a batch status change
+const MaxBatchSize = 100
+
+// ReadAndNormalizeBatchStatus reads a batch status change. The ids are deduplicated,
+// keeping their order, and the status must be one of entity.Statuses.
+func (*PrintRequestNormalizer) ReadAndNormalizeBatchStatus(w http.ResponseWriter, r *http.Request) (*entity.BatchStatusChange, error) {
+	b, err := ioutil.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		return nil, errors.New("failed to read request body")
+	}
+
+	var output *entity.BatchStatusChange
+	err = json.Unmarshal(b, &output)
+	if err != nil || output == nil {
+		return nil, errors.New("failed to unmarshal request body")
+	}
 
+	if !isKnownStatus(output.Status) {
+		return nil, errUnknownStatus
+	}
+	if len(output.Ids) == 0 || len(output.Ids) > MaxBatchSize {
+		return nil, errors.New("ids must have from 1 to " + strconv.Itoa(MaxBatchSize) + " ids")
+	}
+	ids := make([]int, 0, len(output.Ids))
+	seen := make(map[int]bool)
+	for _, id := range output.Ids {
+		if id < 1 {
+			return nil, errors.New("ids must be positive")
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	output.Ids = ids
+	if output.Atomic == nil {
+		atomic := true
+		output.Atomic = &atomic
+	}
+
+	return output, nil
+}
+
+// ReadAndNormalizeFilter reads the "requestor", "status", "material", "color" and
+// "supports" query parameters of a list
+func (*PrintRequestNormalizer) ReadAndNormalizeFilter(r *http.Request) (*entity.PrintRequestFilter, error) {
+	query := r.URL.Query()
+	output := &entity.PrintRequestFilter{
+		Requestor: query.Get("requestor"),
+		Status:    query.Get("status"),
+		Material:  strings.ToLower(query.Get("material")),
+		Color:     query.Get("color"),
+	}
+	if output.Status != "" && !isKnownStatus(output.Status) {
+		return nil, errUnknownStatus
+	}
+	if output.Material != "" && !isKnownMaterial(output.Material) {
+		return nil, errUnknownMaterial
+	}
+	if v := query.Get("supports"); v != "" {
+		supports, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, errors.New("supports must be true or false")
+		}
+		output.Supports = &supports
+	}
+	return output, nil
+}
+
+// errUnknownStatus is returned for a status that is not one of entity.Statuses
+var errUnknownStatus = errors.New("status must be one of pending_review, changes_requested, received, processed, approved, rejected, finished or failed")
+
+func isKnownStatus(status string) bool {
+	for _, s := range entity.Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsMultipart reports whether the request body is a multipart/form-data upload
+func (*PrintRequestNormalizer) IsMultipart(r *http.Request) bool {
+	return isMultipart(r)
+}
+
+func isMultipart(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "multipart/form-data"
+}
+
+// ReadAndNormalizeUpload reads a multipart/form-data print request. The form fields use
+// the same names as the JSON body and the print file is sent in the "file" field.
+func (*PrintRequestNormalizer) ReadAndNormalizeUpload(w http.ResponseWriter, r *http.Request) (*entity.PrintRequest, *Upload, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxUploadSize)
+	defer r.Body.Close()
+
+	err := r.ParseMultipartForm(MaxUploadSize)
+	if err != nil {
+		return nil, nil, errors.New("failed to read multipart request body")
+	}
+
+	output, err := normalizeFields(r.FormValue)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return nil, nil, errors.New("file is required")
+	}
+	defer file.Close()
+
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, nil, errors.New("failed to read uploaded file")
+	}
+
+	return output, &Upload{Filename: header.Filename, Data: data}, nil
+}
+
+// normalizeFields reads a print request from text fields named like the JSON body, get
+// returns "" for a missing field
+func normalizeFields(get func(name string) string) (*entity.PrintRequest, error) {
+	output := entity.NewPrintRequest()
+	output.ItemName = get("item_name")
+	output.FileUrl = get("file_url")
+	output.Requestor = get("requestor")
+	output.Notes = get("notes")
+	output.Status = get("status")
+	output.Material = get("material")
+	output.Color = get("color")
+
+	if v := get("estimated_weight"); v != "" {
+		f, err := strconv.ParseFloat(v, 32)
+		if err != nil {
+			return nil, errors.New("estimated_weight is not a number")
+		}
+		output.EstimatedWeight = float32(f)
+	}
+	if v := get("estimated_filament_length"); v != "" {
+		f, err := strconv.ParseFloat(v, 32)
+		if err != nil {
+			return nil, errors.New("estimated_filament_length is not a number")
+		}
+		output.EstimatedFilamentLength = float32(f)
+	}
+	if v := get("estimated_duration"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, errors.New("estimated_duration is not a number")
+		}
+		output.EstimatedDuration = i
+	}
+	if v := get("layer_height"); v != "" {
+		f, err := strconv.ParseFloat(v, 32)
+		if err != nil {
+			return nil, errors.New("layer_height is not a number")
+		}
+		output.LayerHeight = float32(f)
+	}
+	if v := get("infill"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, errors.New("infill is not a number")
+		}
+		output.Infill = i
+	}
+	if v := get("supports"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, errors.New("supports must be true or false")
+		}
+		output.Supports = b
+	}
+	if v := get("nozzle_size"); v != "" {
+		f, err := strconv.ParseFloat(v, 32)
+		if err != nil {
+			return nil, errors.New("nozzle_size is not a number")
+		}
+		output.NozzleSize = float32(f)
+	}
+	if v := get("quantity"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, errors.New("quantity is not a number")
+		}
+		output.Quantity = i
+	}
+	if v := get("project_id"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, errors.New("project_id is not a number")
+		}
+		output.ProjectId = i
+	}
+
+	err := normalizeSettings(output)
+	if err != nil {
+		return nil, err
+	}
 	return output, nil
 }
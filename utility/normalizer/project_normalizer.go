@@ -0,0 +1,52 @@
+package normalizer
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"threedee/entity"
+)
+
+// MaxProjectNameLength limits the name and the owner of a project
+const MaxProjectNameLength = 100
+
+type ProjectNormalizer struct {
+}
+
+func NewProjectNormalizer() *ProjectNormalizer {
+	return &ProjectNormalizer{}
+}
+
+// ReadAndNormalize reads a project, the totals and status are computed from the parts so
+// they are ignored
+func (*ProjectNormalizer) ReadAndNormalize(w http.ResponseWriter, r *http.Request) (*entity.Project, error) {
+	// Read body
+	b, err := ioutil.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		return nil, errors.New("failed to read request body")
+	}
+
+	// Unmarshal
+	var input *entity.Project
+	err = json.Unmarshal(b, &input)
+	if err != nil || input == nil {
+		return nil, errors.New("failed to unmarshal request body")
+	}
+
+	// Validate
+	output := entity.NewProject()
+	output.Name = strings.TrimSpace(input.Name)
+	output.Owner = strings.TrimSpace(input.Owner)
+	output.Description = strings.TrimSpace(input.Description)
+	if output.Name == "" || output.Owner == "" {
+		return nil, errors.New("name and owner are required")
+	}
+	if len(output.Name) > MaxProjectNameLength || len(output.Owner) > MaxProjectNameLength {
+		return nil, errors.New("name and owner must be at most 100 characters")
+	}
+
+	return output, nil
+}
@@ -0,0 +1,39 @@
+package normalizer
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"threedee/entity"
+)
+
+type QuotaNormalizer struct {
+}
+
+func NewQuotaNormalizer() *QuotaNormalizer {
+	return &QuotaNormalizer{}
+}
+
+func (*QuotaNormalizer) ReadAndNormalize(w http.ResponseWriter, r *http.Request) (*entity.Quota, error) {
+	// Read body
+	b, err := ioutil.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		return nil, errors.New("failed to read request body")
+	}
+
+	// Unmarshal
+	var output *entity.Quota
+	err = json.Unmarshal(b, &output)
+	if err != nil || output == nil {
+		return nil, errors.New("failed to unmarshal request body")
+	}
+
+	// Validate, zero is unlimited
+	if output.MaxActive < 0 || output.MaxGramsPerMonth < 0 || output.MaxHoursPerWeek < 0 {
+		return nil, errors.New("limits must not be negative")
+	}
+
+	return output, nil
+}
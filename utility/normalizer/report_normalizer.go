@@ -0,0 +1,95 @@
+package normalizer
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"threedee/entity"
+	"time"
+)
+
+// DefaultReportDays is the range of a report without "from"
+const DefaultReportDays = 30
+
+type ReportNormalizer struct {
+}
+
+func NewReportNormalizer() *ReportNormalizer {
+	return &ReportNormalizer{}
+}
+
+// ReadAndNormalizeUsage reads the query of GET /reports/usage. "from" and "to" are UTC
+// dates, both included, defaulting to the last DefaultReportDays days. "interval" is
+// day, week or month (default) and "group_by" a comma separated list of requestor and
+// status.
+func (*ReportNormalizer) ReadAndNormalizeUsage(r *http.Request) (*entity.UsageQuery, error) {
+	query := r.URL.Query()
+	from, to, err := readRange(query)
+	if err != nil {
+		return nil, err
+	}
+	output := &entity.UsageQuery{
+		From:     from,
+		To:       to,
+		Interval: entity.IntervalMonth,
+	}
+
+	if v := query.Get("interval"); v != "" {
+		if v != entity.IntervalDay && v != entity.IntervalWeek && v != entity.IntervalMonth {
+			return nil, errors.New("interval must be day, week or month")
+		}
+		output.Interval = v
+	}
+
+	if v := query.Get("group_by"); v != "" {
+		for _, group := range strings.Split(v, ",") {
+			switch strings.TrimSpace(group) {
+			case "requestor":
+				output.ByRequestor = true
+			case "status":
+				output.ByStatus = true
+			default:
+				return nil, errors.New("group_by must be requestor, status or both")
+			}
+		}
+	}
+
+	return output, nil
+}
+
+// ReadAndNormalizeEstimates reads the query of GET /reports/estimates, "from" and "to" like
+// ReadAndNormalizeUsage
+func (*ReportNormalizer) ReadAndNormalizeEstimates(r *http.Request) (*entity.EstimateQuery, error) {
+	from, to, err := readRange(r.URL.Query())
+	if err != nil {
+		return nil, err
+	}
+	return &entity.EstimateQuery{From: from, To: to}, nil
+}
+
+// readRange reads the UTC dates "from" and "to", both included, and returns them as the
+// range [from, to + 1 day)
+func readRange(query url.Values) (time.Time, time.Time, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	from, to := today.AddDate(0, 0, 1-DefaultReportDays), today.AddDate(0, 0, 1)
+
+	if v := query.Get("from"); v != "" {
+		date, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return from, to, errors.New("from must be a date like 2021-01-31")
+		}
+		from = date
+	}
+	if v := query.Get("to"); v != "" {
+		date, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return from, to, errors.New("to must be a date like 2021-01-31")
+		}
+		to = date.AddDate(0, 0, 1)
+	}
+	if !from.Before(to) {
+		return from, to, errors.New("from must not be after to")
+	}
+	return from, to, nil
+}
@@ -0,0 +1,50 @@
+package normalizer
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"threedee/entity"
+)
+
+type ReviewNormalizer struct {
+}
+
+func NewReviewNormalizer() *ReviewNormalizer {
+	return &ReviewNormalizer{}
+}
+
+// ReadAndNormalize reads a review action, rejecting and requesting changes need a comment
+// telling the requestor why
+func (*ReviewNormalizer) ReadAndNormalize(w http.ResponseWriter, r *http.Request) (*entity.ReviewEntry, error) {
+	// Read body
+	b, err := ioutil.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		return nil, errors.New("failed to read request body")
+	}
+
+	// Unmarshal
+	var output *entity.ReviewEntry
+	err = json.Unmarshal(b, &output)
+	if err != nil || output == nil {
+		return nil, errors.New("failed to unmarshal request body")
+	}
+
+	// Validate
+	output.Author = strings.TrimSpace(output.Author)
+	output.Comment = strings.TrimSpace(output.Comment)
+	if output.Author == "" {
+		return nil, errors.New("author is required")
+	}
+	if _, ok := entity.ReviewStatuses[output.Action]; !ok {
+		return nil, errors.New("action must be one of approve, reject, request_changes or resubmit")
+	}
+	if output.Comment == "" && (output.Action == entity.ReviewReject || output.Action == entity.ReviewRequestChanges) {
+		return nil, errors.New("comment is required to " + strings.Replace(output.Action, "_", " ", -1))
+	}
+
+	return output, nil
+}
@@ -0,0 +1,61 @@
+package normalizer
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"threedee/entity"
+)
+
+type WebhookNormalizer struct {
+}
+
+func NewWebhookNormalizer() *WebhookNormalizer {
+	return &WebhookNormalizer{}
+}
+
+func (*WebhookNormalizer) ReadAndNormalize(w http.ResponseWriter, r *http.Request) (*entity.WebhookSubscription, error) {
+	// Read body
+	b, err := ioutil.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		return nil, errors.New("failed to read request body")
+	}
+
+	// Unmarshal
+	var output *entity.WebhookSubscription
+	err = json.Unmarshal(b, &output)
+	if err != nil || output == nil {
+		return nil, errors.New("failed to unmarshal request body")
+	}
+
+	// Validate
+	u, err := url.Parse(output.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New("url must be an absolute http or https url")
+	}
+	if output.Secret == "" {
+		return nil, errors.New("secret is required")
+	}
+	if len(output.Events) == 0 {
+		return nil, errors.New("events must not be empty")
+	}
+	for _, event := range output.Events {
+		if !isKnownEvent(event) {
+			return nil, errors.New("unknown event " + event)
+		}
+	}
+
+	return output, nil
+}
+
+func isKnownEvent(event string) bool {
+	for _, e := range entity.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package quota
+
+import (
+	"strconv"
+	"threedee/entity"
+	"time"
+)
+
+/*
+ * Quota decides whether a requestor may add a print request. Months and weeks are
+ * calendar periods in UTC, weeks start on Monday like the usage reports, so a requestor
+ * gets a fresh allowance at the start of each one.
+ */
+
+// ExceededError tells which limit a new request would exceed. RetryAfter is the time
+// until the limit resets, zero for the limit of active requests, which only frees up when
+// a request is done.
+type ExceededError struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *ExceededError) Error() string {
+	return e.Message
+}
+
+// MonthStart returns the first instant of the UTC month of now
+func MonthStart(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// WeekStart returns the first instant of the UTC week of now, a Monday
+func WeekStart(now time.Time) time.Time {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// Check returns an *ExceededError when adding model to usage exceeds limit
+func Check(limit *entity.Quota, usage *entity.QuotaUsage, model *entity.PrintRequest, now time.Time) error {
+	if limit.MaxActive > 0 && usage.Active+1 > limit.MaxActive {
+		return &ExceededError{
+			Message: "quota exceeded: " + strconv.Itoa(usage.Active) + " of " + strconv.Itoa(limit.MaxActive) + " active requests",
+		}
+	}
+
+	grams := usage.GramsThisMonth + float64(model.EstimatedWeight)
+	if limit.MaxGramsPerMonth > 0 && grams > limit.MaxGramsPerMonth {
+		return &ExceededError{
+			Message:    "quota exceeded: " + format(grams) + " of " + format(limit.MaxGramsPerMonth) + " grams this month",
+			RetryAfter: MonthStart(now).AddDate(0, 1, 0).Sub(now),
+		}
+	}
+
+	hours := usage.HoursThisWeek + float64(model.EstimatedDuration)/3600
+	if limit.MaxHoursPerWeek > 0 && hours > limit.MaxHoursPerWeek {
+		return &ExceededError{
+			Message:    "quota exceeded: " + format(hours) + " of " + format(limit.MaxHoursPerWeek) + " print hours this week",
+			RetryAfter: WeekStart(now).AddDate(0, 0, 7).Sub(now),
+		}
+	}
+
+	return nil
+}
+
+func format(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,86 @@
+package quota_test
+
+import (
+	"testing"
+	"threedee/entity"
+	"threedee/utility/quota"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type QuotaTestSuite struct {
+	suite.Suite
+}
+
+func (suite *QuotaTestSuite) TestPeriodStarts() {
+	// a Wednesday
+	now := time.Date(2021, 3, 17, 15, 4, 5, 0, time.FixedZone("WIB", 7*3600))
+
+	suite.Equal(time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC), quota.MonthStart(now))
+	suite.Equal(time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC), quota.WeekStart(now))
+
+	sunday := time.Date(2021, 3, 21, 23, 0, 0, 0, time.UTC)
+	suite.Equal(time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC), quota.WeekStart(sunday))
+}
+
+func (suite *QuotaTestSuite) TestCheck() {
+	now := time.Date(2021, 3, 31, 12, 0, 0, 0, time.UTC)
+	limit := &entity.Quota{MaxActive: 3, MaxGramsPerMonth: 500, MaxHoursPerWeek: 10}
+	model := &entity.PrintRequest{EstimatedWeight: 100, EstimatedDuration: 2 * 3600}
+
+	var testCase = []struct {
+		testcase   string
+		limit      *entity.Quota
+		usage      *entity.QuotaUsage
+		message    string
+		retryAfter time.Duration
+	}{
+		{
+			testcase: "within",
+			limit:    limit,
+			usage:    &entity.QuotaUsage{Active: 2, GramsThisMonth: 400, HoursThisWeek: 8},
+		},
+		{
+			testcase: "unlimited",
+			limit:    &entity.Quota{},
+			usage:    &entity.QuotaUsage{Active: 100, GramsThisMonth: 100000, HoursThisWeek: 1000},
+		},
+		{
+			testcase: "active",
+			limit:    limit,
+			usage:    &entity.QuotaUsage{Active: 3},
+			message:  "quota exceeded: 3 of 3 active requests",
+		},
+		{
+			testcase:   "grams",
+			limit:      limit,
+			usage:      &entity.QuotaUsage{GramsThisMonth: 450},
+			message:    "quota exceeded: 550 of 500 grams this month",
+			retryAfter: 12 * time.Hour,
+		},
+		{
+			testcase:   "hours",
+			limit:      limit,
+			usage:      &entity.QuotaUsage{HoursThisWeek: 9},
+			message:    "quota exceeded: 11 of 10 print hours this week",
+			retryAfter: 4*24*time.Hour + 12*time.Hour,
+		},
+	}
+	for _, tc := range testCase {
+		err := quota.Check(tc.limit, tc.usage, model, now)
+
+		if tc.message == "" {
+			suite.Nil(err, tc.testcase)
+			continue
+		}
+		exceeded, ok := err.(*quota.ExceededError)
+		suite.Require().True(ok, tc.testcase)
+		suite.Equal(tc.message, exceeded.Message, tc.testcase)
+		suite.Equal(tc.retryAfter, exceeded.RetryAfter, tc.testcase)
+	}
+}
+
+func TestQuotaTestSuite(t *testing.T) {
+	suite.Run(t, new(QuotaTestSuite))
+}
@@ -3,6 +3,8 @@ package response
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 type Meta struct {
@@ -57,3 +59,50 @@ func WriteBadRequestError(w http.ResponseWriter, err error) error {
 	Respond(w, meta, http.StatusBadRequest)
 	return err
 }
+
+func WriteForbiddenError(w http.ResponseWriter, err error) error {
+	meta := Meta{
+		Message:    err.Error(),
+		HttpStatus: http.StatusForbidden,
+	}
+
+	Respond(w, meta, http.StatusForbidden)
+	return err
+}
+
+func WriteRequestEntityTooLargeError(w http.ResponseWriter, err error) error {
+	meta := Meta{
+		Message:    err.Error(),
+		HttpStatus: http.StatusRequestEntityTooLarge,
+	}
+
+	Respond(w, meta, http.StatusRequestEntityTooLarge)
+	return err
+}
+
+// WriteConflictError also returns data, e.g. a reference to the conflicting record
+func WriteConflictError(w http.ResponseWriter, data interface{}, err error) error {
+	meta := Meta{
+		Message:    err.Error(),
+		Data:       data,
+		HttpStatus: http.StatusConflict,
+	}
+
+	Respond(w, meta, http.StatusConflict)
+	return err
+}
+
+// WriteTooManyRequestsError tells the client in Retry-After when to try again, rounded up
+// to whole seconds. The header is left out when retryAfter is not known.
+func WriteTooManyRequestsError(w http.ResponseWriter, retryAfter time.Duration, err error) error {
+	meta := Meta{
+		Message:    err.Error(),
+		HttpStatus: http.StatusTooManyRequests,
+	}
+
+	if retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.FormatInt(int64((retryAfter+time.Second-1)/time.Second), 10))
+	}
+	Respond(w, meta, http.StatusTooManyRequests)
+	return err
+}
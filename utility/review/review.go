@@ -0,0 +1,77 @@
+package review
+
+import (
+	"threedee/entity"
+)
+
+/*
+ * Review decides who reviews a new print request and who may take a review action.
+ * Reviewers are given the request of the one with the fewest requests pending review,
+ * ties go to the first in the list, and nobody reviews their own requests unless they
+ * are the only reviewer.
+ */
+
+// ForbiddenError is returned when the author may not take the action on a request
+type ForbiddenError struct {
+	Message string
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.Message
+}
+
+// ConflictError is returned when the request is not in the status the action starts from
+type ConflictError struct {
+	Message string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+// Assign picks the reviewer of a new request of requestor, pending are the requests
+// waiting for review. It returns "" without reviewers, anyone may review then.
+func Assign(reviewers []string, requestor string, pending []*entity.PrintRequest) string {
+	load := make(map[string]int)
+	for _, item := range pending {
+		load[item.Reviewer]++
+	}
+
+	picked := ""
+	for _, reviewer := range reviewers {
+		if reviewer == requestor && len(reviewers) > 1 {
+			continue
+		}
+		if picked == "" || load[reviewer] < load[picked] {
+			picked = reviewer
+		}
+	}
+	return picked
+}
+
+// Check returns a *ConflictError when entry can not be taken on model in its status and
+// a *ForbiddenError when its author may not take it. Decisions belong to the assigned
+// reviewer, or anyone but the requestor when none is assigned, and resubmitting to the
+// requestor.
+func Check(model *entity.PrintRequest, entry *entity.ReviewEntry) error {
+	if entry.Action == entity.ReviewResubmit {
+		if model.Status != entity.StatusChangesRequested {
+			return &ConflictError{"only a request with changes requested can be resubmitted, it is " + model.Status}
+		}
+		if entry.Author != model.Requestor {
+			return &ForbiddenError{"only the requestor can resubmit the request"}
+		}
+		return nil
+	}
+
+	if model.Status != entity.StatusPendingReview {
+		return &ConflictError{"only a request pending review can be reviewed, it is " + model.Status}
+	}
+	if entry.Author == model.Requestor && model.Reviewer != entry.Author {
+		return &ForbiddenError{"requestors can not review their own request"}
+	}
+	if model.Reviewer != "" && entry.Author != model.Reviewer {
+		return &ForbiddenError{"the request is assigned to " + model.Reviewer}
+	}
+	return nil
+}
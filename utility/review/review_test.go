@@ -0,0 +1,67 @@
+package review_test
+
+import (
+	"testing"
+	"threedee/entity"
+	"threedee/utility/review"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ReviewTestSuite struct {
+	suite.Suite
+}
+
+func (suite *ReviewTestSuite) TestAssign() {
+	pending := []*entity.PrintRequest{
+		{Reviewer: "sari"},
+		{Reviewer: "sari"},
+		{Reviewer: "tono"},
+	}
+
+	suite.Equal("", review.Assign(nil, "andi", pending))
+	suite.Equal("tono", review.Assign([]string{"sari", "tono"}, "andi", pending))
+	suite.Equal("udin", review.Assign([]string{"sari", "tono", "udin"}, "andi", pending))
+	suite.Equal("sari", review.Assign([]string{"sari", "tono"}, "andi", nil))
+	suite.Equal("sari", review.Assign([]string{"tono", "sari"}, "tono", nil))
+	suite.Equal("tono", review.Assign([]string{"tono"}, "tono", nil))
+}
+
+func (suite *ReviewTestSuite) TestCheck() {
+	pending := &entity.PrintRequest{Requestor: "andi", Reviewer: "sari", Status: entity.StatusPendingReview}
+	unassigned := &entity.PrintRequest{Requestor: "andi", Status: entity.StatusPendingReview}
+	changes := &entity.PrintRequest{Requestor: "andi", Reviewer: "sari", Status: entity.StatusChangesRequested}
+
+	var testCase = []struct {
+		testcase  string
+		model     *entity.PrintRequest
+		author    string
+		action    string
+		forbidden bool
+		conflict  bool
+	}{
+		{testcase: "reviewer approves", model: pending, author: "sari", action: entity.ReviewApprove},
+		{testcase: "anyone reviews unassigned", model: unassigned, author: "tono", action: entity.ReviewReject},
+		{testcase: "other reviewer", model: pending, author: "tono", action: entity.ReviewApprove, forbidden: true},
+		{testcase: "requestor approves", model: unassigned, author: "andi", action: entity.ReviewApprove, forbidden: true},
+		{testcase: "not pending", model: changes, author: "sari", action: entity.ReviewApprove, conflict: true},
+		{testcase: "requestor resubmits", model: changes, author: "andi", action: entity.ReviewResubmit},
+		{testcase: "reviewer resubmits", model: changes, author: "sari", action: entity.ReviewResubmit, forbidden: true},
+		{testcase: "resubmit pending", model: pending, author: "andi", action: entity.ReviewResubmit, conflict: true},
+	}
+	for _, tc := range testCase {
+		err := review.Check(tc.model, &entity.ReviewEntry{Author: tc.author, Action: tc.action})
+
+		_, forbidden := err.(*review.ForbiddenError)
+		_, conflict := err.(*review.ConflictError)
+		suite.Equal(tc.forbidden, forbidden, tc.testcase)
+		suite.Equal(tc.conflict, conflict, tc.testcase)
+		if !tc.forbidden && !tc.conflict {
+			suite.Nil(err, tc.testcase)
+		}
+	}
+}
+
+func TestReviewTestSuite(t *testing.T) {
+	suite.Run(t, new(ReviewTestSuite))
+}
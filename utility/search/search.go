@@ -0,0 +1,114 @@
+package search
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+/*
+ * Search splits a full-text query into words the same way for every backend. A word is a
+ * run of letters and digits, anything else only separates words, so the terms are safe to
+ * put into a Postgres tsquery or an SQLite FTS5 query. Every term is matched as a prefix
+ * and all terms have to match.
+ */
+
+// MaxTerms keeps a query from turning into a huge tsquery
+const MaxTerms = 10
+
+// Highlight markers around the matched words of a search result
+const (
+	StartSel = "<mark>"
+	StopSel  = "</mark>"
+)
+
+// Markers the databases put around the matched words instead of StartSel and StopSel, so
+// the text can be HTML escaped before they are replaced, see Marked. They are control
+// characters, which no print request field is expected to hold.
+const (
+	StartMark = "\x02"
+	StopMark  = "\x03"
+)
+
+var marks = strings.NewReplacer(StartMark, StartSel, StopMark, StopSel)
+
+var word = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// Terms returns the lowercase words of q, without duplicates
+func Terms(q string) []string {
+	result := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, w := range word.FindAllString(strings.ToLower(q), -1) {
+		if seen[w] {
+			continue
+		}
+		seen[w] = true
+		result = append(result, w)
+		if len(result) == MaxTerms {
+			break
+		}
+	}
+	return result
+}
+
+// TsQuery builds a Postgres tsquery matching every term as a prefix, e.g. "cup:* & hold:*"
+func TsQuery(terms []string) string {
+	parts := make([]string, 0, len(terms))
+	for _, t := range terms {
+		parts = append(parts, t+":*")
+	}
+	return strings.Join(parts, " & ")
+}
+
+// FtsQuery builds an SQLite FTS5 query matching every term as a prefix, e.g. `"cup"* "hold"*`
+func FtsQuery(terms []string) string {
+	parts := make([]string, 0, len(terms))
+	for _, t := range terms {
+		parts = append(parts, `"`+t+`"*`)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Matches reports whether a word of text starts with term
+func Matches(text string, term string) bool {
+	for _, w := range word.FindAllString(strings.ToLower(text), -1) {
+		if strings.HasPrefix(w, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// Highlight HTML escapes text and wraps the words that start with one of the terms in
+// StartSel and StopSel
+func Highlight(text string, terms []string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range word.FindAllStringIndex(text, -1) {
+		b.WriteString(html.EscapeString(text[last:loc[0]]))
+		w := text[loc[0]:loc[1]]
+		if startsWithAny(strings.ToLower(w), terms) {
+			b.WriteString(StartSel + html.EscapeString(w) + StopSel)
+		} else {
+			b.WriteString(html.EscapeString(w))
+		}
+		last = loc[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
+
+// Marked HTML escapes text a database highlighted with StartMark and StopMark and then
+// turns the marks into StartSel and StopSel
+func Marked(text string) string {
+	return marks.Replace(html.EscapeString(text))
+}
+
+func startsWithAny(w string, terms []string) bool {
+	for _, t := range terms {
+		if strings.HasPrefix(w, t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package search_test
+
+import (
+	"testing"
+	"threedee/utility/search"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SearchTestSuite struct {
+	suite.Suite
+}
+
+func (suite *SearchTestSuite) TestTerms() {
+	var testCase = []struct {
+		testcase string
+		q        string
+		terms    []string
+	}{
+		{
+			testcase: "words",
+			q:        "Cup  Holder",
+			terms:    []string{"cup", "holder"},
+		},
+		{
+			testcase: "query syntax is dropped",
+			q:        `cup:* & !holder "OR" (v2)`,
+			terms:    []string{"cup", "holder", "or", "v2"},
+		},
+		{
+			testcase: "duplicates",
+			q:        "cup CUP cup",
+			terms:    []string{"cup"},
+		},
+		{
+			testcase: "no words",
+			q:        " &*! ",
+			terms:    []string{},
+		},
+	}
+	for _, tc := range testCase {
+		suite.Equal(tc.terms, search.Terms(tc.q), tc.testcase)
+	}
+}
+
+func (suite *SearchTestSuite) TestQueries() {
+	terms := []string{"cup", "hold"}
+
+	suite.Equal("cup:* & hold:*", search.TsQuery(terms))
+	suite.Equal(`"cup"* "hold"*`, search.FtsQuery(terms))
+}
+
+func (suite *SearchTestSuite) TestHighlight() {
+	terms := []string{"cup", "hold"}
+
+	suite.True(search.Matches("Cup Holder v2", "hold"))
+	suite.False(search.Matches("Cup Holder v2", "older"))
+	suite.Equal("<mark>Cup</mark> <mark>Holder</mark> v2, upholder", search.Highlight("Cup Holder v2, upholder", terms))
+	suite.Equal("&lt;script&gt;<mark>cup</mark>&lt;/script&gt; &amp; <mark>holder</mark>", search.Highlight("<script>cup</script> & holder", terms))
+}
+
+func (suite *SearchTestSuite) TestMarked() {
+	marked := "&lt;b&gt;" + search.StartMark + "Cup" + search.StopMark + " <mark>Holder</mark>"
+
+	suite.Equal("&amp;lt;b&amp;gt;<mark>Cup</mark> &lt;mark&gt;Holder&lt;/mark&gt;", search.Marked(marked))
+}
+
+func TestSearchTestSuite(t *testing.T) {
+	suite.Run(t, new(SearchTestSuite))
+}
@@ -0,0 +1,249 @@
+package threemf
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"threedee/utility/mesh"
+)
+
+/*
+ * 3MF Reader
+ *
+ * A 3MF file is a zip package. The package relationships (_rels/.rels) point to the model
+ * XML, which lists the objects (meshes or components made of other objects) and a build
+ * section that places objects on the plate with an optional transform.
+ *
+ * Parse returns one mesh per build item, already transformed and converted to millimetres,
+ * so the result describes the plate exactly as the designer exported it.
+ */
+
+const modelRelationshipType = "http://schemas.microsoft.com/3dmanufacturing/2013/01/3dmodel"
+
+// maxComponentDepth guards against components that reference each other in a loop
+const maxComponentDepth = 16
+
+var unitScale = map[string]float64{
+	"micron":     0.001,
+	"millimeter": 1,
+	"centimeter": 10,
+	"inch":       25.4,
+	"foot":       304.8,
+	"meter":      1000,
+}
+
+type relationships struct {
+	Relationships []struct {
+		Target string `xml:"Target,attr"`
+		Type   string `xml:"Type,attr"`
+	} `xml:"Relationship"`
+}
+
+type model struct {
+	Unit    string   `xml:"unit,attr"`
+	Objects []object `xml:"resources>object"`
+	Items   []struct {
+		ObjectId  int    `xml:"objectid,attr"`
+		Transform string `xml:"transform,attr"`
+	} `xml:"build>item"`
+}
+
+type object struct {
+	Id       int    `xml:"id,attr"`
+	Name     string `xml:"name,attr"`
+	Vertices []struct {
+		X float64 `xml:"x,attr"`
+		Y float64 `xml:"y,attr"`
+		Z float64 `xml:"z,attr"`
+	} `xml:"mesh>vertices>vertex"`
+	Triangles []struct {
+		V1 int `xml:"v1,attr"`
+		V2 int `xml:"v2,attr"`
+		V3 int `xml:"v3,attr"`
+	} `xml:"mesh>triangles>triangle"`
+	Components []struct {
+		ObjectId  int    `xml:"objectid,attr"`
+		Transform string `xml:"transform,attr"`
+	} `xml:"components>component"`
+}
+
+// transform is the 3x4 affine matrix of the 3MF spec, stored row by row as m00..m32
+type transform [12]float64
+
+var identity = transform{1, 0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0}
+
+func Parse(data []byte) ([]*mesh.Mesh, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.New("3mf file is not a valid zip package")
+	}
+
+	modelPath, err := findModelPath(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	var m model
+	if err := decodeXML(archive, modelPath, &m); err != nil {
+		return nil, err
+	}
+
+	scale := 1.0
+	if m.Unit != "" {
+		s, ok := unitScale[m.Unit]
+		if !ok {
+			return nil, fmt.Errorf("3mf unit %q is not supported", m.Unit)
+		}
+		scale = s
+	}
+
+	objects := make(map[int]*object, len(m.Objects))
+	for i := range m.Objects {
+		objects[m.Objects[i].Id] = &m.Objects[i]
+	}
+
+	result := make([]*mesh.Mesh, 0, len(m.Items))
+	for _, item := range m.Items {
+		t, err := parseTransform(item.Transform)
+		if err != nil {
+			return nil, err
+		}
+		out := &mesh.Mesh{}
+		if err := appendObject(out, objects, item.ObjectId, t, scale, 0); err != nil {
+			return nil, err
+		}
+		if len(out.Triangles) > 0 {
+			result = append(result, out)
+		}
+	}
+	if len(result) == 0 {
+		return nil, errors.New("3mf file does not contain any printable object")
+	}
+
+	return result, nil
+}
+
+func findModelPath(archive *zip.Reader) (string, error) {
+	var rels relationships
+	if err := decodeXML(archive, "_rels/.rels", &rels); err == nil {
+		for _, rel := range rels.Relationships {
+			if rel.Type == modelRelationshipType {
+				return strings.TrimPrefix(rel.Target, "/"), nil
+			}
+		}
+	}
+	return "3D/3dmodel.model", nil
+}
+
+func decodeXML(archive *zip.Reader, name string, v interface{}) error {
+	for _, f := range archive.File {
+		if !strings.EqualFold(path.Clean(f.Name), name) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+
+		err = xml.NewDecoder(io.LimitReader(rc, 512<<20)).Decode(v)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %s", name, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("3mf package does not contain %s", name)
+}
+
+// appendObject copies the geometry of an object, and of every component it is made of,
+// into out with the accumulated transform applied.
+func appendObject(out *mesh.Mesh, objects map[int]*object, id int, t transform, scale float64, depth int) error {
+	if depth > maxComponentDepth {
+		return errors.New("3mf components are nested too deep")
+	}
+	obj, ok := objects[id]
+	if !ok {
+		return fmt.Errorf("3mf build references unknown object %d", id)
+	}
+	if out.Name == "" {
+		out.Name = obj.Name
+	}
+
+	offset := len(out.Vertices)
+	for _, v := range obj.Vertices {
+		x, y, z := t.apply(v.X, v.Y, v.Z)
+		out.Vertices = append(out.Vertices, mesh.Vertex{X: x * scale, Y: y * scale, Z: z * scale})
+	}
+	for _, tri := range obj.Triangles {
+		if !obj.hasVertex(tri.V1) || !obj.hasVertex(tri.V2) || !obj.hasVertex(tri.V3) {
+			return fmt.Errorf("3mf object %d has a triangle with an unknown vertex", id)
+		}
+		out.Triangles = append(out.Triangles, mesh.Triangle{V1: offset + tri.V1, V2: offset + tri.V2, V3: offset + tri.V3})
+	}
+
+	for _, c := range obj.Components {
+		ct, err := parseTransform(c.Transform)
+		if err != nil {
+			return err
+		}
+		if err := appendObject(out, objects, c.ObjectId, ct.then(t), scale, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasVertex tells whether i is the index of one of the vertices of o
+func (o *object) hasVertex(i int) bool {
+	return i >= 0 && i < len(o.Vertices)
+}
+
+func parseTransform(value string) (transform, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return identity, nil
+	}
+	if len(fields) != 12 {
+		return identity, fmt.Errorf("3mf transform %q must have 12 values", value)
+	}
+	var t transform
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return identity, fmt.Errorf("3mf transform %q is not numeric", value)
+		}
+		t[i] = v
+	}
+	return t, nil
+}
+
+// apply transforms a point. 3MF uses row vectors, so p' = p * M.
+func (t transform) apply(x, y, z float64) (float64, float64, float64) {
+	return x*t[0] + y*t[3] + z*t[6] + t[9],
+		x*t[1] + y*t[4] + z*t[7] + t[10],
+		x*t[2] + y*t[5] + z*t[8] + t[11]
+}
+
+// then returns the transform that applies t first and next afterwards
+func (t transform) then(next transform) transform {
+	var out transform
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 3; col++ {
+			v := 0.0
+			for k := 0; k < 3; k++ {
+				v += t[row*3+k] * next[k*3+col]
+			}
+			if row == 3 {
+				v += next[9+col]
+			}
+			out[row*3+col] = v
+		}
+	}
+	return out
+}
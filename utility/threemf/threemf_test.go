@@ -0,0 +1,132 @@
+package threemf_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+	"threedee/utility/mesh"
+	"threedee/utility/threemf"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const rels = `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Target="/3D/3dmodel.model" Id="rel0" Type="http://schemas.microsoft.com/3dmanufacturing/2013/01/3dmodel"/>
+</Relationships>`
+
+// cube is a closed 1x1x1 cube with outward facing triangles
+const cube = `
+      <mesh>
+        <vertices>
+          <vertex x="0" y="0" z="0"/><vertex x="1" y="0" z="0"/><vertex x="1" y="1" z="0"/><vertex x="0" y="1" z="0"/>
+          <vertex x="0" y="0" z="1"/><vertex x="1" y="0" z="1"/><vertex x="1" y="1" z="1"/><vertex x="0" y="1" z="1"/>
+        </vertices>
+        <triangles>
+          <triangle v1="0" v2="2" v3="1"/><triangle v1="0" v2="3" v3="2"/>
+          <triangle v1="4" v2="5" v3="6"/><triangle v1="4" v2="6" v3="7"/>
+          <triangle v1="0" v2="1" v3="5"/><triangle v1="0" v2="5" v3="4"/>
+          <triangle v1="1" v2="2" v3="6"/><triangle v1="1" v2="6" v3="5"/>
+          <triangle v1="2" v2="3" v3="7"/><triangle v1="2" v2="7" v3="6"/>
+          <triangle v1="3" v2="0" v3="4"/><triangle v1="3" v2="4" v3="7"/>
+        </triangles>
+      </mesh>`
+
+type ThreeMFTestSuite struct {
+	suite.Suite
+}
+
+func (suite *ThreeMFTestSuite) TestParse() {
+	var testCase = []struct {
+		testcase string
+		model    string
+		isError  bool
+		objects  int
+		volume   float64
+		size     [3]float64
+	}{
+		{
+			testcase: "single cube in centimeter",
+			model: `<model unit="centimeter" xmlns="http://schemas.microsoft.com/3dmanufacturing/core/2015/02">
+  <resources><object id="1" type="model">` + cube + `</object></resources>
+  <build><item objectid="1"/></build>
+</model>`,
+			objects: 1,
+			volume:  1000,
+			size:    [3]float64{10, 10, 10},
+		},
+		{
+			testcase: "two placed objects and a scaled component",
+			model: `<model unit="millimeter" xmlns="http://schemas.microsoft.com/3dmanufacturing/core/2015/02">
+  <resources>
+    <object id="1" type="model">` + cube + `</object>
+    <object id="2" type="model"><components><component objectid="1" transform="2 0 0 0 2 0 0 0 2 0 0 0"/></components></object>
+  </resources>
+  <build>
+    <item objectid="1" transform="1 0 0 0 1 0 0 0 1 10 0 0"/>
+    <item objectid="2"/>
+  </build>
+</model>`,
+			objects: 2,
+			volume:  9,
+			size:    [3]float64{11, 2, 2},
+		},
+		{
+			testcase: "unknown unit",
+			model:    `<model unit="furlong"><resources><object id="1">` + cube + `</object></resources><build><item objectid="1"/></build></model>`,
+			isError:  true,
+		},
+		{
+			testcase: "unknown object",
+			model:    `<model><resources><object id="1">` + cube + `</object></resources><build><item objectid="9"/></build></model>`,
+			isError:  true,
+		},
+		{
+			testcase: "negative vertex index",
+			model:    `<model><resources><object id="1"><mesh><vertices><vertex x="0" y="0" z="0"/><vertex x="1" y="0" z="0"/><vertex x="0" y="1" z="0"/></vertices><triangles><triangle v1="0" v2="-1" v3="2"/></triangles></mesh></object></resources><build><item objectid="1"/></build></model>`,
+			isError:  true,
+		},
+		{
+			testcase: "vertex index out of range",
+			model:    `<model><resources><object id="1"><mesh><vertices><vertex x="0" y="0" z="0"/><vertex x="1" y="0" z="0"/><vertex x="0" y="1" z="0"/></vertices><triangles><triangle v1="0" v2="1" v3="3"/></triangles></mesh></object></resources><build><item objectid="1"/></build></model>`,
+			isError:  true,
+		},
+	}
+	for _, tc := range testCase {
+		meshes, err := threemf.Parse(pack(tc.model))
+
+		if tc.isError {
+			suite.NotNil(err, tc.testcase)
+			continue
+		}
+		suite.Nil(err, tc.testcase)
+
+		analysis := mesh.Analyze(meshes)
+		width, depth, height := analysis.BoundingBox.Size()
+		suite.Equal(tc.objects, analysis.Objects, tc.testcase)
+		suite.InDelta(tc.volume, analysis.Volume, 0.0001, tc.testcase)
+		suite.InDelta(tc.size[0], width, 0.0001, tc.testcase)
+		suite.InDelta(tc.size[1], depth, 0.0001, tc.testcase)
+		suite.InDelta(tc.size[2], height, 0.0001, tc.testcase)
+	}
+}
+
+func (suite *ThreeMFTestSuite) TestParseNotZip() {
+	_, err := threemf.Parse([]byte("solid cube"))
+	suite.NotNil(err)
+}
+
+func pack(model string) []byte {
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	f, _ := w.Create("_rels/.rels")
+	f.Write([]byte(rels))
+	f, _ = w.Create("3D/3dmodel.model")
+	f.Write([]byte(model))
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestThreeMFTestSuite(t *testing.T) {
+	suite.Run(t, new(ThreeMFTestSuite))
+}
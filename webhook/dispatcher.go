@@ -0,0 +1,151 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"threedee/entity"
+	webhook_subscription "threedee/interfaces/webhook-subscription"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+ * Webhook Dispatcher
+ *
+ * Dispatch looks up the subscriptions of an event and delivers the event to each of them.
+ * It is called by the outbox relay (outbox/webhook_sink.go), never from a request, so a
+ * slow subscriber never slows down the API response.
+ *
+ * A delivery is a JSON POST signed with the subscription secret:
+ *
+ *   X-Threedee-Event: status_changed
+ *   X-Threedee-Signature: sha256=<hex HMAC-SHA256 of the raw body>
+ *
+ * Any non-2xx answer or network error fails the delivery. Dispatch makes one attempt and
+ * does not wait, the relay retries the event later with backoff, see outbox.Relay, and
+ * passes the attempt number on. Every attempt is written to the delivery log. A retry goes
+ * to all subscriptions of the event again, so the payload id is the outbox event id, which
+ * subscribers can use to drop the duplicates an at-least-once delivery may cause.
+ */
+
+const (
+	HeaderEvent     = "X-Threedee-Event"
+	HeaderSignature = "X-Threedee-Signature"
+)
+
+type Payload struct {
+	Id         int         `json:"id"`
+	Event      string      `json:"event"`
+	OccurredOn time.Time   `json:"occurred_on"`
+	Data       interface{} `json:"data"`
+}
+
+type Dispatcher struct {
+	Repo   webhook_subscription.WebhookRepositoryInterface
+	Client *http.Client
+}
+
+func NewDispatcher(repo webhook_subscription.WebhookRepositoryInterface) *Dispatcher {
+	return &Dispatcher{
+		Repo:   repo,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Dispatch delivers the event to every subscription of it and returns an error when at
+// least one subscription could not be reached. attempt counts the deliveries of the event
+// from 1, it is only written to the delivery log.
+func (d *Dispatcher) Dispatch(id int, event string, occurredOn time.Time, data interface{}, attempt int) error {
+	subscriptions, err := d.Repo.GetByEvent(event)
+	if err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(Payload{Id: id, Event: event, OccurredOn: occurredOn.UTC(), Data: data})
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(subscriptions))
+	for i, sub := range subscriptions {
+		wg.Add(1)
+		go func(i int, sub *entity.WebhookSubscription) {
+			defer wg.Done()
+			errs[i] = d.Deliver(sub, event, body, attempt)
+		}(i, sub)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("webhook delivery failed for %d of %d subscriptions", failed, len(subscriptions))
+	}
+	return nil
+}
+
+// Deliver sends body to one subscription once, logs the attempt and returns its error
+func (d *Dispatcher) Deliver(sub *entity.WebhookSubscription, event string, body []byte, attempt int) error {
+	status, err := d.send(sub, event, body)
+
+	delivery := entity.NewWebhookDelivery()
+	delivery.SubscriptionId = sub.Id
+	delivery.Event = event
+	delivery.Payload = string(body)
+	delivery.Attempt = attempt
+	delivery.StatusCode = status
+	delivery.Success = err == nil
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	if _, logErr := d.Repo.InsertDelivery(delivery); logErr != nil {
+		log.WithField("subscription", sub.Id).Warning("webhook: failed to log delivery: " + logErr.Error())
+	}
+	return err
+}
+
+func (d *Dispatcher) send(sub *entity.WebhookSubscription, event string, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, sub.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(sub.Secret, body))
+
+	res, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("subscriber responded with %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of body, which subscribers recompute to verify a delivery
+func Sign(secret string, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,106 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"threedee/entity"
+	"threedee/testdata/mock"
+	"threedee/webhook"
+	"time"
+
+	testifymock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type DispatcherTestSuite struct {
+	suite.Suite
+	mockWebhookRepo *mock.MockWebhookRepository
+	dispatcher      *webhook.Dispatcher
+}
+
+func (suite *DispatcherTestSuite) SetupTest() {
+	suite.mockWebhookRepo = &mock.MockWebhookRepository{}
+	suite.dispatcher = webhook.NewDispatcher(suite.mockWebhookRepo)
+}
+
+func (suite *DispatcherTestSuite) TestDeliverSigns() {
+	var signature, event string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(webhook.HeaderSignature)
+		event = r.Header.Get(webhook.HeaderEvent)
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	sub := &entity.WebhookSubscription{Id: 3, Url: server.URL, Secret: "s3cret", Events: []string{entity.EventCreated}}
+	suite.mockWebhookRepo.On("InsertDelivery", testifymock.MatchedBy(func(d *entity.WebhookDelivery) bool {
+		return d.Attempt == 2 && d.Success && d.SubscriptionId == 3 && d.StatusCode == http.StatusOK
+	})).Return(1, nil).Once()
+
+	err := suite.dispatcher.Deliver(sub, entity.EventCreated, []byte(`{"event":"created"}`), 2)
+
+	suite.Nil(err)
+	suite.Equal(entity.EventCreated, event)
+	suite.Equal("sha256="+webhook.Sign("s3cret", body), signature)
+	suite.mockWebhookRepo.AssertExpectations(suite.T())
+}
+
+func (suite *DispatcherTestSuite) TestDeliverFails() {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	sub := &entity.WebhookSubscription{Id: 3, Url: server.URL, Secret: "s3cret"}
+	suite.mockWebhookRepo.On("InsertDelivery", testifymock.MatchedBy(func(d *entity.WebhookDelivery) bool {
+		return d.Attempt == 1 && !d.Success && d.StatusCode == http.StatusBadGateway
+	})).Return(1, nil).Once()
+
+	err := suite.dispatcher.Deliver(sub, entity.EventDeleted, []byte(`{}`), 1)
+
+	suite.NotNil(err)
+	suite.Equal(1, calls, "retries are left to the outbox relay")
+	suite.mockWebhookRepo.AssertExpectations(suite.T())
+}
+
+func (suite *DispatcherTestSuite) TestDispatch() {
+	received := make(chan webhook.Payload, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload webhook.Payload
+		json.NewDecoder(r.Body).Decode(&payload)
+		received <- payload
+	}))
+	defer server.Close()
+
+	subs := []*entity.WebhookSubscription{{Id: 1, Url: server.URL, Secret: "a"}, {Id: 2, Url: server.URL, Secret: "b"}}
+	suite.mockWebhookRepo.On("GetByEvent", entity.EventStatusChanged).Return(subs, nil).Once()
+	suite.mockWebhookRepo.On("InsertDelivery", testifymock.Anything).Return(1, nil)
+
+	err := suite.dispatcher.Dispatch(42, entity.EventStatusChanged, time.Now(), entity.DeletedEvent{Id: 9, Requestor: "andi", Status: "received"}, 1)
+
+	suite.Nil(err)
+	suite.Len(received, 2)
+	payload := <-received
+	suite.Equal(42, payload.Id)
+	suite.Equal(entity.EventStatusChanged, payload.Event)
+	suite.Equal(map[string]interface{}{"id": float64(9), "requestor": "andi", "status": "received"}, payload.Data)
+}
+
+func (suite *DispatcherTestSuite) TestDispatchWithoutSubscriptions() {
+	suite.mockWebhookRepo.On("GetByEvent", entity.EventCreated).Return([]*entity.WebhookSubscription{}, nil).Once()
+
+	err := suite.dispatcher.Dispatch(1, entity.EventCreated, time.Now(), nil, 1)
+
+	suite.Nil(err)
+	suite.mockWebhookRepo.AssertNotCalled(suite.T(), "InsertDelivery", testifymock.Anything)
+}
+
+func TestDispatcherTestSuite(t *testing.T) {
+	suite.Run(t, new(DispatcherTestSuite))
+}